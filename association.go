@@ -57,15 +57,29 @@ func (association *Association) Find(out interface{}, conds ...interface{}) erro
 	return association.Error
 }
 
+// Append appends values to the association, a clause.OnConflict in values is used for inserting the join table
+// rows of many2many associations instead of the default `ON CONFLICT DO NOTHING`, e.g:
+//
+//	db.Model(&user).Association("Roles").Append(roles, clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "role_id"}}, DoNothing: true})
 func (association *Association) Append(values ...interface{}) error {
 	if association.Error == nil {
+		var onConflict *clause.OnConflict
+		appendValues := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			if c, ok := value.(clause.OnConflict); ok {
+				onConflict = &c
+			} else {
+				appendValues = append(appendValues, value)
+			}
+		}
+
 		switch association.Relationship.Type {
 		case schema.HasOne, schema.BelongsTo:
-			if len(values) > 0 {
-				association.Error = association.Replace(values...)
+			if len(appendValues) > 0 {
+				association.Error = association.Replace(appendValues...)
 			}
 		default:
-			association.saveAssociation( /*clear*/ false, values...)
+			association.saveAssociation( /*clear*/ false, onConflict, appendValues...)
 		}
 	}
 
@@ -93,7 +107,7 @@ func (association *Association) Replace(values ...interface{}) error {
 		}
 
 		// save associations
-		if association.saveAssociation( /*clear*/ true, values...); association.Error != nil {
+		if association.saveAssociation( /*clear*/ true, nil, values...); association.Error != nil {
 			return association.Error
 		}
 
@@ -377,7 +391,7 @@ type assignBack struct {
 	Dest   reflect.Value
 }
 
-func (association *Association) saveAssociation(clear bool, values ...interface{}) {
+func (association *Association) saveAssociation(clear bool, onConflict *clause.OnConflict, values ...interface{}) {
 	var (
 		reflectValue = association.DB.Statement.ReflectValue
 		assignBacks  []assignBack // assign association values back to arguments after save
@@ -485,6 +499,9 @@ func (association *Association) saveAssociation(clear bool, values ...interface{
 	if len(omitColumns) > 0 {
 		associationDB.Omit(omitColumns...)
 	}
+	if onConflict != nil && association.Relationship.JoinTable != nil {
+		associationDB.Set("gorm:join_table_on_conflict:"+association.Relationship.JoinTable.Table, *onConflict)
+	}
 	associationDB = associationDB.Session(&Session{})
 
 	switch reflectValue.Kind() {
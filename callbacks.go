@@ -8,6 +8,7 @@ import (
 	"sort"
 	"time"
 
+	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils"
 )
@@ -43,9 +44,16 @@ type processor struct {
 	Clauses []string
 	// 对应于 crud 类型的执行函数链
 	fns       []func(*DB)
+	fnNames   []string
 	callbacks []*callback
 }
 
+// finalizingCallbacks callbacks still executed after DB.Abort
+var finalizingCallbacks = map[string]bool{
+	"gorm:commit_or_rollback_transaction": true,
+	"gorm:reset_local_settings":           true,
+}
+
 type callback struct {
 	name      string
 	before    string
@@ -82,6 +90,17 @@ func (cs *callbacks) Raw() *processor {
 	return cs.processors["raw"]
 }
 
+// Debug returns the names of the registered callbacks of every operation (create/query/update/delete/row/raw)
+// in the execution order after resolving Before/After, for diagnosing the callback ordering
+// Debug 返回各类操作按执行顺序排列的回调名称，只读，用于排查插件回调的执行顺序问题。
+func (cs *callbacks) Debug() map[string][]string {
+	pipelines := make(map[string][]string, len(cs.processors))
+	for name, p := range cs.processors {
+		pipelines[name] = append([]string{}, p.fnNames...)
+	}
+	return pipelines
+}
+
 // Execute
 // 通用的 processor 执行函数，其中对应于 crud 的核心操作都被封装在 processor 对应的 fns list 当中了
 // 调用 statement.Build(...) 方法，生成 sql
@@ -105,6 +124,11 @@ func (p *processor) Execute(db *DB) *DB {
 		resetBuildClauses = true
 	}
 
+	// common table expressions are built before the statement
+	if _, ok := stmt.Clauses["WITH"]; ok && len(stmt.BuildClauses) > 0 && stmt.BuildClauses[0] != "WITH" {
+		stmt.BuildClauses = append([]string{"WITH"}, stmt.BuildClauses...)
+	}
+
 	if optimizer, ok := db.Statement.Dest.(StatementModifier); ok {
 		optimizer.ModifyStatement(stmt)
 	}
@@ -145,14 +169,34 @@ func (p *processor) Execute(db *DB) *DB {
 		}
 	}
 
+	// the writer is selected for statements with DB.WriteDB or Session.UseWriter, a transaction takes precedence
+	if _, ok := stmt.Settings.Load("gorm:use_writer"); ok {
+		if _, inTx := stmt.ConnPool.(TxCommitter); !inTx {
+			if selector, ok := stmt.ConnPool.(WriterSelector); ok {
+				stmt.ConnPool = selector.Writer()
+			}
+		}
+	}
+
+	defer db.withPreparedKey()()
+
+	// the statement is executed with Config.DefaultQueryTimeout, Row/Rows are excluded as the rows are read afterward
+	if p != db.callbacks.Row() {
+		defer db.withQueryTimeout()()
+	}
+
 	// 执行一系列的 callback 函数，其中最核心的 create/query/update/delete 操作都被包含在其中了
 	// 核心
-	for _, f := range p.fns {
+	for idx, f := range p.fns {
+		if stmt.aborted && !finalizingCallbacks[p.fnNames[idx]] {
+			continue
+		}
 		f(db)
 	}
+	stmt.aborted = false
 
 	if stmt.SQL.Len() > 0 {
-		db.Logger.Trace(stmt.Context, curTime, func() (string, int64) {
+		trace(stmt.Context, db.Logger, curTime, func() (string, int64) {
 			sql, vars := stmt.SQL.String(), stmt.Vars
 			if filter, ok := db.Logger.(ParamsFilter); ok {
 				sql, vars = filter.ParamsFilter(stmt.Context, stmt.SQL.String(), stmt.Vars...)
@@ -223,7 +267,7 @@ func (p *processor) compile() (err error) {
 	}
 	p.callbacks = callbacks
 
-	if p.fns, err = sortCallbacks(p.callbacks); err != nil {
+	if p.fns, p.fnNames, err = sortCallbacks(p.callbacks); err != nil {
 		p.db.Logger.Error(context.Background(), "Got error when compile callbacks, got %v", err)
 	}
 	return
@@ -273,7 +317,7 @@ func getRIndex(strs []string, str string) int {
 	return -1
 }
 
-func sortCallbacks(cs []*callback) (fns []func(*DB), err error) {
+func sortCallbacks(cs []*callback) (fns []func(*DB), fnNames []string, err error) {
 	var (
 		names, sorted []string
 		sortCallback  func(*callback) error
@@ -363,6 +407,7 @@ func sortCallbacks(cs []*callback) (fns []func(*DB), err error) {
 	for _, name := range sorted {
 		if idx := getRIndex(names, name); !cs[idx].remove {
 			fns = append(fns, cs[idx].handler)
+			fnNames = append(fnNames, name)
 		}
 	}
 
@@ -379,3 +424,22 @@ func removeCallbacks(cs []*callback, nameMap map[string]bool) []*callback {
 	}
 	return callbacks
 }
+
+// trace logs the statement with logger.StructuredTracer if the logger implements it, otherwise with Trace
+func trace(ctx context.Context, l logger.Interface, begin time.Time, fc func() (string, int64), err error) {
+	tracer, ok := l.(logger.StructuredTracer)
+	if !ok {
+		l.Trace(ctx, begin, fc, err)
+		return
+	}
+
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	tracer.TraceStructured(ctx, map[string]interface{}{
+		"sql":         sql,
+		"rows":        rows,
+		"duration_ms": float64(elapsed.Nanoseconds()) / 1e6,
+		"error":       err,
+		"caller":      utils.FileWithLineNum(),
+	})
+}
@@ -347,7 +347,13 @@ func SaveAfterAssociations(create bool) func(db *gorm.DB) {
 				}
 
 				if joins.Len() > 0 {
-					db.AddError(db.Session(&gorm.Session{NewDB: true}).Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{
+					// the join rows may be linked already, the conflict handling can be specified with Association.Append
+					onConflict := clause.OnConflict{DoNothing: true}
+					if c, ok := db.Get("gorm:join_table_on_conflict:" + rel.JoinTable.Table); ok {
+						onConflict, _ = c.(clause.OnConflict)
+					}
+
+					db.AddError(db.Session(&gorm.Session{NewDB: true}).Clauses(onConflict).Session(&gorm.Session{
 						SkipHooks:                db.Statement.SkipHooks,
 						DisableNestedTransaction: true,
 					}).Create(joins.Interface()).Error)
@@ -25,6 +25,10 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 	enableTransaction := func(db *gorm.DB) bool {
 		return !db.SkipDefaultTransaction
 	}
+	// WithLocalSettings is only supported by postgres, other dialects don't register its callbacks
+	enableLocalSettings := func(db *gorm.DB) bool {
+		return db.Dialector != nil && db.Dialector.Name() == "postgres"
+	}
 
 	if len(config.CreateClauses) == 0 {
 		config.CreateClauses = createClauses
@@ -42,41 +46,52 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 	//  创建类 create processor
 	createCallback := db.Callback().Create()
 	createCallback.Match(enableTransaction).Register("gorm:begin_transaction", BeginTransaction)
+	createCallback.Match(enableLocalSettings).Register("gorm:apply_local_settings", ApplyLocalSettings)
 	createCallback.Register("gorm:before_create", BeforeCreate)
 	createCallback.Register("gorm:save_before_associations", SaveBeforeAssociations(true))
 	createCallback.Register("gorm:create", Create(config))
+	createCallback.Register("gorm:on_write", OnWrite("create"))
 	createCallback.Register("gorm:save_after_associations", SaveAfterAssociations(true))
 	createCallback.Register("gorm:after_create", AfterCreate)
 	createCallback.Match(enableTransaction).Register("gorm:commit_or_rollback_transaction", CommitOrRollbackTransaction)
+	createCallback.Match(enableLocalSettings).Register("gorm:reset_local_settings", ResetLocalSettings)
 	createCallback.Clauses = config.CreateClauses
 
 	// 查询类 query processor
 	queryCallback := db.Callback().Query()
+	queryCallback.Match(enableLocalSettings).Register("gorm:apply_local_settings", ApplyLocalSettings)
 	queryCallback.Register("gorm:query", Query)
 	queryCallback.Register("gorm:preload", Preload)
 	queryCallback.Register("gorm:after_query", AfterQuery)
+	queryCallback.Match(enableLocalSettings).Register("gorm:reset_local_settings", ResetLocalSettings)
 	queryCallback.Clauses = config.QueryClauses
 
 	// 删除类 delete processor
 	deleteCallback := db.Callback().Delete()
 	deleteCallback.Match(enableTransaction).Register("gorm:begin_transaction", BeginTransaction)
+	deleteCallback.Match(enableLocalSettings).Register("gorm:apply_local_settings", ApplyLocalSettings)
 	deleteCallback.Register("gorm:before_delete", BeforeDelete)
 	deleteCallback.Register("gorm:delete_before_associations", DeleteBeforeAssociations)
 	deleteCallback.Register("gorm:delete", Delete(config))
+	deleteCallback.Register("gorm:on_write", OnWrite("delete"))
 	deleteCallback.Register("gorm:after_delete", AfterDelete)
 	deleteCallback.Match(enableTransaction).Register("gorm:commit_or_rollback_transaction", CommitOrRollbackTransaction)
+	deleteCallback.Match(enableLocalSettings).Register("gorm:reset_local_settings", ResetLocalSettings)
 	deleteCallback.Clauses = config.DeleteClauses
 
 	// 更新类 update processor
 	updateCallback := db.Callback().Update()
 	updateCallback.Match(enableTransaction).Register("gorm:begin_transaction", BeginTransaction)
+	updateCallback.Match(enableLocalSettings).Register("gorm:apply_local_settings", ApplyLocalSettings)
 	updateCallback.Register("gorm:setup_reflect_value", SetupUpdateReflectValue)
 	updateCallback.Register("gorm:before_update", BeforeUpdate)
 	updateCallback.Register("gorm:save_before_associations", SaveBeforeAssociations(false))
 	updateCallback.Register("gorm:update", Update(config))
+	updateCallback.Register("gorm:on_write", OnWrite("update"))
 	updateCallback.Register("gorm:save_after_associations", SaveAfterAssociations(false))
 	updateCallback.Register("gorm:after_update", AfterUpdate)
 	updateCallback.Match(enableTransaction).Register("gorm:commit_or_rollback_transaction", CommitOrRollbackTransaction)
+	updateCallback.Match(enableLocalSettings).Register("gorm:reset_local_settings", ResetLocalSettings)
 	updateCallback.Clauses = config.UpdateClauses
 
 	// row 类
@@ -86,6 +101,8 @@ func RegisterDefaultCallbacks(db *gorm.DB, config *Config) {
 
 	// raw 类
 	rawCallback := db.Callback().Raw()
+	rawCallback.Match(enableLocalSettings).Register("gorm:apply_local_settings", ApplyLocalSettings)
 	rawCallback.Register("gorm:raw", RawExec)
+	rawCallback.Match(enableLocalSettings).Register("gorm:reset_local_settings", ResetLocalSettings)
 	rawCallback.Clauses = config.QueryClauses
 }
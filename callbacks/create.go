@@ -1,6 +1,7 @@
 package callbacks
 
 import (
+	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
@@ -42,6 +43,14 @@ func Create(config *Config) func(db *gorm.DB) {
 			return
 		}
 
+		// the partition is checked when creating as it could be dropped at any time
+		if partition, ok := db.Get("gorm:partition"); ok && !db.DryRun {
+			if !db.Session(&gorm.Session{NewDB: true}).Migrator().HasTable(partition.(string)) {
+				db.AddError(fmt.Errorf("%w: partition %s doesn't exist", gorm.ErrInvalidData, partition))
+				return
+			}
+		}
+
 		if db.Statement.Schema != nil {
 			if !db.Statement.Unscoped {
 				for _, c := range db.Statement.Schema.CreateClauses {
@@ -63,9 +72,17 @@ func Create(config *Config) func(db *gorm.DB) {
 		if db.Statement.SQL.Len() == 0 {
 			db.Statement.SQL.Grow(180)
 			db.Statement.AddClauseIfNotExists(clause.Insert{})
-			db.Statement.AddClause(ConvertToCreateValues(db.Statement))
+			values := ConvertToCreateValues(db.Statement)
+			db.Statement.AddClause(values)
+			if supportReturning {
+				returningOnConflictKeys(db)
+			}
 
-			db.Statement.Build(db.Statement.BuildClauses...)
+			if onConflict, ok := db.Statement.Clauses["ON CONFLICT"].Expression.(clause.OnConflict); ok && onConflict.ReturningOld != nil {
+				buildUpsertReturningOld(db, onConflict, values)
+			} else {
+				db.Statement.Build(db.Statement.BuildClauses...)
+			}
 		}
 
 		isDryRun := !db.DryRun && db.Error == nil
@@ -75,6 +92,8 @@ func Create(config *Config) func(db *gorm.DB) {
 
 		ok, mode := hasReturning(db, supportReturning)
 		if ok {
+			// the returned rows are always mapped back to the created values, even with `RETURNING *`
+			mode |= gorm.ScanUpdate
 			if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
 				if onConflict, _ := c.Expression.(clause.OnConflict); onConflict.DoNothing {
 					mode |= gorm.ScanOnConflictDoNothing
@@ -86,9 +105,14 @@ func Create(config *Config) func(db *gorm.DB) {
 			)
 			if db.AddError(err) == nil {
 				defer func() {
-					db.AddError(rows.Close())
+					closeRows(db, rows)
 				}()
-				gorm.Scan(rows, db, mode)
+
+				if onConflict, ok := db.Statement.Clauses["ON CONFLICT"].Expression.(clause.OnConflict); ok && onConflict.ReturningOld != nil {
+					gorm.Scan(newUpsertOldRows(db, rows, onConflict.ReturningOld), db, mode)
+				} else {
+					gorm.Scan(rows, db, mode)
+				}
 
 				if db.Statement.Result != nil {
 					db.Statement.Result.RowsAffected = db.RowsAffected
@@ -232,6 +256,224 @@ func AfterCreate(db *gorm.DB) {
 	}
 }
 
+const (
+	upsertOldTable  = "gorm_old"
+	upsertNewTable  = "gorm_new"
+	upsertOldPrefix = "gorm_old__"
+	upsertNewPrefix = "gorm_new__"
+)
+
+// buildUpsertReturningOld builds the upsert as a data-modifying CTE joined with the conflicting rows before upserting,
+// all CTEs see the same snapshot, so the rows of gorm_old are the ones before upserting, e.g:
+//
+//	WITH "gorm_old" AS (SELECT * FROM "users" WHERE ("id") IN (($1),($2))),
+//	"gorm_new" AS (INSERT INTO "users" ... ON CONFLICT ("id") DO UPDATE SET ... RETURNING *)
+//	SELECT "gorm_new".*,"gorm_old"."id" AS "gorm_old__id",... FROM "gorm_new" LEFT JOIN "gorm_old" ON "gorm_new"."id" = "gorm_old"."id"
+func buildUpsertReturningOld(db *gorm.DB, onConflict clause.OnConflict, values clause.Values) {
+	stmt := db.Statement
+	if db.Dialector.Name() != "postgres" {
+		db.AddError(fmt.Errorf("%w: returning old values of upsert is only supported by postgres", gorm.ErrUnsupportedDriver))
+		return
+	}
+	if stmt.Schema == nil {
+		db.AddError(fmt.Errorf("%w when returning old values of upsert", gorm.ErrModelValueRequired))
+		return
+	}
+
+	keys := onConflict.Columns
+	if len(keys) == 0 {
+		if onConflict.OnConstraint != "" {
+			db.AddError(fmt.Errorf("%w: conflict columns are required to return old values of upsert", gorm.ErrInvalidData))
+			return
+		}
+		for _, dbName := range stmt.Schema.PrimaryFieldDBNames {
+			keys = append(keys, clause.Column{Name: dbName})
+		}
+	}
+
+	keyIndexes := make([]int, len(keys))
+	for idx, key := range keys {
+		keyIndexes[idx] = -1
+		for i, column := range values.Columns {
+			if column.Name == key.Name {
+				keyIndexes[idx] = i
+			}
+		}
+		if keyIndexes[idx] == -1 {
+			db.AddError(fmt.Errorf("%w: conflict column %s isn't inserted", gorm.ErrInvalidData, key.Name))
+			return
+		}
+	}
+
+	keyValues := make([][]interface{}, len(values.Values))
+	for idx, value := range values.Values {
+		for _, i := range keyIndexes {
+			keyValues[idx] = append(keyValues[idx], value[i])
+		}
+	}
+
+	stmt.AddClause(clause.Returning{})
+
+	stmt.WriteString("WITH ")
+	stmt.WriteQuoted(upsertOldTable)
+	stmt.WriteString(" AS (SELECT * FROM ")
+	stmt.WriteQuoted(clause.Table{Name: clause.CurrentTable})
+	stmt.WriteString(" WHERE (")
+	for idx, key := range keys {
+		if idx > 0 {
+			stmt.WriteByte(',')
+		}
+		stmt.WriteQuoted(key.Name)
+	}
+	stmt.WriteString(") IN ")
+	stmt.AddVar(stmt, keyValues)
+	stmt.WriteString("),")
+	stmt.WriteQuoted(upsertNewTable)
+	stmt.WriteString(" AS (")
+	stmt.Build(stmt.BuildClauses...)
+	stmt.WriteString(") SELECT ")
+	stmt.WriteQuoted(upsertNewTable)
+	stmt.WriteString(".*")
+	for _, dbName := range stmt.Schema.DBNames {
+		stmt.WriteByte(',')
+		stmt.WriteQuoted(clause.Column{Table: upsertOldTable, Name: dbName, Alias: upsertOldPrefix + dbName})
+	}
+	stmt.WriteString(" FROM ")
+	stmt.WriteQuoted(upsertNewTable)
+	stmt.WriteString(" LEFT JOIN ")
+	stmt.WriteQuoted(upsertOldTable)
+	stmt.WriteString(" ON ")
+	for idx, key := range keys {
+		if idx > 0 {
+			stmt.WriteString(" AND ")
+		}
+		stmt.WriteQuoted(clause.Column{Table: upsertNewTable, Name: key.Name})
+		stmt.WriteString(" = ")
+		stmt.WriteQuoted(clause.Column{Table: upsertOldTable, Name: key.Name})
+	}
+}
+
+// upsertOldRows scans the old values of each row into the ReturningOld dest of the ON CONFLICT clause
+// besides scanning the new values
+type upsertOldRows struct {
+	gorm.Rows
+	db      *gorm.DB
+	old     reflect.Value
+	mapping map[string]string
+}
+
+func newUpsertOldRows(db *gorm.DB, rows gorm.Rows, dest interface{}) gorm.Rows {
+	old := reflect.ValueOf(dest)
+	if old.Kind() != reflect.Ptr || old.IsNil() {
+		db.AddError(fmt.Errorf("%w: ReturningOld should be a pointer", gorm.ErrInvalidValue))
+		return rows
+	}
+
+	old = old.Elem()
+	if old.Kind() == reflect.Slice {
+		old.SetLen(0)
+	}
+
+	// old columns are scanned as the fields, the new columns are ignored
+	mapping := make(map[string]string, len(db.Statement.Schema.DBNames)*2)
+	for _, dbName := range db.Statement.Schema.DBNames {
+		mapping[dbName] = upsertNewPrefix + dbName
+		mapping[upsertOldPrefix+dbName] = dbName
+	}
+	return &upsertOldRows{Rows: rows, db: db, old: old, mapping: mapping}
+}
+
+func (r *upsertOldRows) Scan(dest ...interface{}) error {
+	if err := r.Rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	sqlRows, ok := r.Rows.(*sql.Rows)
+	if !ok {
+		return nil
+	}
+
+	elem := r.old
+	if r.old.Kind() == reflect.Slice {
+		elem = reflect.New(r.old.Type().Elem()).Elem()
+	}
+
+	target := elem
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	// the current row could be scanned again
+	if err := r.db.Session(&gorm.Session{NewDB: true}).MapColumns(r.mapping).ScanRows(sqlRows, target.Addr().Interface()); err != nil {
+		return err
+	}
+
+	if r.old.Kind() == reflect.Slice {
+		r.old.Set(reflect.Append(r.old, elem))
+	}
+	return nil
+}
+
+// returningOnConflictKeys rows skipped by `ON CONFLICT DO NOTHING` are not returned, so the returned rows can't be mapped
+// to the created values by position, returns the conflict keys as well to map them by keys when scanning
+func returningOnConflictKeys(db *gorm.DB) {
+	c, ok := db.Statement.Clauses["ON CONFLICT"]
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	onConflict, _ := c.Expression.(clause.OnConflict)
+	if !onConflict.DoNothing {
+		return
+	}
+
+	var keyFields []*schema.Field
+	if len(onConflict.Columns) > 0 {
+		for _, column := range onConflict.Columns {
+			field := db.Statement.Schema.LookUpField(column.Name)
+			if field == nil || field.DBName == "" {
+				return
+			}
+			keyFields = append(keyFields, field)
+		}
+	} else {
+		// any unique constraint could conflict, the keys must be provided by the created values rather than generated
+		for _, field := range db.Statement.Schema.Fields {
+			if field.DBName != "" && (field.Unique || field.PrimaryKey) && (!field.HasDefaultValue || field.DefaultValueInterface != nil) {
+				keyFields = append(keyFields, field)
+			}
+		}
+	}
+
+	if len(keyFields) == 0 {
+		return
+	}
+
+	if rc, ok := db.Statement.Clauses["RETURNING"]; ok {
+		if returning, ok := rc.Expression.(clause.Returning); ok && len(returning.Columns) > 0 {
+			// don't append to the columns of the clause given by users
+			returning.Columns = returning.Columns[:len(returning.Columns):len(returning.Columns)]
+			for _, field := range keyFields {
+				returned := false
+				for _, column := range returning.Columns {
+					if column.Name == "*" || column.Name == field.DBName {
+						returned = true
+						break
+					}
+				}
+				if !returned {
+					returning.Columns = append(returning.Columns, clause.Column{Name: field.DBName})
+				}
+			}
+			rc.Expression = returning
+			db.Statement.Clauses["RETURNING"] = rc
+		}
+	}
+
+	db.InstanceSet("gorm:on_conflict_key_fields", keyFields)
+}
+
 // ConvertToCreateValues convert to create values
 // 构建sql
 func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
@@ -250,6 +492,7 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 		var (
 			selectColumns, restricted = stmt.SelectAndOmitColumns(true, false)
 			_, updateTrackTime        = stmt.Get("gorm:update_track_time")
+			actor, hasActor           = actorOf(stmt)
 			isZero                    bool
 		)
 		stmt.Settings.Delete("gorm:update_track_time")
@@ -258,7 +501,7 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 
 		for _, db := range stmt.Schema.DBNames {
 			if field := stmt.Schema.FieldsByDBName[db]; !field.HasDefaultValue || field.DefaultValueInterface != nil {
-				if v, ok := selectColumns[db]; (ok && v) || (!ok && (!restricted || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0)) {
+				if v, ok := selectColumns[db]; (ok && v) || (!ok && (!restricted || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 || (hasActor && field.Actor != ""))) {
 					values.Columns = append(values.Columns, clause.Column{Name: db})
 				}
 			}
@@ -294,10 +537,16 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 						} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
 							stmt.AddError(field.Set(stmt.Context, rv, curTime))
 							values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
+						} else if hasActor && field.Actor != "" {
+							stmt.AddError(field.Set(stmt.Context, rv, actor))
+							values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
 						}
 					} else if field.AutoUpdateTime > 0 && updateTrackTime {
 						stmt.AddError(field.Set(stmt.Context, rv, curTime))
 						values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
+					} else if hasActor && field.Actor == schema.ActorUpdated && updateTrackTime {
+						stmt.AddError(field.Set(stmt.Context, rv, actor))
+						values.Values[i][idx], _ = field.ValueOf(stmt.Context, rv)
 					}
 				}
 
@@ -325,6 +574,20 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 					}
 				}
 			}
+
+			// rows rely entirely on database defaults, `DEFAULT VALUES` inserts only one row,
+			// so insert the default value of a column for each row, e.g: INSERT INTO `t` (`id`) VALUES (DEFAULT),(DEFAULT)
+			if len(values.Columns) == 0 && rValLen > 1 && len(stmt.Schema.FieldsWithDefaultDBValue) > 0 {
+				field := stmt.Schema.FieldsWithDefaultDBValue[0]
+				if pf := stmt.Schema.PrioritizedPrimaryField; pf != nil && pf.HasDefaultValue && pf.DefaultValueInterface == nil {
+					field = pf
+				}
+
+				values.Columns = append(values.Columns, clause.Column{Name: field.DBName})
+				for idx := range values.Values {
+					values.Values[idx] = []interface{}{stmt.DefaultValueOf(field)}
+				}
+			}
 		case reflect.Struct:
 			values.Values = [][]interface{}{make([]interface{}, len(values.Columns))}
 			for idx, column := range values.Columns {
@@ -336,10 +599,16 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 					} else if field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
 						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, curTime))
 						values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
+					} else if hasActor && field.Actor != "" {
+						stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, actor))
+						values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
 					}
 				} else if field.AutoUpdateTime > 0 && updateTrackTime {
 					stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, curTime))
 					values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
+				} else if hasActor && field.Actor == schema.ActorUpdated && updateTrackTime {
+					stmt.AddError(field.Set(stmt.Context, stmt.ReflectValue, actor))
+					values.Values[0][idx], _ = field.ValueOf(stmt.Context, stmt.ReflectValue)
 				}
 			}
 
@@ -356,6 +625,18 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 		}
 	}
 
+	if len(stmt.DB.BindInterceptors) > 0 && stmt.Schema != nil {
+		for idx, column := range values.Columns {
+			if field := stmt.Schema.LookUpField(column.Name); field != nil {
+				for _, row := range values.Values {
+					if idx < len(row) {
+						row[idx] = interceptBindValue(stmt, field, row[idx])
+					}
+				}
+			}
+		}
+	}
+
 	if c, ok := stmt.Clauses["ON CONFLICT"]; ok {
 		if onConflict, _ := c.Expression.(clause.OnConflict); onConflict.UpdateAll {
 			if stmt.Schema != nil && len(values.Columns) >= 1 {
@@ -393,7 +674,7 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 				}
 
 				// use primary fields as default OnConflict columns
-				if len(onConflict.Columns) == 0 {
+				if len(onConflict.Columns) == 0 && len(onConflict.TargetExprs) == 0 {
 					for _, field := range stmt.Schema.PrimaryFields {
 						onConflict.Columns = append(onConflict.Columns, clause.Column{Name: field.DBName})
 					}
@@ -401,7 +682,34 @@ func ConvertToCreateValues(stmt *gorm.Statement) (values clause.Values) {
 				stmt.AddClause(onConflict)
 			}
 		}
+
+		// MySQL `ON DUPLICATE KEY UPDATE` doesn't take a WHERE clause, guard the assignments with it instead
+		if onConflict, _ := stmt.Clauses["ON CONFLICT"].Expression.(clause.OnConflict); len(onConflict.Where.Exprs) > 0 && !onConflict.DoNothing && stmt.DialectName() == "mysql" {
+			onConflict.DoUpdates = guardDoUpdates(onConflict.DoUpdates, onConflict.Where)
+			onConflict.Where = clause.Where{}
+			stmt.AddClause(onConflict)
+		}
 	}
 
 	return values
 }
+
+// guardDoUpdates keeps the current value of each column unless the predicate is true, e.g:
+//
+//	`name`=IF(VALUES(updated_at) > updated_at,VALUES(`name`),`name`)
+func guardDoUpdates(doUpdates clause.Set, where clause.Where) clause.Set {
+	set := make(clause.Set, 0, len(doUpdates))
+	for _, assignment := range doUpdates {
+		value := assignment.Value
+		if column, ok := value.(clause.Column); ok && column.Table == "excluded" {
+			column.Table = ""
+			value = clause.Expr{SQL: "VALUES(?)", Vars: []interface{}{column}}
+		}
+
+		set = append(set, clause.Assignment{
+			Column: assignment.Column,
+			Value:  clause.Expr{SQL: "IF(?,?,?)", Vars: []interface{}{clause.AndConditions{Exprs: where.Exprs}, value, assignment.Column}},
+		})
+	}
+	return set
+}
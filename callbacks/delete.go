@@ -153,6 +153,11 @@ func Delete(config *Config) func(db *gorm.DB) {
 			db.Statement.Build(db.Statement.BuildClauses...)
 		}
 
+		// check after building as soft delete builds the statement in its clauses
+		if db.WarnOnUnindexedWhere {
+			warnUnindexedWhere(db)
+		}
+
 		checkMissingWhereConditions(db)
 
 		if !db.DryRun && db.Error == nil {
@@ -178,7 +183,7 @@ func Delete(config *Config) func(db *gorm.DB) {
 				if db.Statement.Result != nil {
 					db.Statement.Result.RowsAffected = db.RowsAffected
 				}
-				db.AddError(rows.Close())
+				closeRows(db, rows)
 			}
 		}
 	}
@@ -1,11 +1,16 @@
 package callbacks
 
 import (
+	"database/sql"
 	"reflect"
+	"regexp"
 	"sort"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
 )
 
 // ConvertMapToValuesForCreate convert map to values
@@ -106,6 +111,58 @@ func hasReturning(tx *gorm.DB, supportReturning bool) (bool, gorm.ScanMode) {
 	return false, 0
 }
 
+// closeRows closes rows and adds the close error unless Config.IgnoreRowsCloseError,
+// a deferred error surfacing at close may mean the scanned result is truncated
+func closeRows(db *gorm.DB, rows *sql.Rows) {
+	if err := rows.Close(); !db.IgnoreRowsCloseError {
+		db.AddError(err)
+	}
+
+	// return the connection pinned for the rows, e.g: the one acquired with ConnAcquireTimeout
+	if releaser, ok := db.Statement.ConnPool.(interface{ ReleaseRows(*sql.Rows) }); ok {
+		releaser.ReleaseRows(rows)
+	}
+}
+
+// actorOf returns the actor of the statement's context with Config.ActorFunc
+func actorOf(stmt *gorm.Statement) (actor interface{}, ok bool) {
+	if stmt.DB.ActorFunc != nil {
+		actor, ok = stmt.DB.ActorFunc(stmt.Context)
+	}
+	return
+}
+
+// interceptBindValue applies the interceptor of Config.BindInterceptors named by the field's `normalize` tag to the value,
+// nil values and expressions are kept, pointers are dereferenced before intercepting
+func interceptBindValue(stmt *gorm.Statement, field *schema.Field, value interface{}) interface{} {
+	name, ok := field.TagSettings["NORMALIZE"]
+	if !ok {
+		return value
+	}
+
+	interceptor := stmt.DB.BindInterceptors[name]
+	if interceptor == nil {
+		return value
+	}
+
+	if _, ok := value.(clause.Expression); ok {
+		return value
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	for reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return value
+		}
+		reflectValue = reflectValue.Elem()
+	}
+
+	if !reflectValue.IsValid() {
+		return value
+	}
+	return interceptor(reflectValue.Interface())
+}
+
 func checkMissingWhereConditions(db *gorm.DB) {
 	// 倘若 AllowGlobalUpdate 标识不为 true 且 error 为空，则需要对 where 条件进行校验
 	if !db.AllowGlobalUpdate && db.Error == nil {
@@ -151,3 +208,122 @@ func loadOrStoreVisitMap(visitMap *visitMap, v reflect.Value) (loaded bool) {
 
 	return
 }
+
+// rawWhereColumnRegexp matches the columns compared in raw conditions, e.g: `name = ?`, `users.age IN ?`
+var rawWhereColumnRegexp = regexp.MustCompile(`(?i)([\w.` + "`" + `"\[\]]+)\s*(=|<>|!=|>=|<=|>|<|\bIN\b|\bLIKE\b|\bIS\b|\bBETWEEN\b)`)
+
+// warnUnindexedWhere logs a warning listing the WHERE columns of the current table without an index for Config.WarnOnUnindexedWhere,
+// a column is indexed if it's a primary key, unique, or all preceding columns of an index containing it are filtered as well,
+// it's a best-effort check with the indexes declared by the model, columns unknown to the schema are ignored
+func warnUnindexedWhere(db *gorm.DB) {
+	where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	var (
+		fields   []*schema.Field
+		filtered = map[string]bool{}
+		collect  func(exprs []clause.Expression)
+	)
+	addColumn := func(table, name string) {
+		if table != "" && table != clause.CurrentTable && table != db.Statement.Table {
+			return
+		}
+		if field := db.Statement.Schema.LookUpField(name); field != nil && field.DBName != "" && !filtered[field.DBName] {
+			filtered[field.DBName] = true
+			fields = append(fields, field)
+		}
+	}
+	addRawColumn := func(column string) {
+		parts := strings.Split(strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(column), ".")
+		if len(parts) > 1 {
+			addColumn(parts[len(parts)-2], parts[len(parts)-1])
+		} else {
+			addColumn("", parts[0])
+		}
+	}
+	addExprColumn := func(column interface{}) {
+		switch c := column.(type) {
+		case string:
+			addRawColumn(c)
+		case clause.Column:
+			if c.Raw {
+				addRawColumn(c.Name)
+			} else {
+				addColumn(c.Table, c.Name)
+			}
+		}
+	}
+
+	collect = func(exprs []clause.Expression) {
+		for _, expr := range exprs {
+			switch e := expr.(type) {
+			case clause.Eq:
+				addExprColumn(e.Column)
+			case clause.Neq:
+				addExprColumn(e.Column)
+			case clause.Gt:
+				addExprColumn(e.Column)
+			case clause.Gte:
+				addExprColumn(e.Column)
+			case clause.Lt:
+				addExprColumn(e.Column)
+			case clause.Lte:
+				addExprColumn(e.Column)
+			case clause.Like:
+				addExprColumn(e.Column)
+			case clause.IN:
+				addExprColumn(e.Column)
+			case clause.Expr:
+				for _, match := range rawWhereColumnRegexp.FindAllStringSubmatch(e.SQL, -1) {
+					addRawColumn(match[1])
+				}
+			case clause.NamedExpr:
+				for _, match := range rawWhereColumnRegexp.FindAllStringSubmatch(e.SQL, -1) {
+					addRawColumn(match[1])
+				}
+			case clause.StrictNamedExpr:
+				for _, match := range rawWhereColumnRegexp.FindAllStringSubmatch(e.SQL, -1) {
+					addRawColumn(match[1])
+				}
+			case clause.AndConditions:
+				collect(e.Exprs)
+			case clause.OrConditions:
+				collect(e.Exprs)
+			case clause.NotConditions:
+				collect(e.Exprs)
+			}
+		}
+	}
+	collect(where.Exprs)
+
+	indexed := map[string]bool{}
+	for _, field := range fields {
+		if field.PrimaryKey || field.Unique {
+			indexed[field.DBName] = true
+		}
+	}
+	for _, index := range db.Statement.Schema.ParseIndexes() {
+		for _, option := range index.Fields {
+			if option.Field == nil {
+				break
+			}
+			indexed[option.DBName] = true
+			if !filtered[option.DBName] {
+				break
+			}
+		}
+	}
+
+	var unindexed []string
+	for _, field := range fields {
+		if !indexed[field.DBName] {
+			unindexed = append(unindexed, db.Statement.Table+"."+field.DBName)
+		}
+	}
+
+	if len(unindexed) > 0 {
+		db.Logger.Warn(db.Statement.Context, "where on columns without index may be slow: %s, from %s", strings.Join(unindexed, ", "), utils.FileWithLineNum())
+	}
+}
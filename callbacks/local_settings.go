@@ -0,0 +1,99 @@
+package callbacks
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type localSettingsConn struct {
+	conn     *sql.Conn
+	connPool gorm.ConnPool
+	keys     []string
+}
+
+// ApplyLocalSettings issues the settings of WithLocalSettings before the statement, `SET LOCAL` is used in a transaction,
+// otherwise a connection is pinned for the statement and the settings are reset by ResetLocalSettings
+func ApplyLocalSettings(db *gorm.DB) {
+	v, ok := db.Get("gorm:local_settings")
+	if !ok || db.Error != nil || db.DryRun {
+		return
+	}
+
+	settings, _ := v.(map[string]string)
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		for _, key := range keys {
+			if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, "SET LOCAL "+key+" = "+quoteSettingValue(settings[key])); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+		return
+	}
+
+	var (
+		conn *sql.Conn
+		err  error
+	)
+	if pinner, ok := db.Statement.ConnPool.(interface {
+		Conn(context.Context) (*sql.Conn, error)
+	}); ok {
+		conn, err = pinner.Conn(db.Statement.Context)
+	} else if sqlDB, dbErr := db.DB(); dbErr == nil {
+		conn, err = sqlDB.Conn(db.Statement.Context)
+	} else {
+		err = dbErr
+	}
+
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+
+	pinned := &localSettingsConn{conn: conn, connPool: db.Statement.ConnPool}
+	db.InstanceSet("gorm:local_settings_conn", pinned)
+	db.Statement.ConnPool = conn
+
+	for _, key := range keys {
+		if _, err := conn.ExecContext(db.Statement.Context, "SET "+key+" = "+quoteSettingValue(settings[key])); err != nil {
+			db.AddError(err)
+			return
+		}
+		pinned.keys = append(pinned.keys, key)
+	}
+}
+
+// ResetLocalSettings resets the settings on the pinned connection and releases it
+func ResetLocalSettings(db *gorm.DB) {
+	v, ok := db.InstanceGet("gorm:local_settings_conn")
+	if !ok {
+		return
+	}
+
+	pinned := v.(*localSettingsConn)
+	for _, key := range pinned.keys {
+		if _, err := pinned.conn.ExecContext(context.Background(), "SET "+key+" = DEFAULT"); err != nil {
+			db.AddError(err)
+			// the connection may keep the setting, discard it instead of returning it to the pool
+			_ = pinned.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			break
+		}
+	}
+
+	db.AddError(pinned.conn.Close())
+	db.Statement.ConnPool = pinned.connPool
+}
+
+func quoteSettingValue(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
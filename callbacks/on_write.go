@@ -0,0 +1,32 @@
+package callbacks
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// OnWrite reports the executed write to Config.OnWrite with the rows affected and the primary keys of the written values,
+// keys are collected from the values (filled by RETURNING or the inserted ids when creating), composite keys are
+// reported as []interface{}, keys are empty if the statement is built from conditions only
+func OnWrite(op string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.OnWrite == nil || db.Error != nil || db.DryRun || db.Statement.SQL.Len() == 0 {
+			return
+		}
+
+		var keys []interface{}
+		if db.Statement.Schema != nil && len(db.Statement.Schema.PrimaryFields) > 0 && db.Statement.ReflectValue.IsValid() {
+			_, values := schema.GetIdentityFieldValuesMap(db.Statement.Context, db.Statement.ReflectValue, db.Statement.Schema.PrimaryFields)
+			keys = make([]interface{}, 0, len(values))
+			for _, value := range values {
+				if len(value) == 1 {
+					keys = append(keys, value[0])
+				} else {
+					keys = append(keys, value)
+				}
+			}
+		}
+
+		db.OnWrite(db.Statement.Context, op, db.Statement.Table, db.RowsAffected, keys)
+	}
+}
@@ -221,7 +221,7 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 
 		joinResults := rel.JoinTable.MakeSlice().Elem()
 		column, values := schema.ToQueryValues(clause.CurrentTable, joinForeignKeys, joinForeignValues)
-		if err := tx.Where(clause.IN{Column: column, Values: values}).Find(joinResults.Addr().Interface()).Error; err != nil {
+		if err := findInBatches(tx, column, values, joinResults, nil); err != nil {
 			return err
 		}
 
@@ -275,21 +275,26 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 	column, values := schema.ToQueryValues(clause.CurrentTable, relForeignKeys, foreignValues)
 
 	if len(values) != 0 {
-		tx = tx.Model(reflectResults.Addr().Interface()).Where(clause.IN{Column: column, Values: values})
-
 		for _, cond := range conds {
-			if fc, ok := cond.(func(*gorm.DB) *gorm.DB); ok {
-				tx = fc(tx)
-			} else {
+			if _, ok := cond.(func(*gorm.DB) *gorm.DB); !ok {
 				inlineConds = append(inlineConds, cond)
 			}
 		}
 
-		if len(inlineConds) > 0 {
-			tx = tx.Where(inlineConds[0], inlineConds[1:]...)
-		}
+		if err := findInBatches(tx, column, values, reflectResults, func(tx *gorm.DB) *gorm.DB {
+			tx = tx.Model(reflectResults.Addr().Interface())
+
+			for _, cond := range conds {
+				if fc, ok := cond.(func(*gorm.DB) *gorm.DB); ok {
+					tx = fc(tx)
+				}
+			}
 
-		if err := tx.Find(reflectResults.Addr().Interface()).Error; err != nil {
+			if len(inlineConds) > 0 {
+				tx = tx.Where(inlineConds[0], inlineConds[1:]...)
+			}
+			return tx
+		}); err != nil {
 			return err
 		}
 	}
@@ -349,3 +354,34 @@ func preload(tx *gorm.DB, rel *schema.Relationship, conds []interface{}, preload
 
 	return tx.Error
 }
+
+// findInBatches finds records matching the IN condition into results, the values are split into chunks of PreloadBatchSize
+func findInBatches(tx *gorm.DB, column interface{}, values []interface{}, results reflect.Value, scope func(*gorm.DB) *gorm.DB) error {
+	find := func(tx *gorm.DB, values []interface{}, results reflect.Value) error {
+		tx = tx.Where(clause.IN{Column: column, Values: values})
+		if scope != nil {
+			tx = scope(tx)
+		}
+		return tx.Find(results.Addr().Interface()).Error
+	}
+
+	batchSize := tx.PreloadBatchSize
+	if batchSize <= 0 || len(values) <= batchSize {
+		return find(tx, values, results)
+	}
+
+	tx = tx.Session(&gorm.Session{})
+	for i := 0; i < len(values); i += batchSize {
+		end := i + batchSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		batchResults := reflect.New(results.Type()).Elem()
+		if err := find(tx, values[i:end], batchResults); err != nil {
+			return err
+		}
+		results.Set(reflect.AppendSlice(results, batchResults))
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package callbacks
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"math/rand"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -22,9 +28,15 @@ func Query(db *gorm.DB) {
 				return
 			}
 			defer func() {
-				db.AddError(rows.Close())
+				closeRows(db, rows)
+				explainSampledQuery(db)
 			}()
-			gorm.Scan(rows, db, 0)
+
+			if _, skip := db.Get("gorm:skip_max_rows"); db.MaxRows > 0 && !skip {
+				gorm.Scan(&maxRowsGuard{Rows: rows, db: db}, db, 0)
+			} else {
+				gorm.Scan(rows, db, 0)
+			}
 
 			if db.Statement.Result != nil {
 				db.Statement.Result.RowsAffected = db.RowsAffected
@@ -33,10 +45,90 @@ func Query(db *gorm.DB) {
 	}
 }
 
+// maxRowsGuard stops scanning with ErrTooManyRows once more than Config.MaxRows rows are read
+type maxRowsGuard struct {
+	gorm.Rows
+	db    *gorm.DB
+	count int
+}
+
+func (guard *maxRowsGuard) Next() bool {
+	if !guard.Rows.Next() {
+		return false
+	}
+
+	if guard.count++; guard.count > guard.db.MaxRows {
+		guard.db.AddError(fmt.Errorf("%w: more than %d rows", gorm.ErrTooManyRows, guard.db.MaxRows))
+		return false
+	}
+	return true
+}
+
+// explainWorkers bounds the sampled EXPLAIN running in the background, samples are dropped when all are busy
+var explainWorkers = make(chan struct{}, 4)
+
+// explainTimeout limits the duration of a sampled EXPLAIN
+const explainTimeout = 10 * time.Second
+
+// explainSampledQuery runs EXPLAIN for the sampled fraction of queries in the background and passes the plan to OnExplain,
+// EXPLAIN runs on the *sql.DB instead of the statement's pool, so it isn't prepared or affected by pinned connections,
+// queries in transactions aren't sampled, errors when explaining are ignored
+func explainSampledQuery(db *gorm.DB) {
+	if db.OnExplain == nil || db.ExplainSampleRate <= 0 || db.Error != nil || rand.Float64() >= db.ExplainSampleRate {
+		return
+	}
+
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		// e.g: the connection pinned by WithLocalSettings, use the pool of the DB
+		if sqlDB, err = (&gorm.DB{Config: db.Config}).DB(); err != nil {
+			return
+		}
+	}
+
+	select {
+	case explainWorkers <- struct{}{}:
+	default:
+		return
+	}
+
+	var (
+		sql       = db.Statement.SQL.String()
+		vars      = append([]interface{}{}, db.Statement.Vars...)
+		onExplain = db.OnExplain
+	)
+	go func() {
+		defer func() { <-explainWorkers }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+
+		if plan, err := explainQuery(ctx, sqlDB, sql, vars); err == nil {
+			onExplain(sql, plan)
+		}
+	}()
+}
+
+// explainQuery returns the plan of the query read by gorm.ScanPlan
+func explainQuery(ctx context.Context, sqlDB *sql.DB, query string, vars []interface{}) (string, error) {
+	rows, err := sqlDB.QueryContext(ctx, "EXPLAIN "+query, vars...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	return gorm.ScanPlan(rows)
+}
+
 // BuildQuerySQL
 // 构建sql
 func BuildQuerySQL(db *gorm.DB) {
-	if db.Statement.Schema != nil {
+	// the derived tables of DB.ValuesTable and DB.RawBuilder don't take the model's query clauses
+	if _, derivedTable := db.Get("gorm:derived_table"); db.Statement.Schema != nil && !derivedTable {
 		for _, c := range db.Statement.Schema.QueryClauses {
 			db.Statement.AddClause(c)
 		}
@@ -45,6 +137,15 @@ func BuildQuerySQL(db *gorm.DB) {
 	if db.Statement.SQL.Len() == 0 {
 		db.Statement.SQL.Grow(100)
 		clauseSelect := clause.Select{Distinct: db.Statement.Distinct}
+		for _, name := range db.Statement.DistinctOn {
+			if db.Statement.Schema != nil {
+				if f := db.Statement.Schema.LookUpField(name); f != nil {
+					clauseSelect.DistinctOn = append(clauseSelect.DistinctOn, clause.Column{Name: f.DBName})
+					continue
+				}
+			}
+			clauseSelect.DistinctOn = append(clauseSelect.DistinctOn, clause.Column{Name: name, Raw: true})
+		}
 
 		if db.Statement.ReflectValue.Kind() == reflect.Struct && db.Statement.ReflectValue.Type() == db.Statement.Schema.ModelType {
 			var conds []clause.Expression
@@ -108,6 +209,15 @@ func BuildQuerySQL(db *gorm.DB) {
 			fromClause = v
 		}
 
+		// tables of an implicit cross join (FROM a, b) may share column names, only select columns of the current table
+		// when querying into the model itself, other destinations may take columns of any table
+		if len(fromClause.Tables) > 1 && len(clauseSelect.Columns) == 0 && len(db.Statement.Selects) == 0 && isModelFromCurrentTable(db, fromClause) {
+			clauseSelect.Columns = make([]clause.Column, len(db.Statement.Schema.DBNames))
+			for idx, dbName := range db.Statement.Schema.DBNames {
+				clauseSelect.Columns[idx] = clause.Column{Table: db.Statement.Table, Name: dbName}
+			}
+		}
+
 		if len(db.Statement.Joins) != 0 || len(fromClause.Joins) != 0 {
 			if len(db.Statement.Selects) == 0 && len(db.Statement.Omits) == 0 && db.Statement.Schema != nil {
 				clauseSelect.Columns = make([]clause.Column, len(db.Statement.Schema.DBNames))
@@ -266,13 +376,141 @@ func BuildQuerySQL(db *gorm.DB) {
 			}
 
 			db.Statement.AddClause(fromClause)
+
+			if db.Config.WarnOnImplicitCrossJoin {
+				warnImplicitCrossJoins(db, fromClause.Joins)
+			}
 		} else {
 			db.Statement.AddClauseIfNotExists(clause.From{})
 		}
 
+		// alias the current table's columns like `users`.`name` AS `users_name` to avoid ambiguous column names
+		_, qualified := db.Get("gorm:select_qualified")
+		if !qualified && db.OnAmbiguousColumn == gorm.AmbiguousColumnPrefixWithTable && len(fromClause.Joins) != 0 {
+			qualified = true
+			db.Statement.Settings.Store("gorm:select_qualified", true)
+		}
+
+		if _, selected := db.Statement.Clauses["SELECT"]; qualified && !selected && db.Statement.Schema != nil && len(db.Statement.Selects) == 0 {
+			if len(clauseSelect.Columns) == 0 {
+				clauseSelect.Columns = make([]clause.Column, len(db.Statement.Schema.DBNames))
+				for idx, dbName := range db.Statement.Schema.DBNames {
+					clauseSelect.Columns[idx] = clause.Column{Name: dbName}
+				}
+			}
+
+			// the generated aliases are recorded, only they are mapped back to the columns when scanning
+			aliases := make(map[string]string, len(clauseSelect.Columns))
+			for idx, column := range clauseSelect.Columns {
+				if !column.Raw && column.Alias == "" && (column.Table == "" || column.Table == clause.CurrentTable || column.Table == db.Statement.Table) {
+					clauseSelect.Columns[idx].Table = db.Statement.Table
+					clauseSelect.Columns[idx].Alias = db.Statement.Table + "_" + column.Name
+					aliases[clauseSelect.Columns[idx].Alias] = column.Name
+				}
+			}
+			db.InstanceSet("gorm:qualified_aliases", aliases)
+		}
+
 		db.Statement.AddClauseIfNotExists(clauseSelect)
 
+		if db.AutoGroupBy {
+			autoGroupBy(db)
+		}
+
+		if db.WarnOnUnindexedWhere {
+			warnUnindexedWhere(db)
+		}
+
 		db.Statement.Build(db.Statement.BuildClauses...)
+
+		if selectClause, ok := db.Statement.Clauses["SELECT"].Expression.(clause.Select); ok && len(selectClause.DistinctOn) > 0 {
+			checkDistinctOnOrder(db, selectClause.DistinctOn)
+		}
+	}
+}
+
+var (
+	plainColumnRegexp  = regexp.MustCompile("^[\\w`\"\\[\\]]+(\\.[\\w`\"\\[\\]]+)*$")
+	selectAliasRegexp  = regexp.MustCompile(`(?i)^(.+?)\s+(AS\s+)?[\w` + "`" + `"\[\]]+$`)
+	rawJoinRegexp      = regexp.MustCompile(`(?i)\bJOIN\b`)
+	rawJoinCondRegexp  = regexp.MustCompile(`(?i)\b(ON|USING)\b`)
+	rawCrossJoinRegexp = regexp.MustCompile(`(?i)\b(CROSS|NATURAL)\s+(\w+\s+)?JOIN\b`)
+)
+
+// warnImplicitCrossJoins logs a warning for joins without ON/USING conditions, which likely produce a cartesian product,
+// explicit CROSS/NATURAL joins are not reported
+func warnImplicitCrossJoins(db *gorm.DB, joins []clause.Join) {
+	for _, join := range joins {
+		var joinSQL string
+		switch expr := join.Expression.(type) {
+		case nil:
+			if len(join.ON.Exprs) > 0 || len(join.Using) > 0 || join.Type == clause.CrossJoin {
+				continue
+			}
+			joinSQL = strings.TrimSpace(string(join.Type) + " JOIN " + join.Table.Name)
+		case clause.NamedExpr:
+			joinSQL = expr.SQL
+		case clause.StrictNamedExpr:
+			joinSQL = expr.SQL
+		case clause.Expr:
+			joinSQL = expr.SQL
+		default:
+			continue
+		}
+
+		if join.Expression != nil && (!rawJoinRegexp.MatchString(joinSQL) || rawJoinCondRegexp.MatchString(joinSQL) || rawCrossJoinRegexp.MatchString(joinSQL)) {
+			continue
+		}
+
+		db.Logger.Warn(db.Statement.Context, "join without ON/USING condition may produce a cartesian product: %s, from %s", joinSQL, utils.FileWithLineNum())
+	}
+}
+
+// checkDistinctOnOrder checks the leading ORDER BY columns match the DISTINCT ON columns in any order,
+// rows picked by DISTINCT ON are unpredictable otherwise
+func checkDistinctOnOrder(db *gorm.DB, distinctOn []clause.Column) {
+	orderBy, ok := db.Statement.Clauses["ORDER BY"].Expression.(clause.OrderBy)
+	if !ok || orderBy.Expression != nil {
+		return
+	}
+
+	var orderNames []string
+	for _, column := range orderBy.Columns {
+		if !column.Column.Raw {
+			orderNames = append(orderNames, column.Column.Name)
+			continue
+		}
+		// raw orders like `user_id, created_at DESC`
+		for _, name := range strings.Split(column.Column.Name, ",") {
+			if fields := strings.Fields(name); len(fields) > 0 {
+				orderNames = append(orderNames, fields[0])
+			}
+		}
+	}
+
+	if len(orderNames) < len(distinctOn) {
+		db.AddError(gorm.ErrDistinctOnOrderMismatch)
+		return
+	}
+
+	normalize := func(name string) string {
+		name = strings.Trim(name, "`\"")
+		if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+			name = strings.Trim(name[idx+1:], "`\"")
+		}
+		return strings.ToLower(name)
+	}
+
+	leading := make(map[string]bool, len(distinctOn))
+	for _, name := range orderNames[:len(distinctOn)] {
+		leading[normalize(name)] = true
+	}
+
+	for _, column := range distinctOn {
+		if !leading[normalize(column.Name)] {
+			db.AddError(gorm.ErrDistinctOnOrderMismatch)
+			return
+		}
 	}
 }
 
@@ -301,7 +539,8 @@ func AfterQuery(db *gorm.DB) {
 	// clear the joins after query because preload need it
 	if v, ok := db.Statement.Clauses["FROM"].Expression.(clause.From); ok {
 		fromClause := db.Statement.Clauses["FROM"]
-		fromClause.Expression = clause.From{Tables: v.Tables, Joins: utils.RTrimSlice(v.Joins, len(db.Statement.Joins))} // keep the original From Joins
+		v.Joins = utils.RTrimSlice(v.Joins, len(db.Statement.Joins)) // keep the original From Joins
+		fromClause.Expression = v
 		db.Statement.Clauses["FROM"] = fromClause
 	}
 	if db.Error == nil && db.Statement.Schema != nil && !db.Statement.SkipHooks && db.Statement.Schema.AfterFind && db.RowsAffected > 0 {
@@ -314,3 +553,150 @@ func AfterQuery(db *gorm.DB) {
 		})
 	}
 }
+
+// autoGroupBy appends the plain columns of the select list missing from the specified GROUP BY to it,
+// aggregates, other expressions and the `*` wildcard are skipped
+func autoGroupBy(db *gorm.DB) {
+	groupByClause, ok := db.Statement.Clauses["GROUP BY"]
+	if !ok {
+		return
+	}
+	groupBy, ok := groupByClause.Expression.(clause.GroupBy)
+	if !ok || len(groupBy.Columns) == 0 {
+		return
+	}
+	selectClause, ok := db.Statement.Clauses["SELECT"].Expression.(clause.Select)
+	if !ok || selectClause.Expression != nil {
+		return
+	}
+
+	grouped := groupedColumns{full: map[string]bool{}, names: map[string]bool{}}
+	for _, column := range groupBy.Columns {
+		grouped.add(column)
+	}
+
+	var missing []clause.Column
+	appendMissing := func(column clause.Column) {
+		if !grouped.contains(column) {
+			grouped.add(column)
+			missing = append(missing, column)
+		}
+	}
+
+	for _, column := range selectClause.Columns {
+		if !column.Raw {
+			if column.Name != clause.Associations && column.Name != "*" {
+				appendMissing(clause.Column{Table: column.Table, Name: column.Name})
+			}
+			continue
+		}
+
+		for _, item := range splitSelectList(column.Name) {
+			if matches := selectAliasRegexp.FindStringSubmatch(item); matches != nil {
+				item = strings.TrimSpace(matches[1])
+			}
+			if !plainColumnRegexp.MatchString(item) || isSelectLiteral(item) {
+				continue
+			}
+			appendMissing(clause.Column{Name: item, Raw: true})
+		}
+	}
+
+	if len(missing) > 0 {
+		groupBy.Columns = append(groupBy.Columns, missing...)
+		groupByClause.Expression = groupBy
+		db.Statement.Clauses["GROUP BY"] = groupByClause
+	}
+}
+
+// groupedColumns tracks the columns of GROUP BY, an unqualified column matches qualified columns of the same name
+type groupedColumns struct {
+	full  map[string]bool
+	names map[string]bool
+}
+
+func (grouped groupedColumns) split(column clause.Column) (table, name string) {
+	name = strings.ToLower(strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(column.Name))
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		table, name = name[:idx], name[idx+1:]
+	}
+	if column.Table != "" && column.Table != clause.CurrentTable {
+		table = strings.ToLower(column.Table)
+	}
+	return
+}
+
+func (grouped groupedColumns) add(column clause.Column) {
+	table, name := grouped.split(column)
+	grouped.full[table+"."+name] = true
+	grouped.names[name] = true
+}
+
+func (grouped groupedColumns) contains(column clause.Column) bool {
+	table, name := grouped.split(column)
+	if table == "" {
+		return grouped.names[name]
+	}
+	return grouped.full[table+"."+name] || grouped.full["."+name]
+}
+
+// splitSelectList splits a raw select list by the commas outside of parentheses and quotes
+func splitSelectList(list string) (items []string) {
+	var (
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(list); i++ {
+		switch c := list[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, strings.TrimSpace(list[start:]))
+}
+
+// isSelectLiteral reports whether the item is a number or keyword literal instead of a column
+func isSelectLiteral(item string) bool {
+	if _, err := strconv.ParseFloat(item, 64); err == nil {
+		return true
+	}
+	switch strings.ToUpper(item) {
+	case "NULL", "TRUE", "FALSE", "DISTINCT":
+		return true
+	}
+	return false
+}
+
+// isModelFromCurrentTable checks the destination is the model and the current table is listed without alias in FROM
+func isModelFromCurrentTable(db *gorm.DB, fromClause clause.From) bool {
+	if db.Statement.Schema == nil || db.Statement.TableExpr != nil || !db.Statement.ReflectValue.IsValid() {
+		return false
+	}
+
+	destType := db.Statement.ReflectValue.Type()
+	for destType.Kind() == reflect.Slice || destType.Kind() == reflect.Array || destType.Kind() == reflect.Ptr {
+		destType = destType.Elem()
+	}
+	if destType != db.Statement.Schema.ModelType {
+		return false
+	}
+
+	for _, table := range fromClause.Tables {
+		if (table.Name == db.Statement.Table || table.Name == clause.CurrentTable) && table.Alias == "" && !table.Raw {
+			return true
+		}
+	}
+	return false
+}
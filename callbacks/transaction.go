@@ -1,6 +1,8 @@
 package callbacks
 
 import (
+	"errors"
+
 	"gorm.io/gorm"
 )
 
@@ -8,6 +10,9 @@ func BeginTransaction(db *gorm.DB) {
 	if !db.Config.SkipDefaultTransaction && db.Error == nil {
 		if tx := db.Begin(); tx.Error == nil {
 			db.Statement.ConnPool = tx.Statement.ConnPool
+			if hooks, ok := tx.Get("gorm:after_commit_hooks"); ok {
+				db.Statement.Settings.Store("gorm:after_commit_hooks", hooks)
+			}
 			db.InstanceSet("gorm:started_transaction", true)
 		} else if tx.Error == gorm.ErrInvalidTransaction {
 			tx.Error = nil
@@ -20,13 +25,21 @@ func BeginTransaction(db *gorm.DB) {
 func CommitOrRollbackTransaction(db *gorm.DB) {
 	if !db.Config.SkipDefaultTransaction {
 		if _, ok := db.InstanceGet("gorm:started_transaction"); ok {
-			if db.Error != nil {
+			if ctxErr := db.Statement.Context.Err(); ctxErr != nil {
+				// never commit when the context is done, database/sql rollbacks the transaction already,
+				// so the rollback error (sql.ErrTxDone) is ignored to keep context.Canceled in db.Error
+				if !errors.Is(db.Error, ctxErr) {
+					db.AddError(ctxErr)
+				}
+				db.Session(&gorm.Session{}).Rollback()
+			} else if db.Error != nil {
 				db.Rollback()
 			} else {
 				db.Commit()
 			}
 
 			db.Statement.ConnPool = db.ConnPool
+			db.Statement.Settings.Delete("gorm:after_commit_hooks")
 		}
 	}
 }
@@ -84,11 +84,17 @@ func Update(config *Config) func(db *gorm.DB) {
 				}
 			}
 
+			if db.WarnOnUnindexedWhere {
+				warnUnindexedWhere(db)
+			}
+
 			db.Statement.Build(db.Statement.BuildClauses...)
 		}
 
-		// 校验 where 条件
-		checkMissingWhereConditions(db)
+		// 校验 where 条件，通过 db.GlobalUpdate() 显式允许全表更新时跳过
+		if _, ok := db.Get("gorm:global_update"); !ok {
+			checkMissingWhereConditions(db)
+		}
 
 		if !db.DryRun && db.Error == nil {
 			if ok, mode := hasReturning(db, supportReturning); ok {
@@ -97,7 +103,7 @@ func Update(config *Config) func(db *gorm.DB) {
 					db.Statement.Dest = db.Statement.ReflectValue.Addr().Interface()
 					gorm.Scan(rows, db, mode)
 					db.Statement.Dest = dest
-					db.AddError(rows.Close())
+					closeRows(db, rows)
 
 					if db.Statement.Result != nil {
 						db.Statement.Result.RowsAffected = db.RowsAffected
@@ -150,6 +156,7 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 	var (
 		selectColumns, restricted = stmt.SelectAndOmitColumns(false, true)
 		assignValue               func(field *schema.Field, value interface{})
+		actor, hasActor           = actorOf(stmt)
 	)
 
 	switch stmt.ReflectValue.Kind() {
@@ -260,6 +267,13 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 						}
 					}
 				}
+
+				if hasActor && field.Actor == schema.ActorUpdated && value[field.Name] == nil && value[field.DBName] == nil {
+					if v, ok := selectColumns[field.DBName]; (ok && v) || !ok {
+						assignValue(field, actor)
+						set = append(set, clause.Assignment{Column: clause.Column{Name: field.DBName}, Value: actor})
+					}
+				}
 			}
 		}
 	default:
@@ -280,7 +294,8 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 			for _, dbName := range stmt.Schema.DBNames {
 				if field := updatingSchema.LookUpField(dbName); field != nil {
 					if !field.PrimaryKey || !updatingValue.CanAddr() || stmt.Dest != stmt.Model {
-						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && (!restricted || (!stmt.SkipHooks && field.AutoUpdateTime > 0))) {
+						updateActor := hasActor && field.Actor == schema.ActorUpdated
+						if v, ok := selectColumns[field.DBName]; (ok && v) || (!ok && (!restricted || (!stmt.SkipHooks && (field.AutoUpdateTime > 0 || updateActor)))) {
 							value, isZero := field.ValueOf(stmt.Context, updatingValue)
 							if !stmt.SkipHooks && field.AutoUpdateTime > 0 {
 								if field.AutoUpdateTime == schema.UnixNanosecond {
@@ -293,6 +308,9 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 									value = stmt.DB.NowFunc()
 								}
 								isZero = false
+							} else if !stmt.SkipHooks && updateActor {
+								value = actor
+								isZero = false
 							}
 
 							if (ok || !isZero) && field.Updatable {
@@ -318,5 +336,13 @@ func ConvertToAssignments(stmt *gorm.Statement) (set clause.Set) {
 		}
 	}
 
+	if len(stmt.DB.BindInterceptors) > 0 && stmt.Schema != nil {
+		for idx, assignment := range set {
+			if field := stmt.Schema.LookUpField(assignment.Column.Name); field != nil {
+				set[idx].Value = interceptBindValue(stmt, field, assignment.Value)
+			}
+		}
+	}
+
 	return
 }
@@ -1,9 +1,11 @@
 package gorm
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/utils"
@@ -33,6 +35,8 @@ func (db *DB) Model(value interface{}) (tx *DB) {
 //	db.Clauses(hints.UseIndex("idx_user_name")).Find(&User{})
 //	// specify the lock strength to UPDATE
 //	db.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&users)
+//	// sample 10% rows of the table, the same rows are returned for the same seed
+//	db.Clauses(clause.TableSample{Method: "BERNOULLI", Percent: 10, Seed: &seed}).Find(&users)
 //
 // [docs]: https://gorm.io/docs/sql_builder.html#Clauses
 func (db *DB) Clauses(conds ...clause.Expression) (tx *DB) {
@@ -42,6 +46,10 @@ func (db *DB) Clauses(conds ...clause.Expression) (tx *DB) {
 	for _, cond := range conds {
 		if c, ok := cond.(clause.Interface); ok {
 			tx.Statement.AddClause(c)
+		} else if hint, ok := cond.(clause.OptimizerHint); ok {
+			tx.Statement.addOptimizerHint(hint)
+		} else if sample, ok := cond.(clause.TableSample); ok {
+			tx.Statement.addTableSample(sample)
 		} else if optimizer, ok := cond.(StatementModifier); ok {
 			optimizer.ModifyStatement(tx.Statement)
 		} else {
@@ -87,6 +95,46 @@ func (db *DB) Table(name string, args ...interface{}) (tx *DB) {
 	return
 }
 
+// Partition specify the partition table to insert into directly instead of routing through the parent table,
+// the partition must exist when creating, it's equivalent to Table on dialects or tables without partitioning
+//
+//	db.Partition("orders_2024_01").Create(&orders)
+//	// INSERT INTO `orders_2024_01` ...
+func (db *DB) Partition(name string) (tx *DB) {
+	return db.Table(name).Set("gorm:partition", name)
+}
+
+// ValuesTable specify a derived table of row values as the table you would like to run db operations,
+// values bind in row-major order, query clauses of the model (e.g: soft delete) aren't applied as the derived table
+// doesn't have their columns
+//
+//	db.ValuesTable("t", []string{"id", "name"}, [][]interface{}{{1, "a"}, {2, "b"}}).
+//		Joins("JOIN users ON users.id = t.id AND users.name <> t.name").Select("users.*").Find(&users)
+//	// SELECT users.* FROM (VALUES (1,'a'),(2,'b')) AS `t` (`id`,`name`) JOIN users ON ...
+func (db *DB) ValuesTable(alias string, columns []string, rows [][]interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if len(rows) == 0 {
+		tx.AddError(fmt.Errorf("%w: values table %s has no rows", ErrInvalidData, alias))
+		return
+	}
+
+	valuesTable := clause.ValuesTable{Alias: alias, Columns: make([]clause.Column, len(columns)), Values: rows}
+	for idx, column := range columns {
+		valuesTable.Columns[idx] = clause.Column{Name: column}
+	}
+
+	for _, row := range rows {
+		if len(columns) > 0 && len(row) != len(columns) {
+			tx.AddError(fmt.Errorf("%w: values table %s expects %d columns, got %v", ErrInvalidData, alias, len(columns), row))
+			return
+		}
+	}
+
+	tx.Statement.TableExpr = &clause.Expr{SQL: "?", Vars: []interface{}{valuesTable}}
+	tx.Statement.Table = alias
+	return tx.Set("gorm:derived_table", true)
+}
+
 // Distinct specify distinct fields that you want querying
 //
 //	// Select distinct names of users
@@ -102,6 +150,17 @@ func (db *DB) Distinct(args ...interface{}) (tx *DB) {
 	return
 }
 
+// DistinctOn keeps the first row of each group of rows with the same values of columns, e.g. `DISTINCT ON` of Postgres,
+// the leading ORDER BY columns must match the columns, otherwise ErrDistinctOnOrderMismatch is returned
+//
+//	db.DistinctOn("user_id").Order("user_id").Order("created_at DESC").Find(&orders)
+//	// SELECT DISTINCT ON ("user_id") * FROM "orders" ORDER BY "user_id","created_at DESC"
+func (db *DB) DistinctOn(columns ...string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.DistinctOn = append(tx.Statement.DistinctOn[:len(tx.Statement.DistinctOn):len(tx.Statement.DistinctOn)], columns...)
+	return
+}
+
 // Select specify fields that you want when querying, creating, updating
 //
 // Use Select when you only want a subset of the fields. By default, GORM will select all fields.
@@ -115,6 +174,11 @@ func (db *DB) Select(query interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 
 	switch v := query.(type) {
+	case clause.Expression:
+		tx.Statement.AddClause(clause.Select{
+			Distinct:   db.Statement.Distinct,
+			Expression: v,
+		})
 	case []string:
 		tx.Statement.Selects = v
 
@@ -247,6 +311,12 @@ func (db *DB) Or(query interface{}, args ...interface{}) (tx *DB) {
 //	db.Joins("Account").Find(&user)
 //	db.Joins("JOIN emails ON emails.user_id = users.id AND emails.email = ?", "jinzhu@example.org").Find(&user)
 //	db.Joins("Account", DB.Select("id").Where("user_id = users.id AND name = ?", "someName").Model(&Account{}))
+//
+// conditions of the *DB argument are added to the ON clause of the association join, unlike a WHERE after the join,
+// rows without a matching association are still returned with a zero value association
+//
+//	db.Joins("Company", DB.Where("Company.alive = ?", true)).Find(&users)
+//	// SELECT ... FROM `users` LEFT JOIN `companies` `Company` ON `users`.`company_id` = `Company`.`id` AND Company.alive = true
 func (db *DB) Joins(query string, args ...interface{}) (tx *DB) {
 	return joins(db, clause.LeftJoin, query, args...)
 }
@@ -304,6 +374,21 @@ func (db *DB) Having(query interface{}, args ...interface{}) (tx *DB) {
 	return
 }
 
+// QualifyWindow wraps the current query as derived table `alias` and filters it with cond, like `QUALIFY`,
+// used for filtering on window function results. Soft delete conditions are only applied to the inner query
+//
+//	// top 1 oldest user per company
+//	db.Model(&User{}).Select("*, ROW_NUMBER() OVER (PARTITION BY company_id ORDER BY age DESC) AS rn").
+//		QualifyWindow("t", clause.Eq{Column: "rn", Value: 1}).Find(&users)
+//	// SELECT * FROM (SELECT *, ROW_NUMBER() OVER (...) AS rn FROM `users` WHERE `users`.`deleted_at` IS NULL) AS `t` WHERE `rn` = 1
+func (db *DB) QualifyWindow(alias string, cond clause.Expression) (tx *DB) {
+	subQuery := db.getInstance()
+	tx = subQuery.Session(&Session{NewDB: true}).Table("(?) AS "+subQuery.Statement.Quote(alias), subQuery)
+	tx.Statement.Table = alias
+	tx.Statement.Unscoped = true
+	return tx.Where(cond)
+}
+
 // Order specify order when retrieving records from database
 //
 //	db.Order("name DESC")
@@ -334,6 +419,50 @@ func (db *DB) Order(value interface{}) (tx *DB) {
 	return
 }
 
+// OrderBySafe specify order from a user supplied spec like "name asc,created_at desc", fields are validated against
+// allowed (external name -> real column), unknown fields or directions add ErrInvalidSort
+//
+//	db.OrderBySafe(c.Query("sort"), map[string]string{"name": "name", "created": "users.created_at"})
+func (db *DB) OrderBySafe(spec string, allowed map[string]string) (tx *DB) {
+	tx = db.getInstance()
+
+	orderBy := clause.OrderBy{}
+	for _, item := range strings.Split(spec, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 {
+			continue
+		}
+
+		column, ok := allowed[fields[0]]
+		if !ok || len(fields) > 2 {
+			tx.AddError(fmt.Errorf("%w: %s", ErrInvalidSort, strings.TrimSpace(item)))
+			return
+		}
+
+		orderByColumn := clause.OrderByColumn{Column: clause.Column{Name: column}}
+		if tables := strings.Split(column, "."); len(tables) == 2 {
+			orderByColumn.Column = clause.Column{Table: tables[0], Name: tables[1]}
+		}
+
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				orderByColumn.Desc = true
+			default:
+				tx.AddError(fmt.Errorf("%w: %s", ErrInvalidSort, strings.TrimSpace(item)))
+				return
+			}
+		}
+		orderBy.Columns = append(orderBy.Columns, orderByColumn)
+	}
+
+	if len(orderBy.Columns) > 0 {
+		tx.Statement.AddClause(orderBy)
+	}
+	return
+}
+
 // Limit specify the number of records to be retrieved
 //
 // Limit conditions can be cancelled by using `Limit(-1)`.
@@ -460,6 +589,192 @@ func (db *DB) Unscoped() (tx *DB) {
 	return
 }
 
+// WithInsert adds the insert as the common table expression `name`, so the statement can use the rows it returns
+// in one round trip, the insert should be built with DryRun, its vars are spliced before the statement's vars.
+// Data-modifying statements in WITH are supported by Postgres only
+//
+//	ins := db.Session(&gorm.Session{DryRun: true}).Create(&user)
+//	db.WithInsert("ins", ins).Table("pets").Create(map[string]interface{}{"name": "pet", "user_id": gorm.Expr("(SELECT id FROM ins)")})
+//	// WITH "ins" AS (INSERT INTO "users" ... RETURNING "id") INSERT INTO "pets" ("name","user_id") VALUES ($3,(SELECT id FROM ins))
+func (db *DB) WithInsert(name string, insertQuery *DB) (tx *DB) {
+	tx = db.getInstance()
+	if insertQuery == nil || insertQuery.Statement.SQL.Len() == 0 {
+		tx.AddError(fmt.Errorf("%w: the insert of %s should be built with DryRun", ErrInvalidData, name))
+		return
+	} else if insertQuery.Error != nil {
+		tx.AddError(insertQuery.Error)
+		return
+	}
+
+	tx.Statement.AddClause(clause.With{CTEs: []clause.CTE{{
+		Name: name, Expression: clause.Expr{SQL: "?", Vars: []interface{}{insertQuery}},
+	}}})
+	return
+}
+
+// GlobalUpdate allows the following update to run without conditions, unlike Config.AllowGlobalUpdate,
+// it only applies to the current statement and doesn't affect deletes
+//
+//	db.Model(&User{}).GlobalUpdate().Update("active", false)
+//	// UPDATE users SET active=false
+func (db *DB) GlobalUpdate() (tx *DB) {
+	return db.Set("gorm:global_update", true)
+}
+
+// SelectQualified selects the columns of the current table aliased with the table name, the aliased columns are
+// scanned back into the fields, useful to avoid duplicate column names of joined tables
+//
+//	db.SelectQualified().Joins("JOIN pets ON pets.user_id = users.id").Find(&users)
+//	// SELECT `users`.`id` AS `users_id`,`users`.`name` AS `users_name`,... FROM `users` JOIN pets ON pets.user_id = users.id
+func (db *DB) SelectQualified() (tx *DB) {
+	return db.Set("gorm:select_qualified", true)
+}
+
+// ResourceGroup runs the statement in the MySQL 8 resource group `name` with the RESOURCE_GROUP optimizer hint,
+// it's a no-op on other dialects
+//
+//	db.ResourceGroup("reporting").Find(&orders)
+//	// SELECT /*+ RESOURCE_GROUP(reporting) */ * FROM `orders`
+func (db *DB) ResourceGroup(name string) (tx *DB) {
+	tx = db.getInstance()
+	if name == "" || strings.IndexFunc(name, utils.IsValidDBNameChar) >= 0 {
+		tx.AddError(fmt.Errorf("invalid resource group name %q", name))
+		return
+	}
+
+	if tx.Dialector == nil || tx.Dialector.Name() != "mysql" {
+		return
+	}
+
+	tx.Statement.addOptimizerHint(clause.OptimizerHint{Hints: []string{"RESOURCE_GROUP(" + name + ")"}})
+	return
+}
+
+// InsertIgnore skips rows that conflict with an existing unique key when creating, MySQL uses `INSERT IGNORE`,
+// other dialects use `ON CONFLICT DO NOTHING`
+//
+//	db.InsertIgnore().Create(&users)
+//	// MySQL: INSERT IGNORE INTO `users` ...
+//	// Postgres/SQLite: INSERT INTO "users" ... ON CONFLICT DO NOTHING
+func (db *DB) InsertIgnore() (tx *DB) {
+	tx = db.getInstance()
+	if tx.Dialector != nil && tx.Dialector.Name() == "mysql" {
+		tx.Statement.AddClause(clause.Insert{Modifier: "IGNORE"})
+	} else {
+		tx.Statement.AddClause(clause.OnConflict{DoNothing: true})
+	}
+	return
+}
+
+// WithLocalSettings sets session variables for the duration of the statement, `SET LOCAL key = 'value'` is issued
+// before the statement in a transaction (including the default transaction of Create/Update/Delete),
+// otherwise a connection is pinned, `SET key = 'value'` before the statement and `SET key = DEFAULT` afterward.
+// It isn't applied to Row/Rows, use an explicit transaction for them, only PostgreSQL is supported
+//
+//	db.WithLocalSettings(map[string]string{"statement_timeout": "5s"}).Find(&users)
+func (db *DB) WithLocalSettings(settings map[string]string) (tx *DB) {
+	tx = db.getInstance()
+	if name := tx.Statement.DialectName(); name != "postgres" {
+		tx.AddError(fmt.Errorf("%w: local settings are not supported by %s", ErrUnsupportedDriver, name))
+		return
+	}
+
+	localSettings := make(map[string]string, len(settings))
+	for key, value := range settings {
+		if key == "" || strings.IndexFunc(key, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' && r != '.'
+		}) >= 0 {
+			tx.AddError(fmt.Errorf("invalid setting name %q", key))
+			return
+		}
+
+		if strings.ContainsAny(value, "\\\x00") {
+			tx.AddError(fmt.Errorf("invalid value %q of setting %s", value, key))
+			return
+		}
+		localSettings[key] = value
+	}
+
+	if len(localSettings) > 0 {
+		tx.Statement.Settings.Store("gorm:local_settings", localSettings)
+	}
+	return
+}
+
+// PreparedKey specify the key for caching the prepared statement in PreparedStmt mode,
+// the statement is cached by the key combined with the SQL without comments and redundant whitespaces,
+// so statements only differ in comments share the same prepared statement, and a different key separates them
+//
+//	db.Session(&Session{PrepareStmt: true}).PreparedKey("report").Raw("/* page 1 */ SELECT * FROM users").Scan(&users)
+func (db *DB) PreparedKey(key string) (tx *DB) {
+	return db.Set("gorm:prepared_key", key)
+}
+
+type routeKeyCtxKey struct{}
+
+// RouteKey attaches a routing key to the statement context, it is kept out of the SQL,
+// a ConnPool wrapper could read it with RouteKeyFrom to pick a backend
+//
+//	db.RouteKey("replica").Find(&users)
+func (db *DB) RouteKey(key string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Context = context.WithValue(tx.Statement.Context, routeKeyCtxKey{}, key)
+	return
+}
+
+// RouteKeyFrom returns the routing key set by DB.RouteKey from the context
+func RouteKeyFrom(ctx context.Context) (key string, ok bool) {
+	if ctx == nil {
+		return "", false
+	}
+	key, ok = ctx.Value(routeKeyCtxKey{}).(string)
+	return
+}
+
+// WriteDB forces the statement onto the writer (primary) connection even for a SELECT, it's executed on the ConnPool
+// returned by WriterSelector if the ConnPool implements it, resolver plugins could check the "gorm:use_writer" setting.
+// A transaction takes precedence over read/write hints, statements in it always use the connection of the transaction
+//
+//	db.WriteDB().First(&user, id) // read your own write
+func (db *DB) WriteDB() (tx *DB) {
+	return db.Set("gorm:use_writer", true)
+}
+
+type queryCacheCtxKey struct{}
+
+type queryCacheUsage struct {
+	read, write bool
+}
+
+// RefreshCache makes query cache plugins skip reading the cached result but still write the fresh result back,
+// e.g: force refreshing after a known write, it's a no-op without a query cache plugin
+//
+//	db.RefreshCache().First(&user, id)
+func (db *DB) RefreshCache() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Context = context.WithValue(tx.Statement.Context, queryCacheCtxKey{}, queryCacheUsage{write: true})
+	return
+}
+
+// NoCache makes query cache plugins neither read nor write the cache for the statement,
+// it's a no-op without a query cache plugin
+func (db *DB) NoCache() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Context = context.WithValue(tx.Statement.Context, queryCacheCtxKey{}, queryCacheUsage{})
+	return
+}
+
+// QueryCacheFrom returns whether a query cache plugin could read and write the cache for the statement with the context,
+// both are true unless DB.RefreshCache or DB.NoCache is used
+func QueryCacheFrom(ctx context.Context) (read, write bool) {
+	if ctx != nil {
+		if usage, ok := ctx.Value(queryCacheCtxKey{}).(queryCacheUsage); ok {
+			return usage.read, usage.write
+		}
+	}
+	return true, true
+}
+
 func (db *DB) Raw(sql string, values ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.SQL = strings.Builder{}
@@ -471,3 +786,27 @@ func (db *DB) Raw(sql string, values ...interface{}) (tx *DB) {
 	}
 	return
 }
+
+// RawBuilder uses the raw SQL with positional args as a derived table aliased alias, so clause methods can filter,
+// order and paginate it, query clauses of the model (e.g: soft delete) aren't applied as the derived table is
+// filtered by the raw SQL already
+//
+//	db.RawBuilder("t", "SELECT * FROM users WHERE age > ?", 18).Where("name LIKE ?", "jin%").Order("id").Limit(10).Find(&users)
+//	// SELECT * FROM (SELECT * FROM users WHERE age > 18) AS `t` WHERE name LIKE "jin%" ORDER BY id LIMIT 10
+func (db *DB) RawBuilder(alias string, sql string, values ...interface{}) (tx *DB) {
+	return db.rawBuilder(alias, clause.Expr{SQL: sql, Vars: values})
+}
+
+// NamedRawBuilder works like RawBuilder, but the raw SQL uses @name args, e.g:
+//
+//	db.NamedRawBuilder("t", "SELECT * FROM users WHERE age > @age", sql.Named("age", 18)).Limit(10).Find(&users)
+func (db *DB) NamedRawBuilder(alias string, sql string, values ...interface{}) (tx *DB) {
+	return db.rawBuilder(alias, clause.NamedExpr{SQL: sql, Vars: values})
+}
+
+func (db *DB) rawBuilder(alias string, base clause.Expression) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.TableExpr = &clause.Expr{SQL: "(?) AS " + tx.Statement.Quote(alias), Vars: []interface{}{base}}
+	tx.Statement.Table = alias
+	return tx.Set("gorm:derived_table", true)
+}
@@ -0,0 +1,64 @@
+package clause
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FilterClauseDialects dialects support `FILTER (WHERE ...)` on aggregates,
+// AggregateFilter falls back to the CASE form on other dialects
+var FilterClauseDialects = map[string]bool{"postgres": true, "sqlite": true}
+
+// dialectNamer implemented by builders know the current dialect, e.g: *gorm.Statement
+type dialectNamer interface {
+	DialectName() string
+}
+
+var aggregateRegexp = regexp.MustCompile(`(?is)^\s*(\w+)\s*\(\s*(DISTINCT\s+)?(.*?)\s*\)\s*$`)
+
+// AggregateFilter aggregate with filter, e.g:
+//
+//	count(*) FILTER (WHERE `status` = ?)
+//	// falls back to the CASE form on dialects lacking FILTER
+//	count(CASE WHEN `status` = ? THEN 1 END)
+type AggregateFilter struct {
+	Aggregate Expression
+	Filter    Where
+}
+
+// Build build aggregate filter
+func (af AggregateFilter) Build(builder Builder) {
+	if len(af.Filter.Exprs) == 0 {
+		af.Aggregate.Build(builder)
+		return
+	}
+
+	if namer, ok := builder.(dialectNamer); ok && !FilterClauseDialects[namer.DialectName()] {
+		if expr, ok := af.Aggregate.(Expr); ok {
+			if matches := aggregateRegexp.FindStringSubmatch(expr.SQL); len(matches) == 4 {
+				arg := matches[3]
+				if arg == "*" {
+					arg = "1"
+				}
+
+				builder.WriteString(matches[1])
+				builder.WriteByte('(')
+				if matches[2] != "" {
+					builder.WriteString(strings.ToUpper(strings.TrimSpace(matches[2])))
+					builder.WriteByte(' ')
+				}
+				builder.WriteString("CASE WHEN ")
+				af.Filter.Build(builder)
+				builder.WriteString(" THEN ")
+				Expr{SQL: arg, Vars: expr.Vars, WithoutParentheses: expr.WithoutParentheses}.Build(builder)
+				builder.WriteString(" END)")
+				return
+			}
+		}
+	}
+
+	af.Aggregate.Build(builder)
+	builder.WriteString(" FILTER (WHERE ")
+	af.Filter.Build(builder)
+	builder.WriteByte(')')
+}
@@ -3,6 +3,7 @@ package clause
 import (
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"go/ast"
 	"reflect"
 )
@@ -81,8 +82,20 @@ type NamedExpr struct {
 	Vars []interface{}
 }
 
+// StrictNamedExpr named expr reporting the names not found in Vars as errors, NamedExpr writes them as they are, e.g: @var of MySQL
+type StrictNamedExpr NamedExpr
+
+// Build build raw expression
+func (expr StrictNamedExpr) Build(builder Builder) {
+	NamedExpr(expr).build(builder, true)
+}
+
 // Build build raw expression
 func (expr NamedExpr) Build(builder Builder) {
+	expr.build(builder, false)
+}
+
+func (expr NamedExpr) build(builder Builder, strict bool) {
 	var (
 		idx              int
 		inName           bool
@@ -122,6 +135,16 @@ func (expr NamedExpr) Build(builder Builder) {
 	}
 
 	name := make([]byte, 0, 10)
+	writeName := func() {
+		if nv, ok := namedMap[string(name)]; ok {
+			builder.AddVar(builder, nv)
+		} else if strict && len(name) > 0 {
+			builder.AddError(fmt.Errorf("named argument @%s not found in %q", name, expr.SQL))
+		} else {
+			builder.WriteByte('@')
+			builder.WriteString(string(name))
+		}
+	}
 
 	for _, v := range []byte(expr.SQL) {
 		if v == '@' && !inName {
@@ -129,12 +152,7 @@ func (expr NamedExpr) Build(builder Builder) {
 			name = name[:0]
 		} else if v == ' ' || v == ',' || v == ')' || v == '"' || v == '\'' || v == '`' || v == '\r' || v == '\n' || v == ';' {
 			if inName {
-				if nv, ok := namedMap[string(name)]; ok {
-					builder.AddVar(builder, nv)
-				} else {
-					builder.WriteByte('@')
-					builder.WriteString(string(name))
-				}
+				writeName()
 				inName = false
 			}
 
@@ -179,12 +197,7 @@ func (expr NamedExpr) Build(builder Builder) {
 	}
 
 	if inName {
-		if nv, ok := namedMap[string(name)]; ok {
-			builder.AddVar(builder, nv)
-		} else {
-			builder.WriteByte('@')
-			builder.WriteString(string(name))
-		}
+		writeName()
 	}
 }
 
@@ -235,6 +248,68 @@ func (in IN) NegationBuild(builder Builder) {
 	}
 }
 
+// TupleIN Whether a row value is within a set of row values, e.g: (a, b) IN ((1, 2), (3, 4))
+type TupleIN struct {
+	Columns []Column
+	Values  [][]interface{}
+}
+
+func (in TupleIN) Build(builder Builder) {
+	in.build(builder, " IN ")
+}
+
+func (in TupleIN) NegationBuild(builder Builder) {
+	in.build(builder, " NOT IN ")
+}
+
+func (in TupleIN) build(builder Builder, op string) {
+	builder.WriteQuoted(in.Columns)
+	builder.WriteString(op)
+	builder.WriteByte('(')
+
+	if len(in.Values) == 0 {
+		builder.WriteByte('(')
+		for idx := range in.Columns {
+			if idx > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString("NULL")
+		}
+		builder.WriteByte(')')
+	}
+
+	for idx, values := range in.Values {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteByte('(')
+		builder.AddVar(builder, values...)
+		builder.WriteByte(')')
+	}
+	builder.WriteByte(')')
+}
+
+// Expand returns the equivalent OR of ANDs conditions, for databases don't support row value IN
+//
+//	(a = 1 AND b = 2) OR (a = 3 AND b = 4)
+func (in TupleIN) Expand() Expression {
+	if len(in.Values) == 0 {
+		return Expr{SQL: "1 = 0"}
+	}
+
+	exprs := make([]Expression, 0, len(in.Values))
+	for _, values := range in.Values {
+		conds := make([]Expression, 0, len(in.Columns))
+		for idx, column := range in.Columns {
+			if idx < len(values) {
+				conds = append(conds, Eq{Column: column, Value: values[idx]})
+			}
+		}
+		exprs = append(exprs, AndConditions{Exprs: conds})
+	}
+	return OrConditions{Exprs: exprs}
+}
+
 // Eq equal to for where
 type Eq struct {
 	Column interface{}
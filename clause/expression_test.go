@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 
@@ -150,6 +151,28 @@ func TestNamedExpr(t *testing.T) {
 	}
 }
 
+func TestStrictNamedExpr(t *testing.T) {
+	user, _ := schema.Parse(&tests.User{}, &sync.Map{}, db.NamingStrategy)
+
+	stmt := &gorm.Statement{DB: db.Session(&gorm.Session{}), Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}}
+	gorm.NamedExpr("amount > @min AND amount < @max AND @min < 100", map[string]interface{}{"min": 1, "max": 10}).Build(stmt)
+	if stmt.SQL.String() != "amount > ? AND amount < ? AND ? < 100" || stmt.Error != nil {
+		t.Errorf("generated SQL is not equal, got %v, error %v", stmt.SQL.String(), stmt.Error)
+	}
+	if !reflect.DeepEqual([]interface{}{1, 10, 1}, stmt.Vars) {
+		t.Errorf("generated vars is not equal, got %v", stmt.Vars)
+	}
+
+	stmt = &gorm.Statement{DB: db.Session(&gorm.Session{}), Table: user.Table, Schema: user, Clauses: map[string]clause.Clause{}}
+	gorm.NamedExpr("amount > @min AND amount < @max", sql.Named("min", 1)).Build(stmt)
+	if stmt.Error == nil || !strings.Contains(stmt.Error.Error(), "@max") {
+		t.Errorf("should report the missing named argument, got %v", stmt.Error)
+	}
+	if len(stmt.Vars) != 1 {
+		t.Errorf("should not bind the missing named argument, got %v", stmt.Vars)
+	}
+}
+
 func TestExpression(t *testing.T) {
 	column := "column-name"
 	results := []struct {
@@ -216,6 +239,58 @@ func TestExpression(t *testing.T) {
 		},
 		ExpectedVars: []interface{}{100},
 		Result:       "SUM(`users`.`id`) >= ?",
+	}, {
+		Expressions: []clause.Expression{
+			clause.TupleIN{Columns: []clause.Column{{Name: "a"}, {Name: "b"}}, Values: [][]interface{}{{1, 2}, {3, 4}}},
+		},
+		ExpectedVars: []interface{}{1, 2, 3, 4},
+		Result:       "(`a`,`b`) IN ((?,?),(?,?))",
+	}, {
+		Expressions: []clause.Expression{
+			clause.Not(clause.TupleIN{Columns: []clause.Column{{Name: "a"}, {Name: "b"}}, Values: [][]interface{}{{1, 2}}}),
+		},
+		ExpectedVars: []interface{}{1, 2},
+		Result:       "(`a`,`b`) NOT IN ((?,?))",
+	}, {
+		Expressions: []clause.Expression{
+			clause.TupleIN{Columns: []clause.Column{{Name: "a"}, {Name: "b"}}},
+		},
+		Result: "(`a`,`b`) IN ((NULL,NULL))",
+	}, {
+		Expressions: []clause.Expression{
+			clause.TupleIN{Columns: []clause.Column{{Name: "a"}, {Name: "b"}}, Values: [][]interface{}{{1, 2}, {3, 4}}}.Expand(),
+		},
+		ExpectedVars: []interface{}{1, 2, 3, 4},
+		Result:       "((`a` = ? AND `b` = ?) OR (`a` = ? AND `b` = ?))",
+	}, {
+		Expressions: []clause.Expression{
+			clause.ValuesTable{Alias: "t", Columns: []clause.Column{{Name: "id"}, {Name: "name"}}, Values: [][]interface{}{{1, "a"}, {2, "b"}}},
+		},
+		ExpectedVars: []interface{}{1, "a", 2, "b"},
+		Result:       "(VALUES (?,?),(?,?)) AS `t` (`id`,`name`)",
+	}, {
+		Expressions: []clause.Expression{
+			clause.ValuesTable{Alias: "t", Values: [][]interface{}{{[]byte("a")}}},
+		},
+		ExpectedVars: []interface{}{[]byte("a")},
+		Result:       "(VALUES (?)) AS `t`",
+	}, {
+		Expressions: []clause.Expression{
+			clause.AggregateFilter{Aggregate: clause.Expr{SQL: "count(*)"}, Filter: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "status", Value: "paid"}}}},
+		},
+		ExpectedVars: []interface{}{"paid"},
+		Result:       "count(CASE WHEN `status` = ? THEN 1 END)",
+	}, {
+		Expressions: []clause.Expression{
+			clause.AggregateFilter{Aggregate: clause.Expr{SQL: "SUM(DISTINCT amount * ?)", Vars: []interface{}{2}}, Filter: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "status", Value: "paid"}, clause.Gt{Column: "age", Value: 18}}}},
+		},
+		ExpectedVars: []interface{}{"paid", 18, 2},
+		Result:       "SUM(DISTINCT CASE WHEN `status` = ? AND `age` > ? THEN amount * ? END)",
+	}, {
+		Expressions: []clause.Expression{
+			clause.AggregateFilter{Aggregate: clause.Expr{SQL: "count(*)"}},
+		},
+		Result: "count(*)",
 	}}
 
 	for idx, result := range results {
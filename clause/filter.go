@@ -0,0 +1,125 @@
+package clause
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FilterNode node of a filter tree, usually decoded from JSON, e.g:
+//
+//	{"and":[{"field":"age","op":"gt","value":18},{"not":{"field":"name","op":"like","value":"%jinzhu%"}}]}
+//
+// a node is either a group (and/or/not) or a condition (field/op/value)
+type FilterNode struct {
+	And   []FilterNode `json:"and,omitempty"`
+	Or    []FilterNode `json:"or,omitempty"`
+	Not   *FilterNode  `json:"not,omitempty"`
+	Field string       `json:"field,omitempty"`
+	Op    string       `json:"op,omitempty"`
+	Value interface{}  `json:"value,omitempty"`
+}
+
+// FilterOps operators allowed in filter conditions
+var FilterOps = map[string]func(column Column, value interface{}) (Expression, error){
+	"eq": func(column Column, value interface{}) (Expression, error) {
+		return Eq{Column: column, Value: value}, nil
+	},
+	"neq": func(column Column, value interface{}) (Expression, error) {
+		return Neq{Column: column, Value: value}, nil
+	},
+	"gt": func(column Column, value interface{}) (Expression, error) {
+		return Gt{Column: column, Value: value}, nil
+	},
+	"gte": func(column Column, value interface{}) (Expression, error) {
+		return Gte{Column: column, Value: value}, nil
+	},
+	"lt": func(column Column, value interface{}) (Expression, error) {
+		return Lt{Column: column, Value: value}, nil
+	},
+	"lte": func(column Column, value interface{}) (Expression, error) {
+		return Lte{Column: column, Value: value}, nil
+	},
+	"like": func(column Column, value interface{}) (Expression, error) {
+		return Like{Column: column, Value: value}, nil
+	},
+	"in": func(column Column, value interface{}) (Expression, error) {
+		values, err := filterValues(value)
+		return IN{Column: column, Values: values}, err
+	},
+	"nin": func(column Column, value interface{}) (Expression, error) {
+		values, err := filterValues(value)
+		return NotConditions{Exprs: []Expression{IN{Column: column, Values: values}}}, err
+	},
+}
+
+// FromFilter converts a filter tree to conditions, allowed maps the external field names to columns,
+// unknown fields, operators or malformed nodes return an error, values are always bound as vars
+func FromFilter(spec FilterNode, allowed map[string]string) (Expression, error) {
+	var kinds int
+	for _, group := range []bool{spec.And != nil, spec.Or != nil, spec.Not != nil, spec.Field != "" || spec.Op != ""} {
+		if group {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return nil, fmt.Errorf("invalid filter node, expects exactly one of and, or, not or a field condition")
+	}
+
+	switch {
+	case spec.And != nil || spec.Or != nil:
+		nodes := spec.And
+		if spec.Or != nil {
+			nodes = spec.Or
+		}
+		if len(nodes) == 0 {
+			return nil, fmt.Errorf("invalid filter node, empty group")
+		}
+
+		exprs := make([]Expression, 0, len(nodes))
+		for _, node := range nodes {
+			expr, err := FromFilter(node, allowed)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, expr)
+		}
+
+		if len(exprs) == 1 {
+			return exprs[0], nil
+		} else if spec.Or != nil {
+			return OrConditions{Exprs: exprs}, nil
+		}
+		return AndConditions{Exprs: exprs}, nil
+	case spec.Not != nil:
+		expr, err := FromFilter(*spec.Not, allowed)
+		if err != nil {
+			return nil, err
+		}
+		return NotConditions{Exprs: []Expression{expr}}, nil
+	}
+
+	column, ok := allowed[spec.Field]
+	if !ok || column == "" {
+		return nil, fmt.Errorf("unknown filter field %q", spec.Field)
+	}
+
+	op, ok := FilterOps[spec.Op]
+	if !ok {
+		return nil, fmt.Errorf("unknown filter operator %q", spec.Op)
+	}
+
+	return op(Column{Name: column}, spec.Value)
+}
+
+func filterValues(value interface{}) ([]interface{}, error) {
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() != reflect.Slice && reflectValue.Kind() != reflect.Array {
+		return nil, fmt.Errorf("invalid filter value %v, expects a list", value)
+	}
+
+	values := make([]interface{}, reflectValue.Len())
+	for i := 0; i < reflectValue.Len(); i++ {
+		values[i] = reflectValue.Index(i).Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,84 @@
+package clause_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestFromFilter(t *testing.T) {
+	allowed := map[string]string{"age": "age", "name": "users.name", "role": "role"}
+
+	results := []struct {
+		Filter string
+		Result string
+		Vars   []interface{}
+	}{
+		{
+			`{"field":"age","op":"gt","value":18}`,
+			"SELECT * FROM `users` WHERE `age` > ?",
+			[]interface{}{float64(18)},
+		},
+		{
+			`{"and":[{"field":"age","op":"gte","value":18},{"or":[{"field":"name","op":"like","value":"%jinzhu%"},{"field":"role","op":"in","value":["admin","owner"]}]}]}`,
+			"SELECT * FROM `users` WHERE `age` >= ? AND (`users`.`name` LIKE ? OR `role` IN (?,?))",
+			[]interface{}{float64(18), "%jinzhu%", "admin", "owner"},
+		},
+		{
+			`{"or":[{"field":"age","op":"lt","value":18}]}`,
+			"SELECT * FROM `users` WHERE `age` < ?",
+			[]interface{}{float64(18)},
+		},
+		{
+			`{"not":{"and":[{"field":"age","op":"lte","value":18},{"field":"role","op":"neq","value":"admin"}]}}`,
+			"SELECT * FROM `users` WHERE NOT (`age` <= ? AND `role` <> ?)",
+			[]interface{}{float64(18), "admin"},
+		},
+		{
+			`{"and":[{"field":"role","op":"nin","value":["admin","owner"]},{"field":"name","op":"eq","value":null}]}`,
+			"SELECT * FROM `users` WHERE `role` NOT IN (?,?) AND `users`.`name` IS NULL",
+			[]interface{}{"admin", "owner"},
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			var spec clause.FilterNode
+			if err := json.Unmarshal([]byte(result.Filter), &spec); err != nil {
+				t.Fatalf("failed to decode filter, got error %v", err)
+			}
+
+			expr, err := clause.FromFilter(spec, allowed)
+			if err != nil {
+				t.Fatalf("failed to convert filter, got error %v", err)
+			}
+
+			checkBuildClauses(t, []clause.Interface{clause.Select{}, clause.From{}, clause.Where{Exprs: []clause.Expression{expr}}}, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestFromFilterWithInvalidSpec(t *testing.T) {
+	allowed := map[string]string{"age": "age"}
+
+	for _, filter := range []string{
+		`{"field":"password","op":"eq","value":"x"}`,
+		`{"field":"age","op":"; DROP TABLE users","value":1}`,
+		`{"field":"age","op":"in","value":18}`,
+		`{"and":[]}`,
+		`{}`,
+		`{"field":"age","op":"eq","value":18,"or":[{"field":"age","op":"eq","value":20}]}`,
+		`{"not":{"field":"age` + "`" + `","op":"eq","value":18}}`,
+	} {
+		var spec clause.FilterNode
+		if err := json.Unmarshal([]byte(filter), &spec); err != nil {
+			t.Fatalf("failed to decode filter %v, got error %v", filter, err)
+		}
+
+		if _, err := clause.FromFilter(spec, allowed); err == nil {
+			t.Errorf("should return error for filter %v", filter)
+		}
+	}
+}
@@ -1,9 +1,16 @@
 package clause
 
-// From from clause
+// FromOnlyDialects dialects support `FROM ONLY table` to exclude inherited tables, Only is ignored on other dialects
+var FromOnlyDialects = map[string]bool{"postgres": true}
+
+// From from clause, multiple tables are rendered comma-separated as an implicit cross join, e.g: FROM `a`,`b`
 type From struct {
 	Tables []Table
 	Joins  []Join
+	// Only renders `FROM ONLY table` to query the table without its child tables, e.g: Postgres table inheritance
+	Only bool
+	// Sample samples rows of the first table, e.g: TABLESAMPLE BERNOULLI (10)
+	Sample *TableSample
 }
 
 // Name from clause name
@@ -13,16 +20,39 @@ func (from From) Name() string {
 
 // Build build from clause
 func (from From) Build(builder Builder) {
+	only := false
+	if from.Only {
+		if namer, ok := builder.(dialectNamer); ok {
+			only = FromOnlyDialects[namer.DialectName()]
+		}
+	}
+
 	if len(from.Tables) > 0 {
 		for idx, table := range from.Tables {
 			if idx > 0 {
 				builder.WriteByte(',')
 			}
 
+			if only {
+				builder.WriteString("ONLY ")
+			}
 			builder.WriteQuoted(table)
+
+			if idx == 0 && from.Sample != nil {
+				builder.WriteByte(' ')
+				from.Sample.Build(builder)
+			}
 		}
 	} else {
+		if only {
+			builder.WriteString("ONLY ")
+		}
 		builder.WriteQuoted(currentTable)
+
+		if from.Sample != nil {
+			builder.WriteByte(' ')
+			from.Sample.Build(builder)
+		}
 	}
 
 	for _, join := range from.Joins {
@@ -8,6 +8,7 @@ import (
 )
 
 func TestFrom(t *testing.T) {
+	seed := int64(42)
 	results := []struct {
 		Clauses []clause.Interface
 		Result  string
@@ -65,6 +66,10 @@ func TestFrom(t *testing.T) {
 			},
 			"SELECT * FROM `users` INNER JOIN `articles` ON `articles`.`id` = `users`.`id` LEFT JOIN `companies` USING (`company_name`)", nil,
 		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{Sample: &clause.TableSample{Method: "BERNOULLI", Percent: 10, Seed: &seed}}},
+			"SELECT * FROM `users` TABLESAMPLE BERNOULLI (?) REPEATABLE (?)", []interface{}{float64(10), int64(42)},
+		},
 	}
 
 	for idx, result := range results {
@@ -35,7 +35,7 @@ func (jt JoinTarget) As(name string) JoinTarget {
 	return jt
 }
 
-// Join clause for from
+// Join clause for from, Expression replaces the whole join if set, except a TableFunction which replaces the Table
 type Join struct {
 	Type       JoinType
 	Table      Table
@@ -51,7 +51,8 @@ func JoinTable(names ...string) Table {
 }
 
 func (join Join) Build(builder Builder) {
-	if join.Expression != nil {
+	fn, isTableFunction := join.Expression.(TableFunction)
+	if join.Expression != nil && !isTableFunction {
 		join.Expression.Build(builder)
 	} else {
 		if join.Type != "" {
@@ -60,7 +61,11 @@ func (join Join) Build(builder Builder) {
 		}
 
 		builder.WriteString("JOIN ")
-		builder.WriteQuoted(join.Table)
+		if isTableFunction {
+			fn.Build(builder)
+		} else {
+			builder.WriteQuoted(join.Table)
+		}
 
 		if len(join.ON.Exprs) > 0 {
 			builder.WriteString(" ON ")
@@ -87,6 +87,25 @@ func TestJoin(t *testing.T) {
 			},
 			sql: "INNER JOIN `user` USING (`id`)",
 		},
+		{
+			name: "TableFunction",
+			join: clause.Join{
+				Type: clause.CrossJoin,
+				Expression: clause.TableFunction{
+					Name: "generate_series", Args: []interface{}{1, clause.Column{Table: "users", Name: "age"}}, Alias: "s", Lateral: true,
+				},
+			},
+			sql: "CROSS JOIN LATERAL generate_series(?,`users`.`age`) AS `s`",
+		},
+		{
+			name: "TableFunction ON",
+			join: clause.Join{
+				Type:       clause.LeftJoin,
+				Expression: clause.TableFunction{Name: "json_each", Args: []interface{}{clause.Column{Table: "users", Name: "attrs"}}, Alias: "attrs"},
+				ON:         clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "true"}}},
+			},
+			sql: "LEFT JOIN json_each(`users`.`attrs`) AS `attrs` ON true",
+		},
 	}
 	for _, result := range results {
 		t.Run(result.name, func(t *testing.T) {
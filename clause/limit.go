@@ -1,9 +1,17 @@
 package clause
 
+import "errors"
+
+// WithTiesDialects dialects support `FETCH FIRST ? ROWS WITH TIES`, building Limit with WithTies for other dialects fails
+var WithTiesDialects = map[string]bool{"postgres": true, "oracle": true}
+
 // Limit limit clause
 type Limit struct {
 	Limit  *int
 	Offset int
+	// WithTies build as `FETCH FIRST ? ROWS WITH TIES` to include rows tied with the last one,
+	// it's only supported by WithTiesDialects
+	WithTies bool
 }
 
 // Name where clause name
@@ -13,6 +21,22 @@ func (limit Limit) Name() string {
 
 // Build build where clause
 func (limit Limit) Build(builder Builder) {
+	if limit.WithTies && limit.Limit != nil && *limit.Limit >= 0 {
+		if namer, ok := builder.(dialectNamer); ok && !WithTiesDialects[namer.DialectName()] {
+			builder.AddError(errors.New("FETCH FIRST WITH TIES isn't supported by " + namer.DialectName()))
+		}
+
+		if limit.Offset > 0 {
+			builder.WriteString("OFFSET ")
+			builder.AddVar(builder, limit.Offset)
+			builder.WriteString(" ROWS ")
+		}
+		builder.WriteString("FETCH FIRST ")
+		builder.AddVar(builder, *limit.Limit)
+		builder.WriteString(" ROWS WITH TIES")
+		return
+	}
+
 	if limit.Limit != nil && *limit.Limit >= 0 {
 		builder.WriteString("LIMIT ")
 		builder.AddVar(builder, *limit.Limit)
@@ -40,6 +64,8 @@ func (limit Limit) MergeClause(clause *Clause) {
 		} else if limit.Offset < 0 {
 			limit.Offset = 0
 		}
+
+		limit.WithTies = limit.WithTies || v.WithTies
 	}
 
 	clause.Expression = limit
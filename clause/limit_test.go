@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -78,3 +79,49 @@ func TestLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitWithTies(t *testing.T) {
+	clause.WithTiesDialects["dummy"] = true
+	defer delete(clause.WithTiesDialects, "dummy")
+
+	limit10 := 10
+	results := []struct {
+		Clauses []clause.Interface
+		Result  string
+		Vars    []interface{}
+	}{
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Limit{Limit: &limit10, WithTies: true}},
+			"SELECT * FROM `users` FETCH FIRST ? ROWS WITH TIES",
+			[]interface{}{limit10},
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Limit{Limit: &limit10, WithTies: true}, clause.Limit{Offset: 20}},
+			"SELECT * FROM `users` OFFSET ? ROWS FETCH FIRST ? ROWS WITH TIES",
+			[]interface{}{20, limit10},
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Limit{Offset: 20, WithTies: true}},
+			"SELECT * FROM `users` OFFSET ?",
+			[]interface{}{20},
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			checkBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestLimitWithTiesUnsupported(t *testing.T) {
+	limit10 := 10
+	tx := db.Session(&gorm.Session{NewDB: true})
+	stmt := gorm.Statement{DB: tx, Table: "users", Clauses: map[string]clause.Clause{}}
+	stmt.AddClause(clause.Limit{Limit: &limit10, WithTies: true})
+	stmt.Build("LIMIT")
+
+	if tx.Error == nil {
+		t.Errorf("should return error for dialects don't support FETCH FIRST WITH TIES")
+	}
+}
@@ -10,6 +10,7 @@ const (
 type Locking struct {
 	Strength string
 	Table    Table
+	Tables   []Table
 	Options  string
 }
 
@@ -18,12 +19,22 @@ func (locking Locking) Name() string {
 	return "FOR"
 }
 
-// Build build where clause
+// Build build where clause, Table and Tables are combined as the OF targets, e.g: FOR UPDATE OF `orders`,`items`
 func (locking Locking) Build(builder Builder) {
 	builder.WriteString(locking.Strength)
+
+	tables := locking.Tables
 	if locking.Table.Name != "" {
-		builder.WriteString(" OF ")
-		builder.WriteQuoted(locking.Table)
+		tables = append([]Table{locking.Table}, tables...)
+	}
+
+	for idx, table := range tables {
+		if idx == 0 {
+			builder.WriteString(" OF ")
+		} else {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(table)
 	}
 
 	if locking.Options != "" {
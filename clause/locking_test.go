@@ -29,6 +29,18 @@ func TestLocking(t *testing.T) {
 			[]clause.Interface{clause.Select{}, clause.From{}, clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}},
 			"SELECT * FROM `users` FOR UPDATE SKIP LOCKED", nil,
 		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Locking{Strength: clause.LockingStrengthUpdate, Tables: []clause.Table{{Name: "orders"}}, Options: clause.LockingOptionsSkipLocked}},
+			"SELECT * FROM `users` FOR UPDATE OF `orders` SKIP LOCKED", nil,
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Locking{Strength: clause.LockingStrengthUpdate, Tables: []clause.Table{{Name: "orders"}, {Name: "items"}}, Options: clause.LockingOptionsNoWait}},
+			"SELECT * FROM `users` FOR UPDATE OF `orders`,`items` NOWAIT", nil,
+		},
+		{
+			[]clause.Interface{clause.Select{}, clause.From{}, clause.Locking{Strength: clause.LockingStrengthShare, Table: clause.Table{Name: clause.CurrentTable}, Tables: []clause.Table{{Name: "orders"}}}},
+			"SELECT * FROM `users` FOR SHARE OF `users`,`orders`", nil,
+		},
 	}
 
 	for idx, result := range results {
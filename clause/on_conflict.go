@@ -1,13 +1,34 @@
 package clause
 
+import "strings"
+
+// TargetTable is the qualifier referencing the existing row in DoUpdates expressions, it is replaced with the current table, e.g:
+//
+//	clause.Assignments(map[string]interface{}{"count": gorm.Expr("target.count + excluded.count")})
+//	// ON CONFLICT ... DO UPDATE SET `count`=`users`.count + excluded.count
+//
+// MySQL `ON DUPLICATE KEY UPDATE` doesn't support `excluded`, the equivalent is gorm.Expr("count + VALUES(count)")
+const TargetTable = "target"
+
 type OnConflict struct {
-	Columns      []Column
+	Columns []Column
+	// TargetExprs conflict target expressions of expression indexes, following Columns, identifiers in vars are quoted, e.g:
+	//   clause.Expr{SQL: "lower(?)", Vars: []interface{}{clause.Column{Name: "email"}}} // ON CONFLICT (lower("email"))
+	// it's ignored by MySQL `ON DUPLICATE KEY UPDATE` which doesn't take a conflict target
+	TargetExprs []Expression
+	// Where only updates the conflicting row if the predicate is true, the `target.` qualifier is replaced too, e.g:
+	//   ON CONFLICT (`id`) DO UPDATE SET `name`=`excluded`.`name` WHERE excluded.updated_at > target.updated_at
+	// MySQL `ON DUPLICATE KEY UPDATE` guards each assignment with the predicate instead, e.g:
+	//   `name`=IF(VALUES(updated_at) > updated_at,VALUES(`name`),`name`)
 	Where        Where
 	TargetWhere  Where
 	OnConstraint string
 	DoNothing    bool
 	DoUpdates    Set
 	UpdateAll    bool
+	// ReturningOld scans the rows before upserting into the pointer to a struct or slice, the upserted rows are
+	// scanned into the created values, rows inserted without conflicts are scanned as zero values, Postgres only
+	ReturningOld interface{}
 }
 
 func (OnConflict) Name() string {
@@ -21,7 +42,7 @@ func (onConflict OnConflict) Build(builder Builder) {
 		builder.WriteString(onConflict.OnConstraint)
 		builder.WriteByte(' ')
 	} else {
-		if len(onConflict.Columns) > 0 {
+		if len(onConflict.Columns) > 0 || len(onConflict.TargetExprs) > 0 {
 			builder.WriteByte('(')
 			for idx, column := range onConflict.Columns {
 				if idx > 0 {
@@ -29,6 +50,12 @@ func (onConflict OnConflict) Build(builder Builder) {
 				}
 				builder.WriteQuoted(column)
 			}
+			for idx, expr := range onConflict.TargetExprs {
+				if idx > 0 || len(onConflict.Columns) > 0 {
+					builder.WriteByte(',')
+				}
+				expr.Build(builder)
+			}
 			builder.WriteString(`) `)
 		}
 
@@ -39,16 +66,34 @@ func (onConflict OnConflict) Build(builder Builder) {
 		}
 	}
 
+	where := onConflict.Where
 	if onConflict.DoNothing {
 		builder.WriteString("DO NOTHING")
 	} else {
 		builder.WriteString("DO UPDATE SET ")
-		onConflict.DoUpdates.Build(builder)
+		doUpdates := make(Set, len(onConflict.DoUpdates))
+		for idx, assignment := range onConflict.DoUpdates {
+			if expr, ok := assignment.Value.(Expr); ok {
+				assignment.Value = qualifyTargetTable(expr)
+			}
+			doUpdates[idx] = assignment
+		}
+		doUpdates.Build(builder)
+
+		if len(where.Exprs) > 0 {
+			where.Exprs = make([]Expression, len(onConflict.Where.Exprs))
+			for idx, expr := range onConflict.Where.Exprs {
+				if e, ok := expr.(Expr); ok {
+					expr = qualifyTargetTable(e)
+				}
+				where.Exprs[idx] = expr
+			}
+		}
 	}
 
-	if len(onConflict.Where.Exprs) > 0 {
+	if len(where.Exprs) > 0 {
 		builder.WriteString(" WHERE ")
-		onConflict.Where.Build(builder)
+		where.Build(builder)
 		builder.WriteByte(' ')
 	}
 }
@@ -57,3 +102,48 @@ func (onConflict OnConflict) Build(builder Builder) {
 func (onConflict OnConflict) MergeClause(clause *Clause) {
 	clause.Expression = onConflict
 }
+
+// qualifyTargetTable replaces the `target.` qualifier outside of quotes with the current table
+func qualifyTargetTable(expr Expr) Expr {
+	if !strings.Contains(expr.SQL, TargetTable+".") {
+		return expr
+	}
+
+	var (
+		sql     strings.Builder
+		vars    = make([]interface{}, 0, len(expr.Vars)+1)
+		varIdx  = 0
+		quote   byte
+		prefix  = TargetTable + "."
+		isIdent = func(c byte) bool {
+			return c == '_' || c == '.' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		}
+	)
+
+	for i := 0; i < len(expr.SQL); i++ {
+		c := expr.SQL[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			if varIdx < len(expr.Vars) {
+				vars = append(vars, expr.Vars[varIdx])
+				varIdx++
+			}
+		case strings.HasPrefix(expr.SQL[i:], prefix) && (i == 0 || !isIdent(expr.SQL[i-1])):
+			sql.WriteString("?.")
+			vars = append(vars, Table{Name: CurrentTable})
+			i += len(prefix) - 1
+			continue
+		}
+		sql.WriteByte(c)
+	}
+
+	expr.SQL = sql.String()
+	expr.Vars = append(vars, expr.Vars[varIdx:]...)
+	return expr
+}
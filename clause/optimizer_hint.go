@@ -0,0 +1,32 @@
+package clause
+
+import (
+	"errors"
+	"strings"
+)
+
+// OptimizerHintDialects dialects accept `/*+ ... */` optimizer hints right after the statement keyword,
+// hints are ignored for dialects not registered, e.g: register "oracle" to enable it
+var OptimizerHintDialects = map[string]bool{"mysql": true}
+
+// OptimizerHint optimizer hints rendered right after the SELECT/INSERT/UPDATE/DELETE keyword, e.g:
+//
+//	db.Clauses(clause.OptimizerHint{Hints: []string{"MAX_EXECUTION_TIME(1000)"}}).Find(&users)
+//	// SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `users`
+type OptimizerHint struct {
+	Hints []string
+}
+
+// Build build optimizer hint
+func (hint OptimizerHint) Build(builder Builder) {
+	for _, h := range hint.Hints {
+		if strings.Contains(h, "*/") {
+			builder.AddError(errors.New("invalid optimizer hint: " + h))
+			return
+		}
+	}
+
+	builder.WriteString("/*+ ")
+	builder.WriteString(strings.Join(hint.Hints, " "))
+	builder.WriteString(" */")
+}
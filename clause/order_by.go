@@ -1,9 +1,34 @@
 package clause
 
+import (
+	"regexp"
+	"strings"
+)
+
+// NullsOrder the position of NULL values when sorting
+type NullsOrder int
+
+const (
+	// NullsDefault keeps the default of the database, or Config.DefaultNullsOrder for the column of OrderBy
+	NullsDefault NullsOrder = iota
+	// NullsFirst sorts NULL values before non-NULL values
+	NullsFirst
+	// NullsLast sorts NULL values after non-NULL values
+	NullsLast
+)
+
+// NullsOrderDialects dialects support `NULLS FIRST`/`NULLS LAST`, it's emulated by sorting with `CASE WHEN column IS NULL`
+// first for others, which may prevent the database from sorting with an index
+var NullsOrderDialects = map[string]bool{"postgres": true, "sqlite": true}
+
+// rawOrderRegexp matches a raw order of a single column with an optional direction, e.g: `name desc`
+var rawOrderRegexp = regexp.MustCompile(`(?i)^\s*([\w."` + "`" + `]+)(\s+(?:asc|desc))?\s*$`)
+
 type OrderByColumn struct {
 	Column  Column
 	Desc    bool
 	Reorder bool
+	Nulls   NullsOrder
 }
 
 type OrderBy struct {
@@ -26,12 +51,68 @@ func (orderBy OrderBy) Build(builder Builder) {
 				builder.WriteByte(',')
 			}
 
+			// NULLs order is only supported by raw columns of a single column
+			nulls, native := column.Nulls, true
+			if column.Column.Raw && !rawOrderRegexp.MatchString(column.Column.Name) {
+				nulls = NullsDefault
+			}
+			if namer, ok := builder.(dialectNamer); ok {
+				native = NullsOrderDialects[namer.DialectName()]
+			}
+
+			if nulls != NullsDefault && !native {
+				writeNullsOrderCase(builder, column)
+			}
+
 			builder.WriteQuoted(column.Column)
 			if column.Desc {
 				builder.WriteString(" DESC")
 			}
+
+			if nulls != NullsDefault && native {
+				if nulls == NullsFirst {
+					builder.WriteString(" NULLS FIRST")
+				} else {
+					builder.WriteString(" NULLS LAST")
+				}
+			}
+		}
+	}
+}
+
+// writeNullsOrderCase writes `CASE WHEN column IS NULL THEN 0 ELSE 1 END,` to emulate `NULLS FIRST`/`NULLS LAST`
+func writeNullsOrderCase(builder Builder, column OrderByColumn) {
+	builder.WriteString("CASE WHEN ")
+	if column.Column.Raw {
+		builder.WriteString(rawOrderRegexp.FindStringSubmatch(column.Column.Name)[1])
+	} else {
+		builder.WriteQuoted(column.Column)
+	}
+
+	if column.Nulls == NullsFirst {
+		builder.WriteString(" IS NULL THEN 0 ELSE 1 END,")
+	} else {
+		builder.WriteString(" IS NULL THEN 1 ELSE 0 END,")
+	}
+}
+
+// WithDefaultNulls returns the order by with the NULLs order of columns don't specify their own set to nulls,
+// raw columns are only set if they're a single column with an optional direction and without NULLS
+func (orderBy OrderBy) WithDefaultNulls(nulls NullsOrder) OrderBy {
+	if nulls == NullsDefault || orderBy.Expression != nil {
+		return orderBy
+	}
+
+	columns := make([]OrderByColumn, len(orderBy.Columns))
+	for idx, column := range orderBy.Columns {
+		if column.Nulls == NullsDefault && (!column.Column.Raw ||
+			(rawOrderRegexp.MatchString(column.Column.Name) && !strings.Contains(strings.ToUpper(column.Column.Name), "NULLS"))) {
+			column.Nulls = nulls
 		}
+		columns[idx] = column
 	}
+	orderBy.Columns = columns
+	return orderBy
 }
 
 // MergeClause merge order by clauses
@@ -48,6 +48,56 @@ func TestOrderBy(t *testing.T) {
 			"SELECT * FROM `users` ORDER BY FIELD(id, ?,?,?)",
 			[]interface{}{1, 2, 3},
 		},
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "age"}, Desc: true, Nulls: clause.NullsFirst}, {Column: clause.Column{Name: "name"}, Nulls: clause.NullsLast}},
+				},
+			},
+			"SELECT * FROM `users` ORDER BY CASE WHEN `age` IS NULL THEN 0 ELSE 1 END,`age` DESC,CASE WHEN `name` IS NULL THEN 1 ELSE 0 END,`name`", nil,
+		},
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "age desc", Raw: true}, Nulls: clause.NullsLast}, {Column: clause.Column{Name: "a, b", Raw: true}, Nulls: clause.NullsLast}},
+				},
+			},
+			"SELECT * FROM `users` ORDER BY CASE WHEN age IS NULL THEN 1 ELSE 0 END,age desc,a, b", nil,
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			checkBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestOrderByNullsNative(t *testing.T) {
+	clause.NullsOrderDialects["dummy"] = true
+	defer delete(clause.NullsOrderDialects, "dummy")
+
+	results := []struct {
+		Clauses []clause.Interface
+		Result  string
+		Vars    []interface{}
+	}{
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "age"}, Desc: true, Nulls: clause.NullsFirst}, {Column: clause.Column{Name: "name desc", Raw: true}, Nulls: clause.NullsLast}},
+				},
+			},
+			"SELECT * FROM `users` ORDER BY `age` DESC NULLS FIRST,name desc NULLS LAST", nil,
+		},
+		{
+			[]clause.Interface{
+				clause.Select{}, clause.From{}, clause.OrderBy{
+					Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "age"}}, {Column: clause.Column{Name: "name"}, Nulls: clause.NullsFirst}, {Column: clause.Column{Name: "a nulls first", Raw: true}}},
+				}.WithDefaultNulls(clause.NullsLast),
+			},
+			"SELECT * FROM `users` ORDER BY `age` NULLS LAST,`name` NULLS FIRST,a nulls first", nil,
+		},
 	}
 
 	for idx, result := range results {
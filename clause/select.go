@@ -1,8 +1,14 @@
 package clause
 
+import "errors"
+
+// DistinctOnDialects dialects support `DISTINCT ON (columns)`, building it for other dialects fails
+var DistinctOnDialects = map[string]bool{"postgres": true}
+
 // Select select attrs when querying, updating, creating
 type Select struct {
 	Distinct   bool     // 使用使用 distinct 模式
+	DistinctOn []Column // 使用 DISTINCT ON (columns) 模式，如 Postgres
 	Columns    []Column // 是否 select 查询指定的列，如 select id,name
 	Expression Expression
 }
@@ -12,9 +18,27 @@ func (s Select) Name() string {
 }
 
 func (s Select) Build(builder Builder) {
+	// DISTINCT ON 优先于 DISTINCT
+	if len(s.DistinctOn) > 0 {
+		if namer, ok := builder.(dialectNamer); ok && !DistinctOnDialects[namer.DialectName()] {
+			builder.AddError(errors.New("DISTINCT ON isn't supported by " + namer.DialectName()))
+		}
+
+		builder.WriteString("DISTINCT ON (")
+		for idx, column := range s.DistinctOn {
+			if idx > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteQuoted(column)
+		}
+		builder.WriteString(") ")
+	}
+
 	// select  查询指定的列
-	if len(s.Columns) > 0 {
-		if s.Distinct {
+	if s.Expression != nil {
+		s.Expression.Build(builder)
+	} else if len(s.Columns) > 0 {
+		if s.Distinct && len(s.DistinctOn) == 0 {
 			builder.WriteString("DISTINCT ")
 		}
 
@@ -32,7 +56,14 @@ func (s Select) Build(builder Builder) {
 }
 
 func (s Select) MergeClause(clause *Clause) {
-	if s.Expression != nil {
+	// keep DISTINCT ON of the previous select, e.g: Clauses(Select{DistinctOn: ...}) then Clauses(Select{Columns: ...})
+	if v, ok := clause.Expression.(Select); ok && len(s.DistinctOn) == 0 {
+		s.DistinctOn = v.DistinctOn
+	}
+
+	if s.Expression != nil && len(s.DistinctOn) > 0 {
+		clause.Expression = s
+	} else if s.Expression != nil {
 		if s.Distinct {
 			if expr, ok := s.Expression.(Expr); ok {
 				expr.SQL = "DISTINCT " + expr.SQL
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -70,3 +71,62 @@ func TestSelect(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectDistinctOn(t *testing.T) {
+	clause.DistinctOnDialects["dummy"] = true
+	defer delete(clause.DistinctOnDialects, "dummy")
+
+	results := []struct {
+		Clauses []clause.Interface
+		Result  string
+		Vars    []interface{}
+	}{
+		{
+			[]clause.Interface{clause.Select{
+				DistinctOn: []clause.Column{{Name: "user_id"}},
+			}, clause.From{}},
+			"SELECT DISTINCT ON (`user_id`) * FROM `users`", nil,
+		},
+		{
+			[]clause.Interface{clause.Select{
+				Distinct:   true,
+				DistinctOn: []clause.Column{{Name: "user_id"}, {Name: "name"}},
+				Columns:    []clause.Column{{Name: "user_id"}, {Name: "name"}, {Name: "age"}},
+			}, clause.From{}},
+			"SELECT DISTINCT ON (`user_id`,`name`) `user_id`,`name`,`age` FROM `users`", nil,
+		},
+		{
+			[]clause.Interface{clause.Select{
+				DistinctOn: []clause.Column{{Name: "user_id"}},
+			}, clause.Select{
+				Distinct: true,
+				Columns:  []clause.Column{{Name: "user_id"}, {Name: "name"}},
+			}, clause.From{}},
+			"SELECT DISTINCT ON (`user_id`) `user_id`,`name` FROM `users`", nil,
+		},
+		{
+			[]clause.Interface{clause.Select{
+				DistinctOn: []clause.Column{{Name: "user_id"}},
+				Expression: clause.Expr{SQL: "user_id, max(age)"},
+			}, clause.From{}},
+			"SELECT DISTINCT ON (`user_id`) user_id, max(age) FROM `users`", nil,
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			checkBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
+
+func TestSelectDistinctOnUnsupported(t *testing.T) {
+	tx := db.Session(&gorm.Session{NewDB: true})
+	stmt := gorm.Statement{DB: tx, Table: "users", Clauses: map[string]clause.Clause{}}
+	stmt.AddClause(clause.Select{DistinctOn: []clause.Column{{Name: "user_id"}}})
+	stmt.Build("SELECT")
+
+	if tx.Error == nil {
+		t.Errorf("should return error for dialects don't support DISTINCT ON")
+	}
+}
@@ -0,0 +1,47 @@
+package clause
+
+import (
+	"errors"
+	"strings"
+)
+
+// TableFunction set-returning function used as a table source, e.g:
+//
+//	// CROSS JOIN LATERAL generate_series(?,?,?) AS `bucket`
+//	clause.Join{Type: clause.CrossJoin, Expression: clause.TableFunction{
+//		Name: "generate_series", Args: []interface{}{start, end, clause.Expr{SQL: "interval '1 day'"}}, Alias: "bucket", Lateral: true,
+//	}}
+//
+// the args are bound as vars, expressions like clause.Column or clause.Expr are built inline
+type TableFunction struct {
+	Name    string
+	Args    []interface{}
+	Alias   string
+	Lateral bool
+}
+
+// Build build table function
+func (fn TableFunction) Build(builder Builder) {
+	if fn.Name == "" || strings.IndexFunc(fn.Name, func(r rune) bool {
+		return !(r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) >= 0 {
+		builder.AddError(errors.New("invalid table function name: " + fn.Name))
+		return
+	}
+
+	if fn.Lateral {
+		builder.WriteString("LATERAL ")
+	}
+
+	builder.WriteString(fn.Name)
+	builder.WriteByte('(')
+	if len(fn.Args) > 0 {
+		builder.AddVar(builder, fn.Args...)
+	}
+	builder.WriteByte(')')
+
+	if fn.Alias != "" {
+		builder.WriteString(" AS ")
+		builder.WriteQuoted(fn.Alias)
+	}
+}
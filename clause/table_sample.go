@@ -0,0 +1,41 @@
+package clause
+
+import (
+	"errors"
+	"strings"
+)
+
+// TableSample samples rows of the queried table, rendered after the table in the FROM clause, e.g:
+//
+//	seed := int64(42)
+//	db.Clauses(clause.TableSample{Method: "BERNOULLI", Percent: 10, Seed: &seed}).Find(&users)
+//	// SELECT * FROM "users" TABLESAMPLE BERNOULLI (10) REPEATABLE (42)
+//
+// Seed renders `REPEATABLE (seed)` to return the same sample across queries as long as the table isn't changed
+type TableSample struct {
+	Method  string
+	Percent float64
+	Seed    *int64
+}
+
+// Build build table sample
+func (sample TableSample) Build(builder Builder) {
+	if sample.Method == "" || strings.IndexFunc(sample.Method, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) >= 0 {
+		builder.AddError(errors.New("invalid table sample method: " + sample.Method))
+		return
+	}
+
+	builder.WriteString("TABLESAMPLE ")
+	builder.WriteString(sample.Method)
+	builder.WriteString(" (")
+	builder.AddVar(builder, sample.Percent)
+	builder.WriteByte(')')
+
+	if sample.Seed != nil {
+		builder.WriteString(" REPEATABLE (")
+		builder.AddVar(builder, *sample.Seed)
+		builder.WriteByte(')')
+	}
+}
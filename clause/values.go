@@ -34,6 +34,8 @@ func (values Values) Build(builder Builder) {
 			builder.WriteByte(')')
 		}
 	} else {
+		// the row relies entirely on database defaults, dialects don't support it could customize the VALUES clause builder,
+		// e.g: MySQL `INSERT INTO t VALUES()`
 		builder.WriteString("DEFAULT VALUES")
 	}
 }
@@ -43,3 +45,37 @@ func (values Values) MergeClause(clause *Clause) {
 	clause.Name = ""
 	clause.Expression = values
 }
+
+// ValuesTable derived table of row values, e.g: (VALUES (?,?),(?,?)) AS `t` (`id`,`name`)
+type ValuesTable struct {
+	Alias   string
+	Columns []Column
+	Values  [][]interface{}
+}
+
+// Build build derived values table
+func (values ValuesTable) Build(builder Builder) {
+	builder.WriteString("(VALUES ")
+	for idx, value := range values.Values {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+
+		builder.WriteByte('(')
+		builder.AddVar(builder, value...)
+		builder.WriteByte(')')
+	}
+	builder.WriteString(") AS ")
+	builder.WriteQuoted(values.Alias)
+
+	if len(values.Columns) > 0 {
+		builder.WriteString(" (")
+		for idx, column := range values.Columns {
+			if idx > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteQuoted(column)
+		}
+		builder.WriteByte(')')
+	}
+}
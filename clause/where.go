@@ -74,6 +74,9 @@ func buildExprs(exprs []Expression, builder Builder, joinCond string) {
 			case NamedExpr:
 				sql := strings.ToUpper(v.SQL)
 				wrapInParentheses = strings.Contains(sql, AndWithSpace) || strings.Contains(sql, OrWithSpace)
+			case StrictNamedExpr:
+				sql := strings.ToUpper(v.SQL)
+				wrapInParentheses = strings.Contains(sql, AndWithSpace) || strings.Contains(sql, OrWithSpace)
 			}
 		}
 
@@ -1,3 +1,42 @@
 package clause
 
-type With struct{}
+// With common table expressions of the statement, it's built before the statement, e.g:
+//
+//	WITH `ins` AS (INSERT INTO `users` ... RETURNING `id`) INSERT INTO `pets` ...
+type With struct {
+	CTEs []CTE
+}
+
+// CTE common table expression, the expression is wrapped in parentheses
+type CTE struct {
+	Name       string
+	Expression Expression
+}
+
+// Name with clause name
+func (with With) Name() string {
+	return "WITH"
+}
+
+// Build build with clause
+func (with With) Build(builder Builder) {
+	for idx, cte := range with.CTEs {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+
+		builder.WriteQuoted(cte.Name)
+		builder.WriteString(" AS (")
+		cte.Expression.Build(builder)
+		builder.WriteByte(')')
+	}
+}
+
+// MergeClause merge with clauses
+func (with With) MergeClause(clause *Clause) {
+	if v, ok := clause.Expression.(With); ok {
+		with.CTEs = append(v.CTEs[:len(v.CTEs):len(v.CTEs)], with.CTEs...)
+	}
+
+	clause.Expression = with
+}
@@ -0,0 +1,37 @@
+package clause_test
+
+import (
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestWith(t *testing.T) {
+	results := []struct {
+		Clauses []clause.Interface
+		Result  string
+		Vars    []interface{}
+	}{
+		{
+			[]clause.Interface{clause.With{CTEs: []clause.CTE{{Name: "ins", Expression: clause.Expr{SQL: "INSERT INTO `users` (`name`) VALUES (?) RETURNING `id`", Vars: []interface{}{"jinzhu"}}}}}, clause.Select{}, clause.From{}},
+			"WITH `ins` AS (INSERT INTO `users` (`name`) VALUES (?) RETURNING `id`) SELECT * FROM `users`",
+			[]interface{}{"jinzhu"},
+		},
+		{
+			[]clause.Interface{
+				clause.With{CTEs: []clause.CTE{{Name: "a", Expression: clause.Expr{SQL: "SELECT ?", Vars: []interface{}{1}}}}},
+				clause.With{CTEs: []clause.CTE{{Name: "b", Expression: clause.Expr{SQL: "SELECT ?", Vars: []interface{}{2}}}}},
+				clause.Select{}, clause.From{},
+			},
+			"WITH `a` AS (SELECT ?),`b` AS (SELECT ?) SELECT * FROM `users`",
+			[]interface{}{1, 2},
+		},
+	}
+
+	for idx, result := range results {
+		t.Run(fmt.Sprintf("case #%v", idx), func(t *testing.T) {
+			checkBuildClauses(t, result.Clauses, result.Result, result.Vars)
+		})
+	}
+}
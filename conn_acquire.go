@@ -0,0 +1,172 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// connAcquirePool limits the duration of acquiring connections from the *sql.DB with Config.ConnAcquireTimeout,
+// statements are executed on the acquired connection with the original context
+type connAcquirePool struct {
+	*sql.DB
+	timeout time.Duration
+
+	mu     sync.Mutex
+	pinned map[*sql.Rows]*sql.Conn
+}
+
+// acquireErrContext is done with the error of acquiring a connection, *sql.DB returns the error without using a connection
+type acquireErrContext struct {
+	context.Context
+	err error
+}
+
+var closedDone = func() chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}()
+
+func (ctx acquireErrContext) Done() <-chan struct{} { return closedDone }
+func (ctx acquireErrContext) Err() error            { return ctx.err }
+
+// Conn acquires a connection, returns an error wrapping ErrConnAcquireTimeout if no connection is available in time
+func (pool *connAcquirePool) Conn(ctx context.Context) (*sql.Conn, error) {
+	pool.releaseClosedRows()
+
+	acquireCtx, cancel := context.WithTimeout(ctx, pool.timeout)
+	defer cancel()
+
+	conn, err := pool.DB.Conn(acquireCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w after %v: %v", ErrConnAcquireTimeout, pool.timeout, err)
+	}
+	return conn, err
+}
+
+// ReleaseRows returns the connection used by the rows to the pool if they are closed,
+// connections of rows closed by the caller are returned before acquiring the next connection
+func (pool *connAcquirePool) ReleaseRows(rows *sql.Rows) {
+	if !rowsClosed(rows) {
+		return
+	}
+
+	pool.mu.Lock()
+	conn, ok := pool.pinned[rows]
+	delete(pool.pinned, rows)
+	pool.mu.Unlock()
+
+	if ok {
+		conn.Close()
+	}
+}
+
+// releaseClosedRows returns the connections of the closed rows to the pool
+func (pool *connAcquirePool) releaseClosedRows() {
+	var conns []*sql.Conn
+	pool.mu.Lock()
+	for rows, conn := range pool.pinned {
+		if rowsClosed(rows) {
+			delete(pool.pinned, rows)
+			conns = append(conns, conn)
+		}
+	}
+	pool.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// rowsClosed reports whether the rows are closed, Columns fails only after closing
+func rowsClosed(rows *sql.Rows) bool {
+	_, err := rows.Columns()
+	return err != nil
+}
+
+func (pool *connAcquirePool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ExecContext(ctx, query, args...)
+}
+
+func (pool *connAcquirePool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	pool.mu.Lock()
+	if pool.pinned == nil {
+		pool.pinned = map[*sql.Rows]*sql.Conn{}
+	}
+	pool.pinned[rows] = conn
+	pool.mu.Unlock()
+	return rows, nil
+}
+
+// QueryRowContext waits for an available connection with the timeout and queries on the pool with it returned,
+// *sql.Row releases its connection when scanned without a hook to return a pinned one
+func (pool *connAcquirePool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	conn, err := pool.Conn(ctx)
+	if errors.Is(err, ErrConnAcquireTimeout) {
+		return pool.DB.QueryRowContext(acquireErrContext{Context: ctx, err: err}, query, args...)
+	} else if err == nil {
+		conn.Close()
+	}
+	return pool.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (pool *connAcquirePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (ConnPool, error) {
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &connAcquireTx{Tx: tx, conn: conn, db: pool.DB}, nil
+}
+
+func (pool *connAcquirePool) GetDBConn() (*sql.DB, error) {
+	return pool.DB, nil
+}
+
+// connAcquireTx returns the acquired connection to the pool when the transaction is committed or rolled back
+type connAcquireTx struct {
+	*sql.Tx
+	conn *sql.Conn
+	db   *sql.DB
+}
+
+func (tx *connAcquireTx) Commit() error {
+	err := tx.Tx.Commit()
+	tx.conn.Close()
+	return err
+}
+
+func (tx *connAcquireTx) Rollback() error {
+	err := tx.Tx.Rollback()
+	tx.conn.Close()
+	return err
+}
+
+func (tx *connAcquireTx) GetDBConn() (*sql.DB, error) {
+	return tx.db, nil
+}
@@ -51,4 +51,22 @@ var (
 	ErrForeignKeyViolated = errors.New("violates foreign key constraint")
 	// ErrCheckConstraintViolated occurs when there is a check constraint violation
 	ErrCheckConstraintViolated = errors.New("violates check constraint")
+	// ErrInvalidSort invalid sort field or direction
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrTooManyRows the query returns more rows than Config.MaxRows
+	ErrTooManyRows = errors.New("too many rows")
+	// ErrTooManyVars the statement binds more vars than Config.MaxVars
+	ErrTooManyVars = errors.New("too many vars")
+	// ErrConnAcquireTimeout no connection is available in the pool within Config.ConnAcquireTimeout
+	ErrConnAcquireTimeout = errors.New("connection acquire timeout")
+	// ErrPingTimeout the automatic ping when initializing doesn't finish in Config.AutomaticPingTimeout
+	ErrPingTimeout = errors.New("ping timeout")
+	// ErrAmbiguousColumn the result contains duplicate column names that can't be mapped to distinct fields
+	ErrAmbiguousColumn = errors.New("ambiguous column")
+	// ErrDistinctOnOrderMismatch the leading ORDER BY columns don't match the DISTINCT ON columns
+	ErrDistinctOnOrderMismatch = errors.New("leading order by columns must match distinct on columns")
+	// ErrPluginNotRegistered the plugin required by another plugin isn't registered
+	ErrPluginNotRegistered = errors.New("required plugin not registered")
+	// ErrPluginDependencyCycle plugins require each other
+	ErrPluginDependencyCycle = errors.New("plugin dependency cycle")
 )
@@ -3,11 +3,15 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash/maphash"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
@@ -25,8 +29,25 @@ func (db *DB) Create(value interface{}) (tx *DB) {
 		return db.CreateInBatches(value, db.CreateBatchSize)
 	}
 
+	if db.MaxVars > 0 {
+		if reflectValue := reflect.Indirect(reflect.ValueOf(value)); reflectValue.Kind() == reflect.Slice || reflectValue.Kind() == reflect.Array {
+			if batchSize := db.maxVarsBatchSize(reflectValue, reflectValue.Len()); batchSize < reflectValue.Len() {
+				return db.CreateInBatches(value, batchSize)
+			}
+		}
+	}
+
 	// 克隆 db 会话实例
 	tx = db.getInstance()
+
+	// 允许创建空切片时，直接返回
+	if tx.AllowEmptyCreate {
+		if reflectValue := reflect.Indirect(reflect.ValueOf(value)); (reflectValue.Kind() == reflect.Slice || reflectValue.Kind() == reflect.Array) && reflectValue.Len() == 0 {
+			tx.RowsAffected = 0
+			return tx
+		}
+	}
+
 	// 设置 dest
 	tx.Statement.Dest = value
 	// 执行 create processor
@@ -44,26 +65,40 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 
 		// the reflection length judgment of the optimized value
 		reflectLen := reflectValue.Len()
+		if db.MaxVars > 0 {
+			batchSize = db.maxVarsBatchSize(reflectValue, batchSize)
+		}
 
 		callFc := func(tx *DB) error {
-			for i := 0; i < reflectLen; i += batchSize {
+			for i, batch := 0, 1; i < reflectLen; i, batch = i+batchSize, batch+1 {
 				ends := i + batchSize
 				if ends > reflectLen {
 					ends = reflectLen
 				}
 
-				subtx := tx.getInstance()
-				subtx.Statement.Dest = reflectValue.Slice(i, ends).Interface()
-				subtx.callbacks.Create().Execute(subtx)
-				if subtx.Error != nil {
-					return subtx.Error
+				createBatch := func(tx *DB) error {
+					subtx := tx.getInstance()
+					subtx.Statement.Dest = reflectValue.Slice(i, ends).Interface()
+					subtx.callbacks.Create().Execute(subtx)
+					if subtx.Error != nil {
+						return subtx.Error
+					}
+					rowsAffected += subtx.RowsAffected
+					return nil
+				}
+
+				if !tx.BatchTransaction {
+					if err := createBatch(tx); err != nil {
+						return err
+					}
+				} else if err := tx.Transaction(createBatch); err != nil {
+					return batchTransactionError(batch, err)
 				}
-				rowsAffected += subtx.RowsAffected
 			}
 			return nil
 		}
 
-		if tx.SkipDefaultTransaction || reflectLen <= batchSize {
+		if tx.SkipDefaultTransaction || tx.BatchTransaction || reflectLen <= batchSize {
 			tx.AddError(callFc(tx.Session(&Session{})))
 		} else {
 			tx.AddError(tx.Transaction(callFc))
@@ -78,6 +113,37 @@ func (db *DB) CreateInBatches(value interface{}, batchSize int) (tx *DB) {
 	return
 }
 
+// maxVarsBatchSize shrinks batchSize so a batch of rows binds at most Config.MaxVars vars,
+// every creatable field of the schema (or every key of a map) is counted as a var of the row
+func (db *DB) maxVarsBatchSize(reflectValue reflect.Value, batchSize int) int {
+	if reflectValue.Len() == 0 {
+		return batchSize
+	}
+
+	var varsPerRow int
+	if elem := reflect.Indirect(reflectValue.Index(0)); elem.Kind() == reflect.Map {
+		varsPerRow = elem.Len()
+	} else {
+		stmt := &Statement{DB: db}
+		if err := stmt.Parse(reflectValue.Interface()); err != nil || stmt.Schema == nil {
+			return batchSize
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && field.Creatable {
+				varsPerRow++
+			}
+		}
+	}
+
+	if varsPerRow > 0 && batchSize*varsPerRow > db.MaxVars {
+		if batchSize = db.MaxVars / varsPerRow; batchSize < 1 {
+			batchSize = 1
+		}
+	}
+	return batchSize
+}
+
 // Save updates value in database. If value doesn't contain a matching primary key, value is inserted.
 func (db *DB) Save(value interface{}) (tx *DB) {
 	tx = db.getInstance()
@@ -183,12 +249,62 @@ func (db *DB) Find(dest interface{}, conds ...interface{}) (tx *DB) {
 	return tx.callbacks.Query().Execute(tx)
 }
 
+// SelectWithTotal finds records into dest like Find, and selects the total count of the matched records ignoring
+// LIMIT and OFFSET with the `COUNT(*) OVER()` window function into the totalField of each record, so paginated
+// lists don't need another query for the total. E.g.:
+//
+//	type UserWithTotal struct {
+//		User
+//		Total int64 `gorm:"->;-:migration"`
+//	}
+//
+//	var users []UserWithTotal
+//	db.Model(&User{}).Where("age > ?", 18).Limit(10).Offset(20).SelectWithTotal(&users, "Total")
+//	// SELECT `users`.`id`,...,COUNT(*) OVER() AS `total` FROM `users` WHERE age > 18 LIMIT 10 OFFSET 20
+func (db *DB) SelectWithTotal(dest interface{}, totalField string) (tx *DB) {
+	tx = db.getInstance()
+
+	destStmt := &Statement{DB: tx}
+	if err := destStmt.Parse(dest); err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	field := destStmt.Schema.LookUpField(totalField)
+	if field == nil || field.DBName == "" {
+		tx.AddError(fmt.Errorf("%w: total field %s not found in %s", ErrInvalidField, totalField, destStmt.Schema.Name))
+		return
+	}
+
+	totalSQL := "COUNT(*) OVER() AS " + tx.Statement.Quote(field.DBName)
+	if c, ok := tx.Statement.Clauses["SELECT"]; ok && c.Expression != nil {
+		c.Expression = clause.CommaExpression{Exprs: []clause.Expression{c.Expression, clause.Expr{SQL: totalSQL}}}
+		tx.Statement.Clauses["SELECT"] = c
+	} else if len(tx.Statement.Selects) > 0 {
+		tx.Statement.Selects = append(append(make([]string, 0, len(tx.Statement.Selects)+1), tx.Statement.Selects...), totalSQL)
+	} else {
+		columns := make([]clause.Column, 0, len(destStmt.Schema.DBNames))
+		for _, dbName := range destStmt.Schema.DBNames {
+			if dbName != field.DBName {
+				columns = append(columns, clause.Column{Table: clause.CurrentTable, Name: dbName})
+			}
+		}
+		tx.Statement.AddClause(clause.Select{
+			Distinct: tx.Statement.Distinct,
+			Columns:  append(columns, clause.Column{Name: totalSQL, Raw: true}),
+		})
+	}
+
+	tx.Statement.Dest = dest
+	return tx.callbacks.Query().Execute(tx)
+}
+
 // FindInBatches finds all records in batches of batchSize
 func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, batch int) error) *DB {
 	var (
 		tx = db.Order(clause.OrderByColumn{
 			Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
-		}).Session(&Session{})
+		}).Set("gorm:skip_max_rows", true).Session(&Session{})
 		queryDB      = tx
 		rowsAffected int64
 		batch        int
@@ -219,9 +335,20 @@ func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, bat
 		if result.Error == nil && result.RowsAffected != 0 {
 			fcTx := result.Session(&Session{NewDB: true})
 			fcTx.RowsAffected = result.RowsAffected
-			tx.AddError(fc(fcTx, batch))
+			if !tx.BatchTransaction {
+				tx.AddError(fc(fcTx, batch))
+			} else if err := fcTx.Transaction(func(batchTx *DB) error {
+				batchTx.RowsAffected = result.RowsAffected
+				return fc(batchTx, batch)
+			}); err != nil {
+				tx.AddError(batchTransactionError(batch, err))
+			}
 		} else if result.Error != nil {
-			tx.AddError(result.Error)
+			if tx.BatchTransaction {
+				tx.AddError(batchTransactionError(batch, result.Error))
+			} else {
+				tx.AddError(result.Error)
+			}
 		}
 
 		if tx.Error != nil || int(result.RowsAffected) < batchSize {
@@ -256,6 +383,11 @@ func (db *DB) FindInBatches(dest interface{}, batchSize int, fc func(tx *DB, bat
 	return tx
 }
 
+// batchTransactionError reports how many batches are committed before the failed one with Config.BatchTransaction
+func batchTransactionError(batch int, err error) error {
+	return fmt.Errorf("batch %d failed, %d batches committed: %w", batch, batch-1, err)
+}
+
 func (db *DB) assignInterfacesToValue(values ...interface{}) {
 	for _, value := range values {
 		switch v := value.(type) {
@@ -410,6 +542,34 @@ func (db *DB) FirstOrCreate(dest interface{}, conds ...interface{}) (tx *DB) {
 	return tx
 }
 
+// CreateOrUpdate finds the first record matching findConds into value and applies updates to it, otherwise if not found
+// creates value. The matched record is locked with FOR UPDATE and both steps are executed in the same transaction.
+//
+//	created, err := db.CreateOrUpdate(&user, User{Name: "jinzhu"}, map[string]interface{}{"age": 18})
+//	// created -> false if user jinzhu exists and its age is updated to 18
+func (db *DB) CreateOrUpdate(value interface{}, findConds interface{}, updates interface{}) (created bool, err error) {
+	err = db.Transaction(func(tx *DB) error {
+		result := tx.Session(&Session{}).Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).Limit(1).Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
+		}).Find(value, findConds)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			created = true
+			return tx.Create(value).Error
+		}
+
+		return tx.Model(value).Updates(updates).Error
+	})
+
+	if err != nil {
+		created = false
+	}
+	return
+}
+
 // Update updates column with value using callbacks. Reference: https://gorm.io/docs/update.html#Update-Changed-Fields
 func (db *DB) Update(column string, value interface{}) (tx *DB) {
 	tx = db.getInstance()
@@ -428,6 +588,55 @@ func (db *DB) Updates(values interface{}) (tx *DB) {
 	return tx.callbacks.Update().Execute(tx)
 }
 
+// UpdatesReturningOld updates attributes like Updates, and scans the matched rows' values before the update into old.
+// The read and the update are executed in the same transaction, matched rows are locked with FOR UPDATE.
+//
+//	var before []User
+//	db.Model(&User{}).Where("active = ?", true).UpdatesReturningOld(map[string]interface{}{"active": false}, &before)
+func (db *DB) UpdatesReturningOld(values interface{}, old interface{}) (tx *DB) {
+	tx = db.getInstance()
+
+	var rowsAffected int64
+	tx.AddError(tx.Transaction(func(tx *DB) error {
+		queryTx := tx.Session(&Session{}).Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate})
+		if queryTx.Statement.Model == nil {
+			queryTx.Statement.Model = values
+		}
+
+		// use primary keys of model as conditions, the same as updating
+		if err := queryTx.Statement.Parse(queryTx.Statement.Model); err == nil {
+			modelValue := reflect.Indirect(reflect.ValueOf(queryTx.Statement.Model))
+			if modelValue.Kind() == reflect.Struct {
+				for _, field := range queryTx.Statement.Schema.PrimaryFields {
+					if value, isZero := field.ValueOf(queryTx.Statement.Context, modelValue); !isZero {
+						queryTx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+							clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value},
+						}})
+					}
+				}
+			}
+		}
+
+		// check conditions before locking the rows, the same as updating
+		if !queryTx.AllowGlobalUpdate {
+			if where, ok := queryTx.Statement.Clauses["WHERE"].Expression.(clause.Where); !ok || len(where.Exprs) == 0 {
+				return ErrMissingWhereClause
+			}
+		}
+
+		if err := queryTx.Find(old).Error; err != nil {
+			return err
+		}
+
+		updateTx := tx.Updates(values)
+		rowsAffected = updateTx.RowsAffected
+		return updateTx.Error
+	}))
+
+	tx.RowsAffected = rowsAffected
+	return
+}
+
 func (db *DB) UpdateColumn(column string, value interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Statement.Dest = map[string]interface{}{column: value}
@@ -552,6 +761,7 @@ func (db *DB) Scan(dest interface{}) (tx *DB) {
 	tx = db.getInstance()
 	tx.Config = &config
 
+	restoreContext := tx.withQueryTimeout()
 	if rows, err := tx.Rows(); err == nil {
 		if rows.Next() {
 			tx.ScanRows(rows, dest)
@@ -559,10 +769,12 @@ func (db *DB) Scan(dest interface{}) (tx *DB) {
 			tx.RowsAffected = 0
 			tx.AddError(rows.Err())
 		}
-		tx.AddError(rows.Close())
+		tx.addRowsCloseError(rows.Close())
+		releaseRows(tx.Statement.ConnPool, rows)
 	}
+	restoreContext()
 
-	currentLogger.Trace(tx.Statement.Context, newLogger.BeginAt, func() (string, int64) {
+	trace(tx.Statement.Context, currentLogger, newLogger.BeginAt, func() (string, int64) {
 		return newLogger.SQL, tx.RowsAffected
 	}, tx.Error)
 	tx.Logger = currentLogger
@@ -594,6 +806,125 @@ func (db *DB) Pluck(column string, dest interface{}) (tx *DB) {
 	return tx.callbacks.Query().Execute(tx)
 }
 
+// Pluck2 queries two columns from a model, returning in the parallel slices dest1 and dest2. E.g.:
+//
+//	var names []string
+//	var ages []int64
+//	db.Model(&users).Pluck2("name", "age", &names, &ages)
+func (db *DB) Pluck2(col1, col2 string, dest1, dest2 interface{}) (err error) {
+	dests := []reflect.Value{reflect.ValueOf(dest1), reflect.ValueOf(dest2)}
+	for idx, dest := range dests {
+		if dest.Kind() != reflect.Ptr || dest.Elem().Kind() != reflect.Slice {
+			return fmt.Errorf("%w: dest should be a pointer to slice, got %T", ErrInvalidData, []interface{}{dest1, dest2}[idx])
+		}
+	}
+
+	tx := db.getInstance()
+	columns := []string{col1, col2}
+	if tx.Statement.Model != nil && tx.Statement.Parse(tx.Statement.Model) == nil {
+		for idx, column := range columns {
+			if f := tx.Statement.Schema.LookUpField(column); f != nil {
+				columns[idx] = f.DBName
+			}
+		}
+	}
+
+	if len(tx.Statement.Selects) == 0 {
+		selectColumns := make([]clause.Column, len(columns))
+		for idx, column := range columns {
+			fields := strings.FieldsFunc(column, utils.IsValidDBNameChar)
+			selectColumns[idx] = clause.Column{Name: column, Raw: len(fields) != 1}
+		}
+		tx.Statement.AddClauseIfNotExists(clause.Select{Distinct: tx.Statement.Distinct, Columns: selectColumns})
+	}
+
+	defer tx.withQueryTimeout()()
+	rows, err := tx.Rows()
+	if err != nil {
+		return err
+	}
+	defer tx.closeRows(rows, &err)
+
+	if resultColumns, err := rows.Columns(); err != nil {
+		return err
+	} else if len(resultColumns) != len(dests) {
+		return fmt.Errorf("%w: expects 2 columns, got %v", ErrInvalidField, resultColumns)
+	}
+
+	for _, dest := range dests {
+		dest.Elem().SetLen(0)
+	}
+
+	for rows.Next() {
+		v1, v2 := reflect.New(dests[0].Elem().Type().Elem()), reflect.New(dests[1].Elem().Type().Elem())
+		if err := rows.Scan(v1.Interface(), v2.Interface()); err != nil {
+			return err
+		}
+
+		dests[0].Elem().Set(reflect.Append(dests[0].Elem(), v1.Elem()))
+		dests[1].Elem().Set(reflect.Append(dests[1].Elem(), v2.Elem()))
+	}
+
+	return rows.Err()
+}
+
+// ScanIntoMap queries and scans two selected columns into dest, a pointer to map keyed by keyCol with values of valCol. E.g.:
+//
+//	var counts map[string]int
+//	db.Model(&User{}).Select("country, count(*) as c").Group("country").ScanIntoMap("country", "c", &counts)
+func (db *DB) ScanIntoMap(keyCol, valCol string, dest interface{}) (err error) {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("%w: dest should be a pointer to map, got %T", ErrInvalidData, dest)
+	}
+
+	tx := db.getInstance()
+	defer tx.withQueryTimeout()()
+	rows, err := tx.Rows()
+	if err != nil {
+		return err
+	}
+	defer tx.closeRows(rows, &err)
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	keyIdx, valIdx := -1, -1
+	for idx, column := range columns {
+		if column == keyCol {
+			keyIdx = idx
+		} else if column == valCol {
+			valIdx = idx
+		}
+	}
+	if keyIdx == -1 || valIdx == -1 {
+		return fmt.Errorf("%w: columns %s, %s should be selected, got %v", ErrInvalidField, keyCol, valCol, columns)
+	}
+
+	mapValue := destValue.Elem()
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapValue.Type()))
+	}
+
+	values := make([]interface{}, len(columns))
+	for rows.Next() {
+		for idx := range values {
+			values[idx] = new(interface{})
+		}
+		key, value := reflect.New(mapValue.Type().Key()), reflect.New(mapValue.Type().Elem())
+		values[keyIdx], values[valIdx] = key.Interface(), value.Interface()
+
+		if err := rows.Scan(values...); err != nil {
+			return err
+		}
+		mapValue.SetMapIndex(key.Elem(), value.Elem())
+	}
+
+	return rows.Err()
+}
+
 func (db *DB) ScanRows(rows *sql.Rows, dest interface{}) error {
 	tx := db.getInstance()
 	if err := tx.Statement.Parse(dest); !errors.Is(err, schema.ErrUnsupportedDataType) {
@@ -613,6 +944,99 @@ func (db *DB) ScanRows(rows *sql.Rows, dest interface{}) error {
 	return tx.Error
 }
 
+// ScanWithMapping runs the current query and scans the results into dest with the column to field mapping
+// instead of the tag-derived one, columns not in the mapping are ignored, e.g:
+//
+//	var users []User
+//	db.Table("source_users").ScanWithMapping(&users, map[string]string{"user_name": "Name", "years": "Age"})
+func (db *DB) ScanWithMapping(dest interface{}, colToField map[string]string) (err error) {
+	tx := db.getInstance()
+	defer tx.withQueryTimeout()()
+	rows, err := tx.Rows()
+	if err != nil {
+		return err
+	}
+	defer tx.closeRows(rows, &err)
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	mapping := make(map[string]string, len(columns))
+	for _, column := range columns {
+		mapping[column] = colToField[column]
+	}
+
+	if rows.Next() {
+		if err := tx.MapColumns(mapping).ScanRows(rows, dest); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamJSON runs the current query and writes the result to w as a JSON array, encoding rows one by one as they're scanned,
+// rows are scanned into the model if specified, otherwise into map[string]interface{}.
+// The written JSON is incomplete if an error happens during streaming
+//
+//	db.Model(&User{}).Where("age > ?", 18).StreamJSON(w)
+func (db *DB) StreamJSON(w io.Writer) (err error) {
+	tx := db.getInstance()
+
+	newValue := func() interface{} { return &map[string]interface{}{} }
+	if tx.Statement.Model != nil {
+		modelType := reflect.TypeOf(tx.Statement.Model)
+		for modelType.Kind() == reflect.Ptr || modelType.Kind() == reflect.Slice || modelType.Kind() == reflect.Array {
+			modelType = modelType.Elem()
+		}
+
+		if modelType.Kind() == reflect.Struct {
+			newValue = func() interface{} { return reflect.New(modelType).Interface() }
+		}
+	}
+
+	defer tx.withQueryTimeout()()
+	rows, err := tx.Rows()
+	if err != nil {
+		return err
+	}
+	defer tx.closeRows(rows, &err)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for idx := 0; rows.Next(); idx++ {
+		value := newValue()
+		if err := tx.ScanRows(rows, value); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if idx > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
 // Connection uses a db connection to execute an arbitrary number of commands in fc. When finished, the connection is
 // returned to the connection pool.
 func (db *DB) Connection(fc func(tx *DB) error) (err error) {
@@ -704,6 +1128,14 @@ func (db *DB) Begin(opts ...*sql.TxOptions) *DB {
 		opt = opts[0]
 	}
 
+	snapshotID, _ := tx.Get("gorm:snapshot_id")
+	if snapshotID != nil {
+		if opt, err = snapshotTxOptions(tx, opt); err != nil {
+			tx.AddError(err)
+			return tx
+		}
+	}
+
 	ctx := tx.Statement.Context
 	if _, ok := ctx.Deadline(); !ok {
 		if db.Config.DefaultTransactionTimeout > 0 {
@@ -725,19 +1157,56 @@ func (db *DB) Begin(opts ...*sql.TxOptions) *DB {
 		err = ErrInvalidTransaction
 	}
 
+	if err == nil && snapshotID != nil {
+		// the snapshot must be imported before any query of the transaction
+		if _, err = tx.Statement.ConnPool.ExecContext(ctx, "SET TRANSACTION SNAPSHOT '"+strings.ReplaceAll(snapshotID.(string), "'", "''")+"'"); err != nil {
+			if committer, ok := tx.Statement.ConnPool.(TxCommitter); ok {
+				_ = committer.Rollback()
+			}
+		}
+	}
+
 	if err != nil {
 		tx.AddError(err)
+	} else {
+		tx.Statement.Settings.Store(afterCommitHooksKey, &txAfterCommitHooks{})
 	}
 
 	return tx
 }
 
+// snapshotTxOptions checks the transaction importing a snapshot is supported, it runs in REPEATABLE READ if no isolation specified
+func snapshotTxOptions(db *DB, opt *sql.TxOptions) (*sql.TxOptions, error) {
+	if db.Dialector == nil || db.Dialector.Name() != "postgres" {
+		return nil, fmt.Errorf("%w: importing snapshot isn't supported", ErrUnsupportedDriver)
+	}
+
+	if opt == nil {
+		return &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, nil
+	}
+
+	switch opt.Isolation {
+	case sql.LevelDefault:
+		return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: opt.ReadOnly}, nil
+	case sql.LevelRepeatableRead, sql.LevelSerializable:
+		return opt, nil
+	default:
+		return nil, fmt.Errorf("%w: importing snapshot requires REPEATABLE READ or SERIALIZABLE isolation, got %v", ErrInvalidTransaction, opt.Isolation)
+	}
+}
+
 // Commit commits the changes in a transaction
 // 执行事务提交操作：
 func (db *DB) Commit() *DB {
 	// 默认情况下，此处的 ConnPool 实现类为 database/sql.Tx
 	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil && !reflect.ValueOf(committer).IsNil() {
-		db.AddError(committer.Commit())
+		hooks := db.txAfterCommitHooks()
+		if err := committer.Commit(); err != nil {
+			db.AddError(err)
+			runHooks(hooks.take(false))
+		} else {
+			runHooks(hooks.take(true))
+		}
 	} else {
 		db.AddError(ErrInvalidTransaction)
 	}
@@ -751,6 +1220,7 @@ func (db *DB) Rollback() *DB {
 	if committer, ok := db.Statement.ConnPool.(TxCommitter); ok && committer != nil {
 		if !reflect.ValueOf(committer).IsNil() {
 			db.AddError(committer.Rollback())
+			runHooks(db.txAfterCommitHooks().take(false))
 		}
 	} else {
 		db.AddError(ErrInvalidTransaction)
@@ -758,8 +1228,133 @@ func (db *DB) Rollback() *DB {
 	return db
 }
 
+// AfterCommit registers fc to run after the current transaction commits successfully, functions run outside the
+// transaction in registration order, and are discarded if the transaction (or the savepoint) rolls back,
+// fc runs immediately if db is not in a transaction, e.g:
+//
+//	db.Transaction(func(tx *gorm.DB) error {
+//		tx.Create(&order)
+//		tx.AfterCommit(func() { publishOrderCreated(order) })
+//		return nil
+//	})
+//
+// it also works for the default transaction when registered in hooks, e.g: AfterCreate
+func (db *DB) AfterCommit(fc func()) *DB {
+	if hooks := db.txAfterCommitHooks(); hooks != nil {
+		hooks.mux.Lock()
+		hooks.fcs = append(hooks.fcs, fc)
+		hooks.mux.Unlock()
+	} else {
+		fc()
+	}
+	return db
+}
+
+// AddAfterCommit registers fc like AfterCommit, but fc is skipped if db is not in a transaction and db.Error is set,
+// so it could be chained after a statement using the default transaction, e.g:
+//
+//	db.Create(&order).AddAfterCommit(func() { publishOrderCreated(order) })
+func (db *DB) AddAfterCommit(fc func()) *DB {
+	if db.txAfterCommitHooks() == nil && db.Error != nil {
+		return db
+	}
+	return db.AfterCommit(fc)
+}
+
+// AddAfterRollback registers fc to run after the current transaction rolls back, or fails to commit, functions run in
+// registration order, functions registered in a savepoint run once it's rolled back to, fc never runs if db is not
+// in a transaction, e.g:
+//
+//	db.Transaction(func(tx *gorm.DB) error {
+//		cache.Reserve(order.ID)
+//		tx.AddAfterRollback(func() { cache.Release(order.ID) })
+//		return tx.Create(&order).Error
+//	})
+func (db *DB) AddAfterRollback(fc func()) *DB {
+	if hooks := db.txAfterCommitHooks(); hooks != nil {
+		hooks.mux.Lock()
+		hooks.rollbackFcs = append(hooks.rollbackFcs, fc)
+		hooks.mux.Unlock()
+	}
+	return db
+}
+
+func runHooks(fcs []func()) {
+	for _, fc := range fcs {
+		fc()
+	}
+}
+
+// afterCommitHooksKey the setting key of the functions registered by AfterCommit and AddAfterRollback, it's stored by
+// Begin and shared by all sessions of the transaction
+const afterCommitHooksKey = "gorm:after_commit_hooks"
+
+type txAfterCommitHooks struct {
+	mux         sync.Mutex
+	fcs         []func()
+	rollbackFcs []func()
+	savePoints  map[string][2]int
+}
+
+// take returns the functions to run after committing or rolling back, and clears the registered functions
+func (hooks *txAfterCommitHooks) take(committed bool) (fcs []func()) {
+	if hooks == nil {
+		return nil
+	}
+
+	hooks.mux.Lock()
+	if committed {
+		fcs = hooks.fcs
+	} else {
+		fcs = hooks.rollbackFcs
+	}
+	hooks.fcs, hooks.rollbackFcs, hooks.savePoints = nil, nil, nil
+	hooks.mux.Unlock()
+	return fcs
+}
+
+// txAfterCommitHooks returns the registered functions of the current transaction, nil if db is not in a transaction
+func (db *DB) txAfterCommitHooks() *txAfterCommitHooks {
+	if committer, ok := db.Statement.ConnPool.(TxCommitter); !ok || committer == nil || reflect.ValueOf(committer).IsNil() {
+		return nil
+	}
+
+	if v, ok := db.Statement.Settings.Load(afterCommitHooksKey); ok {
+		return v.(*txAfterCommitHooks)
+	}
+	return nil
+}
+
+// savePointAfterCommitHooks records or restores the registered functions of the savepoint, returns the AddAfterRollback
+// functions registered since the savepoint when rolling back to it
+func (db *DB) savePointAfterCommitHooks(name string, rollback bool) (rollbackFcs []func()) {
+	hooks := db.txAfterCommitHooks()
+	if hooks == nil {
+		return
+	}
+
+	hooks.mux.Lock()
+	defer hooks.mux.Unlock()
+
+	if rollback {
+		if n, ok := hooks.savePoints[name]; ok && n[0] <= len(hooks.fcs) && n[1] <= len(hooks.rollbackFcs) {
+			hooks.fcs = hooks.fcs[:n[0]]
+			rollbackFcs = append(rollbackFcs, hooks.rollbackFcs[n[1]:]...)
+			hooks.rollbackFcs = hooks.rollbackFcs[:n[1]]
+		}
+		return
+	}
+
+	if hooks.savePoints == nil {
+		hooks.savePoints = map[string][2]int{}
+	}
+	hooks.savePoints[name] = [2]int{len(hooks.fcs), len(hooks.rollbackFcs)}
+	return
+}
+
 func (db *DB) SavePoint(name string) *DB {
 	if savePointer, ok := db.Dialector.(SavePointerDialectorInterface); ok {
+		db.savePointAfterCommitHooks(name, false)
 		// close prepared statement, because SavePoint not support prepared statement.
 		// e.g. mysql8.0 doc: https://dev.mysql.com/doc/refman/8.0/en/sql-prepared-statements.html
 		var (
@@ -783,6 +1378,7 @@ func (db *DB) SavePoint(name string) *DB {
 
 func (db *DB) RollbackTo(name string) *DB {
 	if savePointer, ok := db.Dialector.(SavePointerDialectorInterface); ok {
+		rollbackFcs := db.savePointAfterCommitHooks(name, true)
 		// close prepared statement, because RollbackTo not support prepared statement.
 		// e.g. mysql8.0 doc: https://dev.mysql.com/doc/refman/8.0/en/sql-prepared-statements.html
 		var (
@@ -798,6 +1394,7 @@ func (db *DB) RollbackTo(name string) *DB {
 		if isPreparedStmtTx {
 			db.Statement.ConnPool = preparedStmtTx
 		}
+		runHooks(rollbackFcs)
 	} else {
 		db.AddError(ErrUnsupportedDriver)
 	}
@@ -817,3 +1414,173 @@ func (db *DB) Exec(sql string, values ...interface{}) (tx *DB) {
 
 	return tx.callbacks.Raw().Execute(tx)
 }
+
+// valuesCastType returns the type to cast values to for the column data type, serial types are not valid in casts
+func valuesCastType(dataType string) string {
+	switch strings.ToLower(dataType) {
+	case "smallserial":
+		return "smallint"
+	case "serial":
+		return "integer"
+	case "bigserial":
+		return "bigint"
+	}
+	return dataType
+}
+
+// BulkUpdate updates many rows with different values in one statement, rows are matched by keyCols, e.g:
+//
+//	db.BulkUpdate(&User{}, []map[string]interface{}{{"id": 1, "name": "jinzhu"}, {"id": 2, "name": "gorm"}}, []string{"id"})
+//	// PostgreSQL: UPDATE "users" SET "name"="v"."name" FROM (VALUES (CAST($1 AS bigint),CAST($2 AS text)),($3,$4)) AS "v" ("id","name") WHERE "users"."id"="v"."id"
+//	// Others: UPDATE `users` SET `name`=CASE WHEN `users`.`id`=? THEN ? WHEN `users`.`id`=? THEN ? ELSE `name` END WHERE `users`.`id` IN (?,?)
+//
+// all rows should have the same columns, hooks and soft delete are not applied
+func (db *DB) BulkUpdate(model interface{}, rows []map[string]interface{}, keyCols []string) (tx *DB) {
+	tx = db.getInstance()
+	if err := tx.Statement.Parse(model); err != nil {
+		tx.AddError(err)
+		return
+	}
+	tx.Statement.Model = model
+
+	if len(keyCols) == 0 {
+		tx.AddError(fmt.Errorf("%w: key columns are required for bulk update", ErrInvalidData))
+		return
+	} else if len(rows) == 0 {
+		return
+	}
+
+	lookUpDBName := func(name string) string {
+		if field := tx.Statement.Schema.LookUpField(name); field != nil {
+			return field.DBName
+		}
+		return name
+	}
+
+	rowValues := make([]map[string]interface{}, len(rows))
+	for idx, row := range rows {
+		rowValues[idx] = make(map[string]interface{}, len(row))
+		for k, v := range row {
+			rowValues[idx][lookUpDBName(k)] = v
+		}
+	}
+
+	keys := make([]string, len(keyCols))
+	isKey := make(map[string]bool, len(keyCols))
+	for idx, key := range keyCols {
+		keys[idx] = lookUpDBName(key)
+		isKey[keys[idx]] = true
+	}
+
+	columns := make([]string, 0, len(rowValues[0]))
+	for column := range rowValues[0] {
+		if !isKey[column] {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	if len(columns) == 0 {
+		tx.AddError(fmt.Errorf("%w: no columns to update", ErrInvalidData))
+		return
+	}
+
+	allColumns := append(append(make([]string, 0, len(keys)+len(columns)), keys...), columns...)
+	keyValues := make([][]interface{}, len(rowValues))
+	for idx, row := range rowValues {
+		if len(row) != len(allColumns) {
+			tx.AddError(fmt.Errorf("%w: row %d has different columns", ErrInvalidData, idx))
+			return
+		}
+
+		for _, column := range allColumns {
+			if _, ok := row[column]; !ok {
+				tx.AddError(fmt.Errorf("%w: row %d missing column %s", ErrInvalidData, idx, column))
+				return
+			}
+		}
+
+		keyValues[idx] = make([]interface{}, len(keys))
+		for i, key := range keys {
+			keyValues[idx][i] = row[key]
+		}
+	}
+
+	var (
+		sql  strings.Builder
+		vars = []interface{}{clause.Table{Name: clause.CurrentTable}}
+	)
+
+	sql.WriteString("UPDATE ? SET ")
+	if tx.Statement.DialectName() == "postgres" {
+		// values in VALUES are typed from the first row, cast them to the column types, otherwise
+		// parameters are inferred as text and can't be assigned to or compared with int, timestamp columns
+		valuesTable := clause.ValuesTable{Alias: "v", Values: make([][]interface{}, len(rowValues))}
+		for _, column := range allColumns {
+			valuesTable.Columns = append(valuesTable.Columns, clause.Column{Name: column})
+
+			var castType string
+			if field := tx.Statement.Schema.LookUpField(column); field != nil {
+				castType = valuesCastType(tx.Dialector.DataTypeOf(field))
+			}
+
+			for idx, row := range rowValues {
+				if idx == 0 && castType != "" {
+					valuesTable.Values[idx] = append(valuesTable.Values[idx], clause.Expr{SQL: "CAST(? AS " + castType + ")", Vars: []interface{}{row[column]}})
+				} else {
+					valuesTable.Values[idx] = append(valuesTable.Values[idx], row[column])
+				}
+			}
+		}
+
+		for idx, column := range columns {
+			if idx > 0 {
+				sql.WriteByte(',')
+			}
+			sql.WriteString("?=?")
+			vars = append(vars, clause.Column{Name: column}, clause.Column{Table: valuesTable.Alias, Name: column})
+		}
+
+		sql.WriteString(" FROM ? WHERE ")
+		vars = append(vars, valuesTable)
+		for idx, key := range keys {
+			if idx > 0 {
+				sql.WriteString(" AND ")
+			}
+			sql.WriteString("?=?")
+			vars = append(vars, clause.Column{Table: clause.CurrentTable, Name: key}, clause.Column{Table: valuesTable.Alias, Name: key})
+		}
+	} else {
+		for idx, column := range columns {
+			if idx > 0 {
+				sql.WriteByte(',')
+			}
+			sql.WriteString("?=CASE")
+			vars = append(vars, clause.Column{Name: column})
+
+			for _, row := range rowValues {
+				sql.WriteString(" WHEN ")
+				for i, key := range keys {
+					if i > 0 {
+						sql.WriteString(" AND ")
+					}
+					sql.WriteString("?=?")
+					vars = append(vars, clause.Column{Table: clause.CurrentTable, Name: key}, row[key])
+				}
+				sql.WriteString(" THEN ?")
+				vars = append(vars, row[column])
+			}
+
+			sql.WriteString(" ELSE ? END")
+			vars = append(vars, clause.Column{Name: column})
+		}
+
+		column, values := schema.ToQueryValues(clause.CurrentTable, keys, keyValues)
+		sql.WriteString(" WHERE ?")
+		vars = append(vars, clause.IN{Column: column, Values: values})
+	}
+
+	tx.Statement.SQL = strings.Builder{}
+	clause.Expr{SQL: sql.String(), Vars: vars}.Build(tx.Statement)
+	return tx.callbacks.Raw().Execute(tx)
+}
@@ -120,6 +120,81 @@ func G[T any](db *DB, opts ...clause.Expression) Interface[T] {
 	return v
 }
 
+// FirstOrInit finds the first record matching conds, returning it and found as true,
+// otherwise returns a new T initialized with conds (and Attrs, Assign), and found as false
+//
+//	user, found, err := gorm.FirstOrInit[User](db, User{Name: "non_existing"})
+//	// user -> User{Name: "non_existing"}, found -> false
+func FirstOrInit[T any](db *DB, conds ...interface{}) (T, bool, error) {
+	var r T
+	tx := db.FirstOrInit(&r, conds...)
+	if tx.Error != nil {
+		var zero T
+		return zero, false, tx.Error
+	}
+	return r, tx.RowsAffected > 0, nil
+}
+
+// AssociationFind finds the assoc association of owner matching conds into a typed slice, the owner isn't modified,
+// Order/Limit and other conditions chained on db are applied
+//
+//	pets, err := gorm.AssociationFind[Pet](db.Order("name").Limit(10), &user, "Pets", "name LIKE ?", "a%")
+func AssociationFind[T any](db *DB, owner interface{}, assoc string, conds ...interface{}) ([]T, error) {
+	var results []T
+	if err := db.Model(owner).Association(assoc).Find(&results, conds...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ScalarValue runs the query with selectExpr as the only column and scans the first row into T,
+// returns ErrRecordNotFound if no row, a NULL value is returned as the zero value of T
+//
+//	total, err := gorm.ScalarValue[float64](db.Model(&Order{}).Where("paid = ?", true), "SUM(amount)")
+func ScalarValue[T any](db *DB, selectExpr string) (r T, err error) {
+	tx := db.Select(selectExpr)
+	defer tx.withQueryTimeout()()
+	rows, err := tx.Rows()
+	if err != nil {
+		return r, err
+	}
+	defer tx.closeRows(rows, &err)
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return r, err
+		}
+		return r, ErrRecordNotFound
+	}
+
+	var v *T
+	if err := rows.Scan(&v); err != nil {
+		return r, err
+	}
+
+	if v != nil {
+		r = *v
+	}
+	return r, rows.Err()
+}
+
+// SetValue store typed value with key into current db instance's context, same as db.Set
+func SetValue[T any](db *DB, key string, v T) *DB {
+	return db.Set(key, v)
+}
+
+// GetValue get typed value with key from current db instance's context, returns false if not found or the type mismatch
+func GetValue[T any](db *DB, key string) (T, bool) {
+	if v, ok := db.Get(key); ok {
+		if r, ok := v.(T); ok {
+			return r, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
 type g[T any] struct {
 	*createG[T]
 	db  *DB
@@ -3,9 +3,11 @@ package gorm
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,17 +19,37 @@ import (
 // for Config.cacheStore store PreparedStmtDB key
 const preparedStmtDBKey = "preparedStmt"
 
+// defaultWarmupTimeout limits warming up the conn pool when AutomaticPingTimeout is unset
+const defaultWarmupTimeout = 30 * time.Second
+
 // Config GORM config
 type Config struct {
 	// GORM perform single create, update, delete operations in transactions by default to ensure database data integrity
 	// You can disable it by setting `SkipDefaultTransaction` to true
+	// If the context is cancelled before the transaction commits, it is always rolled back and no partial write is committed
 	// 会自动开启事务以保证数据一致性。将此项设置为 true 可以跳过这个默认行为，从而提升性能。
 	// 适合在你已确保业务逻辑中不会产生数据不一致问题的情况下使用。
+	// 若上下文在提交前被取消，默认事务总会回滚，不会提交部分写入，db.Error 会包装 context.Canceled。
 	SkipDefaultTransaction bool
 
 	// 如果事务在指定时间内未完成，将自动回滚。
 	DefaultTransactionTimeout time.Duration
 
+	// DefaultQueryTimeout limits the duration of every statement, the context is cancelled to abort the query in the driver,
+	// an earlier deadline of the caller's context is kept, it isn't applied to Row/Rows as the rows outlive the call
+	// DefaultQueryTimeout 每条语句执行的超时时间，超时后通过 context 取消驱动中的查询；调用方 context 的截止时间更早时以其为准，
+	// 不作用于 Row/Rows，因为返回的结果集在调用结束后仍需读取。
+	DefaultQueryTimeout time.Duration
+
+	// ConnAcquireTimeout limits the duration of acquiring a connection from the *sql.DB pool to execute a statement or
+	// begin a transaction, an error wrapping ErrConnAcquireTimeout is returned if no connection is available in time,
+	// the execution is still limited by the statement's context, it can't be used with PrepareStmt.
+	// Connections of rows returned by Rows are returned to the pool when the next statement starts after they're closed
+	// ConnAcquireTimeout 从 *sql.DB 连接池获取连接（执行语句或开启事务）的超时时间，超时返回包装了 ErrConnAcquireTimeout 的错误，
+	// 连接池耗尽时快速失败而不是阻塞到语句自身的 context 超时；不能与 PrepareStmt 同时使用。
+	// Rows 返回的结果集关闭后，其连接会在下一条语句开始时归还连接池。
+	ConnAcquireTimeout time.Duration
+
 	// NamingStrategy tables, columns naming strategy
 	// NamingStrategy 命名策略，用于控制表名、列名等的生成规则。
 	// 可以通过此项自定义命名风格（如是否使用下划线，是否复数等）。
@@ -48,6 +70,47 @@ type Config struct {
 	// 可自定义时间源（如用于模拟时间、统一时区等）。
 	NowFunc func() time.Time
 
+	// QuoteTo overrides quoting identifiers of statements, returns false to fall back to the dialector, e.g: to reference
+	// objects the dialector mangles like `users@remote_link`, it must escape the identifier itself, see QuoteIdentifierTo
+	// QuoteTo 自定义语句中标识符（表名、列名等）的引用方式，返回 false 时回退到 Dialector 的默认实现，可通过 Session 仅对单次会话生效；
+	// 该函数需自行转义标识符以避免 SQL 注入，可使用 QuoteIdentifierTo。
+	QuoteTo func(writer clause.Writer, str string) bool
+
+	// BindInterceptors modify values of fields tagged with `normalize:name` with the interceptor of the name before binding them
+	// when creating and updating, nil values are skipped and pointers are dereferenced before intercepting
+	// BindInterceptors 按名称注册的绑定值拦截器，创建、更新时对标记了 `normalize:名称` 标签的字段值在绑定前进行统一处理
+	// （如邮箱转小写、去除首尾空格）；nil 值不处理，指针会先解引用再传入拦截器。
+	BindInterceptors map[string]func(value interface{}) interface{}
+
+	// ActorFunc returns the actor of the context, fields tagged with `actor:created` are populated with it when creating,
+	// fields tagged with `actor:updated` are populated when creating and updating, it's a no-op if no actor returned
+	// ActorFunc 从 context 中获取当前操作人，创建时填充 `actor:created`、`actor:updated` 标签的字段，
+	// 更新时填充 `actor:updated` 标签的字段，用于统一维护 created_by/updated_by 审计字段；返回 false 时不做处理。
+	ActorFunc func(ctx context.Context) (interface{}, bool)
+
+	// ScanLocation the location scanned time.Time values will be converted to, keep the driver's location if nil
+	// ScanLocation 查询结果中 time.Time、*time.Time 类型字段扫描后统一转换到的时区，为 nil 时保持驱动返回的时区。
+	ScanLocation *time.Location
+
+	// ExplainSampleRate the fraction (0 ~ 1) of executed queries to run EXPLAIN for, the plan will be passed to OnExplain
+	// ExplainSampleRate 按比例（0 ~ 1）对执行过的查询语句抽样执行 EXPLAIN，执行计划会传递给 OnExplain。
+	ExplainSampleRate float64
+
+	// OnExplain handler of sampled query plans, EXPLAIN runs on the *sql.DB in the background after the query with a
+	// timeout, so it's called from another goroutine, samples are dropped if too many are running,
+	// queries in transactions aren't sampled
+	// OnExplain 抽样得到的查询计划的处理函数，不会影响查询本身的结果和错误；
+	// EXPLAIN 在查询结束后于后台通过 *sql.DB 执行并带有超时，因此该函数会在其他 goroutine 中调用，
+	// 后台执行的 EXPLAIN 过多时会丢弃抽样，事务中的查询不会被抽样。
+	OnExplain func(sql string, plan string)
+
+	// OnWrite is called after the statement of Create/Update/Delete is executed with the operation (create, update, delete),
+	// the table, the rows affected and the primary keys of the written values, keys are best-effort, e.g: empty when
+	// updating or deleting with conditions only
+	// OnWrite 在 Create/Update/Delete 语句执行后调用，传入操作类型、表名、影响行数以及写入数据的主键，可用于统一的写操作审计；
+	// 主键尽力而为（来自 RETURNING 或传入数据的主键），仅按条件更新/删除时为空。
+	OnWrite func(ctx context.Context, op string, table string, rowsAffected int64, keys []interface{})
+
 	// DryRun generate sql without execute
 	// DryRun 设置为 true 时不会实际执行 SQL，只生成 SQL 语句并返回结果。
 	// 通常用于调试或生成 SQL 脚本。
@@ -67,11 +130,56 @@ type Config struct {
 	// PrepareStmtTTL 设置缓存中每个预编译语句的存活时间，默认是 1 小时。
 	PrepareStmtTTL time.Duration
 
+	// PrepareStmtMaxBytes caps the total bytes of the cached statements' SQL templates, the least recently used
+	// statements are evicted when either PrepareStmtMaxSize or it's exceeded, zero means unlimited
+	// PrepareStmtMaxBytes 限制缓存的预编译语句 SQL 模板的总字节数，超出时按 LRU 顺序逐出，0 表示不限制；
+	// 适用于少量超大 SQL（如超长 IN 列表）占用大量驱动内存的场景。
+	PrepareStmtMaxBytes int64
+
+	// StrictTxStmtReuse only reuses prepared statements within the transaction they are prepared in,
+	// statements prepared outside of transactions are never shared into transactions, for drivers binding statements to connections
+	// StrictTxStmtReuse 事务中仅复用在同一事务内预编译的语句，不会将事务外预编译的语句共享到事务中，
+	// 适用于将预编译语句绑定到连接上的驱动；事务内的语句随事务结束而释放。
+	StrictTxStmtReuse bool
+
 	// DisableAutomaticPing
 	// DisableAutomaticPing 禁用自动 ping 数据库（GORM 在启动时会尝试 ping 数据库）。
 	// 某些数据库或网络条件下可设置为 true 来跳过。
 	DisableAutomaticPing bool
 
+	// AutomaticPingTimeout limits the duration of the automatic ping when initializing, zero means no timeout,
+	// Open returns an error wrapping ErrPingTimeout if the ping doesn't finish in time
+	// AutomaticPingTimeout 初始化时自动 ping 的超时时间，0 表示不限制；
+	// 超时后 Open 返回包装了 ErrPingTimeout 的错误，以便与连接被拒绝等错误区分，网络挂起时不会无限阻塞启动。
+	AutomaticPingTimeout time.Duration
+
+	// WarmupConns number of connections to open when initializing, used to prime the conn pool, limited by MaxOpenConns.
+	// Connections beyond the pool's MaxIdleConns (2 by default) are closed when returned, set it on the *sql.DB
+	// passed to the dialector to keep more of them. Warmup is limited by AutomaticPingTimeout, or 30s if it's unset,
+	// and the pool is closed if it fails
+	// WarmupConns 初始化时预先建立的连接数量，用于预热连接池，不会超过 MaxOpenConns。
+	// 超过连接池 MaxIdleConns（默认为 2）的连接在归还时会被关闭，如需保留更多连接，请在传给 dialector 的 *sql.DB 上设置；
+	// 预热受 AutomaticPingTimeout 限制，未设置时为 30 秒，预热失败时会关闭连接池。
+	WarmupConns int
+
+	// SchemaResolver resolves the schema by context, tables are prefixed with the resolved schema when building SQL,
+	// e.g: `tenant_abc`.`users`, tables specified by `Table` or already qualified are used as they are
+	// SchemaResolver 根据 context 解析 schema，构建 SQL 时为表名加上解析出的 schema 前缀（如 `tenant_abc`.`users`），
+	// 适用于按 schema 划分租户的场景；通过 `Table` 指定或已带 schema 的表名保持不变。
+	SchemaResolver func(ctx context.Context) string
+
+	// MigrationLock acquire a database lock when AutoMigrate, serializing migrations across instances,
+	// uses advisory locks on postgres and mysql, or a row of table `gorm_migration_locks` for others
+	// MigrationLock 在 AutoMigrate 时获取数据库锁，使多个实例的迁移串行执行。
+	// postgres、mysql 使用 advisory lock，其他数据库使用 `gorm_migration_locks` 表中的一行记录作为锁。
+	MigrationLock bool
+
+	// MigrationLockTimeout the lock row of `gorm_migration_locks` held longer than it is considered stale (e.g: the holder crashed)
+	// and taken over by others, defaults to 10 minutes, advisory locks are released by the database when the session ends
+	// MigrationLockTimeout `gorm_migration_locks` 表中持有超过该时长的锁记录被视为失效（如持有者已崩溃），可被其他实例接管，
+	// 默认 10 分钟；advisory lock 在会话结束时由数据库自动释放，不受此影响。
+	MigrationLockTimeout time.Duration
+
 	// DisableForeignKeyConstraintWhenMigrating
 	// DisableForeignKeyConstraintWhenMigrating 在迁移（AutoMigrate）时禁用外键约束创建。
 	// 某些数据库或出于设计需要可以关闭外键。
@@ -97,15 +205,94 @@ type Config struct {
 	// 可用于某些特定场景下避免字段缺失的问题。
 	QueryFields bool
 
+	// AutoGroupBy appends the plain columns of the select list missing from a specified GROUP BY to it,
+	// aggregates and other expressions are left untouched
+	// AutoGroupBy 已指定 GROUP BY 时，自动将 SELECT 中未出现在 GROUP BY 里的普通列追加进去，避免 "must appear in the GROUP BY clause" 错误；
+	// 聚合函数及其它表达式不受影响。
+	AutoGroupBy bool
+
+	// WarnOnUnindexedWhere logs a warning listing the WHERE columns without an index declared by the model when querying,
+	// updating and deleting, it's a best-effort static check with the model's primary keys, unique fields and indexes
+	// WarnOnUnindexedWhere 查询、更新、删除时若 WHERE 条件中的列在模型中未声明索引（主键、unique、index 标签），记录一条警告，
+	// 仅基于模型元数据的静态检查，不会查询数据库中的实际索引，适合在开发环境发现潜在的慢查询。
+	WarnOnUnindexedWhere bool
+
 	// CreateBatchSize default create batch size
 	// CreateBatchSize 设置批量创建记录时的默认每批数量。
 	// 数据量大时建议设置为合适的值（如 100、500 等），以避免 SQL 长度超限。
 	CreateBatchSize int
 
+	// AllowEmptyCreate makes Create with an empty slice a no-op instead of returning ErrEmptySlice
+	// AllowEmptyCreate 开启后，Create 空切片时不执行任何操作（RowsAffected 为 0），而不是返回 ErrEmptySlice。
+	AllowEmptyCreate bool
+
+	// PreloadBatchSize splits the IN list of parent keys into chunks of this size when preloading, default unlimited
+	// PreloadBatchSize 预加载时将父记录主键的 IN 列表按此大小拆分为多次查询，避免超出数据库参数数量限制，默认不拆分。
+	PreloadBatchSize int
+
+	// BatchTransaction commits every batch of CreateInBatches and FindInBatches (the query is run before the transaction,
+	// fc in it) in its own transaction, batches completed stay committed if a later one fails, the error reports how many
+	// BatchTransaction 开启后 CreateInBatches、FindInBatches 每一批数据在独立的事务中执行并提交（FindInBatches 的查询在事务外执行，fc 在事务内执行），
+	// 适用于可断点续跑、需要缩短锁持有时间的大批量任务；中途出错时已完成的批次保持提交，返回的错误中包含已提交的批次数。
+	BatchTransaction bool
+
+	// InlineLimitOffset renders LIMIT/OFFSET numbers as literals instead of bound parameters,
+	// for backends or proxies reject parameterized LIMIT in prepared statements
+	// InlineLimitOffset 将 LIMIT/OFFSET 的数值直接写入 SQL 而非使用占位符，
+	// 适用于不支持参数化 LIMIT 的数据库或代理；仅接受非负整数，避免 SQL 注入。
+	InlineLimitOffset bool
+
+	// DefaultNullsOrder sorts NULL values first or last for ORDER BY columns don't specify their own, `NULLS FIRST/LAST` is used
+	// if supported by the dialect (see clause.NullsOrderDialects), otherwise it's emulated with `CASE WHEN column IS NULL`
+	// DefaultNullsOrder 未单独指定 NULL 排序位置的 ORDER BY 列统一将 NULL 排在最前或最后，使 MySQL（NULL 在前）与 Postgres（NULL 在后）分页行为一致；
+	// 方言支持时生成 `NULLS FIRST/LAST`，否则使用 `CASE WHEN column IS NULL` 模拟，可能导致无法利用索引排序。
+	DefaultNullsOrder clause.NullsOrder
+
+	// WarnOnImplicitCrossJoin logs a warning when a built join has no ON/USING condition, which likely produces a cartesian product,
+	// it is a build-time check only, explicit CROSS/NATURAL joins are not reported
+	// WarnOnImplicitCrossJoin 构建 SQL 时检查 join 子句，缺少 ON/USING 条件时输出警告日志，用于尽早发现笛卡尔积导致的重复数据问题；
+	// 仅在构建阶段检查，不影响执行，显式的 CROSS/NATURAL JOIN 不会告警。
+	WarnOnImplicitCrossJoin bool
+
+	// MaxRows aborts queries with ErrTooManyRows once they scan more than MaxRows rows, zero means unlimited,
+	// it doesn't apply to FindInBatches and the Rows/ScanRows streaming APIs
+	// MaxRows 查询扫描的行数超过该值时中止并返回 ErrTooManyRows，防止无界查询耗尽内存，0 表示不限制；
+	// 不作用于 FindInBatches 以及 Rows/ScanRows 等流式接口。
+	MaxRows int
+
+	// MaxVars caps the bound vars of a single statement, zero means unlimited, batch creates are split into
+	// smaller chunks to stay under the limit and lists (e.g. IN) exceeding it fail with ErrTooManyVars
+	// MaxVars 限制单条语句绑定参数（Statement.Vars）的数量，避免超大批量插入或 IN 列表占用过多内存，0 表示不限制；
+	// 批量创建时会自动缩小每批数量，IN 等列表参数超出限制时返回 ErrTooManyVars。
+	MaxVars int
+
+	// OnScanError is called when a column can't be converted to the field it's scanned into,
+	// returning nil leaves the field zero and continues, otherwise the returned error is added to the statement
+	// OnScanError 在列值无法转换为字段类型时调用，raw 为数据库返回的原始值，可用于跳过脏数据或为错误补充字段信息；
+	// 返回 nil 表示该字段保持零值并继续扫描，否则返回的错误会被记录到 db.Error。
+	OnScanError func(field *schema.Field, raw interface{}, err error) error
+
+	// OnAmbiguousColumn decides how duplicate result column names that can't be mapped to distinct fields are scanned,
+	// e.g. `SELECT *` with joins, defaults to AmbiguousColumnLastWins
+	// OnAmbiguousColumn 结果集中存在无法映射到不同字段的重名列（如联表 `SELECT *`）时的处理策略，默认后出现的列覆盖前面的值。
+	OnAmbiguousColumn AmbiguousColumnStrategy
+
+	// IgnoreRowsCloseError ignores errors returned when closing the result rows, by default they are added to the statement,
+	// as a deferred error (e.g. a broken connection) surfacing at close may mean the result is truncated
+	// IgnoreRowsCloseError 忽略关闭结果集（rows.Close）时返回的错误；默认该错误会被记录到 db.Error，
+	// 因为关闭时才暴露的延迟错误（如网络中断）可能意味着读取到的结果不完整。
+	IgnoreRowsCloseError bool
+
 	// TranslateError enabling error translation
 	// TranslateError 启用数据库错误转换，例如将数据库唯一键冲突错误转换为更易理解的错误类型。
 	TranslateError bool
 
+	// ErrorCodeMap translate errors with the code to the mapped error, consulted before the dialector translator,
+	// the code is extracted from driver errors implement `interface{ Code() string }`
+	// ErrorCodeMap 将驱动错误码（SQLSTATE 或厂商错误码）映射为指定错误，优先于方言的错误转换，
+	// 错误码通过驱动错误实现的 `interface{ Code() string }` 获取。
+	ErrorCodeMap map[string]error
+
 	// PropagateUnscoped propagate Unscoped to every other nested statement
 	// PropagateUnscoped 当使用 Unscoped 时，是否将其传递给所有嵌套语句。
 	// 默认只对当前语句生效。设置为 true 可以使其全局生效。
@@ -159,6 +346,19 @@ func (c *Config) AfterInitialize(db *DB) error {
 	return nil
 }
 
+// AmbiguousColumnStrategy strategy of scanning duplicate result column names
+type AmbiguousColumnStrategy int
+
+const (
+	// AmbiguousColumnLastWins the value of the last duplicate column is kept
+	AmbiguousColumnLastWins AmbiguousColumnStrategy = iota
+	// AmbiguousColumnError scanning fails with ErrAmbiguousColumn
+	AmbiguousColumnError
+	// AmbiguousColumnPrefixWithTable queries with joins alias the current table's columns like SelectQualified,
+	// remaining duplicates fail with ErrAmbiguousColumn
+	AmbiguousColumnPrefixWithTable
+)
+
 // Option gorm option interface
 type Option interface {
 	Apply(*Config) error
@@ -185,6 +385,7 @@ type DB struct {
 type Session struct {
 	DryRun                   bool
 	PrepareStmt              bool
+	SkipPrepare              bool
 	NewDB                    bool
 	Initialized              bool
 	SkipHooks                bool
@@ -193,11 +394,24 @@ type Session struct {
 	AllowGlobalUpdate        bool
 	FullSaveAssociations     bool
 	PropagateUnscoped        bool
+	Unscoped                 bool
 	QueryFields              bool
+	AutoGroupBy              bool
 	Context                  context.Context
 	Logger                   logger.Interface
 	NowFunc                  func() time.Time
+	QuoteTo                  func(writer clause.Writer, str string) bool
 	CreateBatchSize          int
+	PreloadBatchSize         int
+	BatchTransaction         bool
+	// DisableBatching creates one row per statement, overriding CreateBatchSize, e.g: for tables with triggers misbehaving
+	// under multi-row inserts, CreateInBatches with an explicit batch size is not affected
+	DisableBatching bool
+	// UseWriter forces statements of the session onto the writer connection even for SELECT, see DB.WriteDB
+	UseWriter bool
+	// SnapshotID imports the exported snapshot (pg_export_snapshot) at the start of transactions of the session,
+	// Postgres only, the isolation must be REPEATABLE READ (default) or SERIALIZABLE
+	SnapshotID string
 }
 
 // Open initialize db session based on dialector
@@ -241,6 +455,11 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 		}
 	}
 
+	// prepared statements acquire connections inside database/sql, the timeout can't be applied to them
+	if config.ConnAcquireTimeout > 0 && config.PrepareStmt {
+		return nil, fmt.Errorf("%w: ConnAcquireTimeout can't be used with PrepareStmt", ErrNotImplemented)
+	}
+
 	// 表、列命名策略
 	if config.NamingStrategy == nil {
 		config.NamingStrategy = schema.NamingStrategy{IdentifierMaxLength: 64} // Default Identifier length is 64
@@ -297,9 +516,14 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 		}
 	}
 
+	if sqlDB, ok := db.ConnPool.(*sql.DB); ok && config.ConnAcquireTimeout > 0 {
+		db.ConnPool = &connAcquirePool{DB: sqlDB, timeout: config.ConnAcquireTimeout}
+	}
+
 	// 是否启用 prepare 模式
 	if config.PrepareStmt {
-		preparedStmt := NewPreparedStmtDB(db.ConnPool, config.PrepareStmtMaxSize, config.PrepareStmtTTL)
+		preparedStmt := NewPreparedStmtDBWithMaxBytes(db.ConnPool, config.PrepareStmtMaxSize, config.PrepareStmtTTL, config.PrepareStmtMaxBytes)
+		preparedStmt.StrictTxStmtReuse = config.StrictTxStmtReuse
 		db.cacheStore.Store(preparedStmtDBKey, preparedStmt)
 		db.ConnPool = preparedStmt
 	}
@@ -314,8 +538,27 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 
 	// 倘若未禁用 AutomaticPing
 	if err == nil && !config.DisableAutomaticPing {
-		if pinger, ok := db.ConnPool.(interface{ Ping() error }); ok {
-			err = pinger.Ping()
+		if err = db.automaticPing(); err != nil {
+			if sqlDB, _ := db.DB(); sqlDB != nil {
+				_ = sqlDB.Close()
+			}
+		}
+	}
+
+	if err == nil && config.WarmupConns > 0 {
+		timeout := config.AutomaticPingTimeout
+		if timeout <= 0 {
+			timeout = defaultWarmupTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err = db.warmup(ctx, config.WarmupConns)
+		cancel()
+
+		if err != nil {
+			if sqlDB, _ := db.DB(); sqlDB != nil {
+				_ = sqlDB.Close()
+			}
 		}
 	}
 
@@ -326,6 +569,58 @@ func Open(dialector Dialector, opts ...Option) (db *DB, err error) {
 	return
 }
 
+// automaticPing pings the database when initializing, limited by Config.AutomaticPingTimeout
+func (db *DB) automaticPing() error {
+	if pinger, ok := db.ConnPool.(interface {
+		PingContext(ctx context.Context) error
+	}); ok && db.AutomaticPingTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), db.AutomaticPingTimeout)
+		defer cancel()
+
+		if err := pinger.PingContext(ctx); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w after %v: %v", ErrPingTimeout, db.AutomaticPingTimeout, err)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if pinger, ok := db.ConnPool.(interface{ Ping() error }); ok {
+		return pinger.Ping()
+	}
+	return nil
+}
+
+// warmup opens n connections, limited by MaxOpenConns, and returns them to the pool,
+// only up to MaxIdleConns of them are kept idle
+func (db *DB) warmup(ctx context.Context, n int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if maxOpen := sqlDB.Stats().MaxOpenConnections; maxOpen > 0 && n > maxOpen {
+		n = maxOpen
+	}
+
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+	}
+	return nil
+}
+
 // Session create new db session
 func (db *DB) Session(config *Session) *DB {
 	var (
@@ -341,6 +636,18 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Config.CreateBatchSize = config.CreateBatchSize
 	}
 
+	if config.DisableBatching {
+		tx.Config.CreateBatchSize = 1
+	}
+
+	if config.PreloadBatchSize > 0 {
+		tx.Config.PreloadBatchSize = config.PreloadBatchSize
+	}
+
+	if config.BatchTransaction {
+		tx.Config.BatchTransaction = true
+	}
+
 	if config.SkipDefaultTransaction {
 		tx.Config.SkipDefaultTransaction = true
 	}
@@ -357,7 +664,12 @@ func (db *DB) Session(config *Session) *DB {
 		txConfig.PropagateUnscoped = true
 	}
 
-	if config.Context != nil || config.PrepareStmt || config.SkipHooks {
+	// Unscoped applies to every statement of the session, including nested ones
+	if config.Unscoped {
+		txConfig.PropagateUnscoped = true
+	}
+
+	if config.Context != nil || config.PrepareStmt || config.SkipPrepare || config.SkipHooks || config.Unscoped || config.SnapshotID != "" || config.UseWriter {
 		tx.Statement = tx.Statement.clone()
 		tx.Statement.DB = tx
 	}
@@ -366,13 +678,16 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Statement.Context = config.Context
 	}
 
-	if config.PrepareStmt {
+	if config.PrepareStmt && db.ConnAcquireTimeout > 0 {
+		tx.AddError(fmt.Errorf("%w: ConnAcquireTimeout can't be used with PrepareStmt", ErrNotImplemented))
+	} else if config.PrepareStmt {
 		var preparedStmt *PreparedStmtDB
 
 		if v, ok := db.cacheStore.Load(preparedStmtDBKey); ok {
 			preparedStmt = v.(*PreparedStmtDB)
 		} else {
-			preparedStmt = NewPreparedStmtDB(db.ConnPool, db.PrepareStmtMaxSize, db.PrepareStmtTTL)
+			preparedStmt = NewPreparedStmtDBWithMaxBytes(db.ConnPool, db.PrepareStmtMaxSize, db.PrepareStmtTTL, db.PrepareStmtMaxBytes)
+			preparedStmt.StrictTxStmtReuse = db.StrictTxStmtReuse
 			db.cacheStore.Store(preparedStmtDBKey, preparedStmt)
 		}
 
@@ -384,19 +699,45 @@ func (db *DB) Session(config *Session) *DB {
 			}
 		default:
 			tx.Statement.ConnPool = &PreparedStmtDB{
-				ConnPool: db.Config.ConnPool,
-				Mux:      preparedStmt.Mux,
-				Stmts:    preparedStmt.Stmts,
+				ConnPool:          db.Config.ConnPool,
+				Mux:               preparedStmt.Mux,
+				Stmts:             preparedStmt.Stmts,
+				StrictTxStmtReuse: db.StrictTxStmtReuse,
+				counters:          preparedStmt.counters,
 			}
 		}
 		txConfig.ConnPool = tx.Statement.ConnPool
 		txConfig.PrepareStmt = true
 	}
 
+	// bypass the prepared statement manager, statements are executed with the underlying connection or transaction
+	if config.SkipPrepare {
+		switch t := tx.Statement.ConnPool.(type) {
+		case *PreparedStmtTX:
+			tx.Statement.ConnPool = t.Tx
+		case *PreparedStmtDB:
+			tx.Statement.ConnPool = t.ConnPool
+		}
+		txConfig.ConnPool = tx.Statement.ConnPool
+		txConfig.PrepareStmt = false
+	}
+
 	if config.SkipHooks {
 		tx.Statement.SkipHooks = true
 	}
 
+	if config.SnapshotID != "" {
+		tx.Statement.Settings.Store("gorm:snapshot_id", config.SnapshotID)
+	}
+
+	if config.UseWriter {
+		tx.Statement.Settings.Store("gorm:use_writer", true)
+	}
+
+	if config.Unscoped {
+		tx.Statement.Unscoped = true
+	}
+
 	if config.DisableNestedTransaction {
 		txConfig.DisableNestedTransaction = true
 	}
@@ -413,6 +754,10 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Config.QueryFields = true
 	}
 
+	if config.AutoGroupBy {
+		tx.Config.AutoGroupBy = true
+	}
+
 	if config.Logger != nil {
 		tx.Config.Logger = config.Logger
 	}
@@ -421,6 +766,10 @@ func (db *DB) Session(config *Session) *DB {
 		tx.Config.NowFunc = config.NowFunc
 	}
 
+	if config.QuoteTo != nil {
+		tx.Config.QuoteTo = config.QuoteTo
+	}
+
 	if config.Initialized {
 		tx = tx.getInstance()
 	}
@@ -433,6 +782,52 @@ func (db *DB) WithContext(ctx context.Context) *DB {
 	return db.Session(&Session{Context: ctx})
 }
 
+// SkipPrepare executes the statements without preparing and caching them even if PrepareStmt is enabled,
+// useful for one-off queries with variable SQL that would pollute the prepared statement cache
+func (db *DB) SkipPrepare() *DB {
+	return db.Session(&Session{SkipPrepare: true})
+}
+
+// Fork returns a new DB without the conditions of the current chain, it keeps the context and the connection (e.g: a transaction)
+// but owns its Statement and Config, nothing mutable is shared with db.
+//
+// A chained *DB is not safe for concurrent use, fork it for each goroutine instead:
+//
+//	for _, id := range ids {
+//		go func(tx *gorm.DB, id uint) {
+//			tx.Where("user_id = ?", id).Find(&pets)
+//		}(db.Fork(), id)
+//	}
+//
+// The forked DB could be reused like a new session, every chain starts from a new Statement.
+// Note most drivers don't support concurrent queries on a transaction connection.
+func (db *DB) Fork() *DB {
+	tx := db.Session(&Session{NewDB: true}).getInstance()
+	tx.clone = 1
+	return tx
+}
+
+// Clone returns a new DB with a copy of the current chain, it keeps the context, the connection and the clauses of db
+// but owns its Statement and Config, nothing mutable is shared with db.
+//
+// Every chain starts from a copy of the cloned Statement, so a clone could be shared by the goroutines of a worker pool:
+//
+//	base := db.Model(&User{}).Where("active = ?", true).Clone()
+//	for _, id := range ids {
+//		go func(id uint) {
+//			base.Where("team_id = ?", id).Find(&users)
+//		}(id)
+//	}
+//
+// Unlike Fork, the conditions of the current chain are kept.
+func (db *DB) Clone() *DB {
+	txConfig := *db.Config
+	tx := &DB{Config: &txConfig, Error: db.Error, clone: 2}
+	tx.Statement = db.Statement.clone()
+	tx.Statement.DB = tx
+	return tx
+}
+
 // Debug start debug mode
 func (db *DB) Debug() (tx *DB) {
 	tx = db.getInstance()
@@ -475,7 +870,9 @@ func (db *DB) Callback() *callbacks {
 // 一次会话在执行过程中可能会遇到多个错误，因此会通过 error wrapping 的方式，实现错误的拼接.
 func (db *DB) AddError(err error) error {
 	if err != nil {
-		if db.Config.TranslateError {
+		if mapped, ok := db.mapErrorCode(err); ok {
+			err = mapped
+		} else if db.Config.TranslateError {
 			if errTranslator, ok := db.Dialector.(ErrorTranslator); ok {
 				err = errTranslator.Translate(err)
 			}
@@ -483,13 +880,76 @@ func (db *DB) AddError(err error) error {
 
 		if db.Error == nil {
 			db.Error = err
-		} else {
+		} else if !errors.Is(db.Error, err) {
+			// errors already in the chain are skipped, e.g: context.Canceled reported by every remaining callback
 			db.Error = fmt.Errorf("%v; %w", db.Error, err)
 		}
 	}
 	return db.Error
 }
 
+// withQueryTimeout derives the statement context with Config.DefaultQueryTimeout,
+// the returned function cancels the derived context and restores the original one
+func (db *DB) withQueryTimeout() func() {
+	stmt := db.Statement
+	if db.DefaultQueryTimeout <= 0 {
+		return func() {}
+	}
+
+	ctx, parent := stmt.Context, stmt.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	var cancel context.CancelFunc
+	stmt.Context, cancel = context.WithTimeout(parent, db.DefaultQueryTimeout)
+	return func() {
+		cancel()
+		stmt.Context = ctx
+	}
+}
+
+// addRowsCloseError adds the error returned when closing rows unless Config.IgnoreRowsCloseError
+func (db *DB) addRowsCloseError(err error) {
+	if !db.IgnoreRowsCloseError {
+		db.AddError(err)
+	}
+}
+
+// closeRows closes rows and reports the close error to err if no error happened before,
+// it's used by the finisher methods returning the error directly
+func (db *DB) closeRows(rows *sql.Rows, err *error) {
+	if closeErr := rows.Close(); closeErr != nil && *err == nil && !db.IgnoreRowsCloseError {
+		*err = closeErr
+	}
+	releaseRows(db.Statement.ConnPool, rows)
+}
+
+// releaseRows returns the connection pinned for the closed rows to the pool, e.g: the one acquired with ConnAcquireTimeout
+func releaseRows(connPool ConnPool, rows *sql.Rows) {
+	if releaser, ok := connPool.(interface{ ReleaseRows(*sql.Rows) }); ok {
+		releaser.ReleaseRows(rows)
+	}
+}
+
+// Abort skips the remaining callbacks of the current operation including the database execution without an error,
+// e.g. a read-through cache fills the dest on a hit, the transaction and local settings are still finalized
+// Abort 在 callback 中调用，跳过当前操作剩余的 callbacks（包括实际的数据库执行）且不设置 db.Error，
+// 默认事务的提交/回滚以及本地设置的重置仍会执行。
+func (db *DB) Abort() {
+	db.Statement.aborted = true
+}
+
+// mapErrorCode returns the error mapped by Config.ErrorCodeMap with the code of err
+func (db *DB) mapErrorCode(err error) (error, bool) {
+	var coder interface{ Code() string }
+	if len(db.Config.ErrorCodeMap) > 0 && errors.As(err, &coder) {
+		mapped, ok := db.Config.ErrorCodeMap[coder.Code()]
+		return mapped, ok
+	}
+	return nil, false
+}
+
 // DB returns `*sql.DB`
 func (db *DB) DB() (*sql.DB, error) {
 	connPool := db.ConnPool
@@ -513,6 +973,16 @@ func (db *DB) DB() (*sql.DB, error) {
 	return nil, ErrInvalidDB
 }
 
+// PingContext verifies the connection to the database is still alive with ctx, e.g: use a context with deadline
+// to avoid blocking on a hung network connection
+func (db *DB) PingContext(ctx context.Context) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 func (db *DB) getInstance() *DB {
 	if db.clone > 0 {
 		tx := &DB{Config: db.Config, Error: db.Error}
@@ -531,6 +1001,10 @@ func (db *DB) getInstance() *DB {
 			if db.Config.PropagateUnscoped {
 				tx.Statement.Unscoped = db.Statement.Unscoped
 			}
+			// the registered AfterCommit functions belong to the transaction, not the statement
+			if hooks, ok := db.Statement.Settings.Load(afterCommitHooksKey); ok {
+				tx.Statement.Settings.Store(afterCommitHooksKey, hooks)
+			}
 		} else {
 			// with clone statement
 			// 倘若已经 db clone 过了，则还需要 clone 原先的 statement
@@ -549,6 +1023,14 @@ func Expr(expr string, args ...interface{}) clause.Expr {
 	return clause.Expr{SQL: expr, Vars: args}
 }
 
+// NamedExpr returns clause.StrictNamedExpr with @name placeholders, args could be sql.NamedArg, map[string]interface{} or structs,
+// a name used repeatedly binds the same value, a name not found in args fails building the statement, e.g:
+//
+//	db.Where(gorm.NamedExpr("amount > @min AND amount < @max", map[string]interface{}{"min": 1, "max": 10})).Find(&orders)
+func NamedExpr(expr string, args ...interface{}) clause.StrictNamedExpr {
+	return clause.StrictNamedExpr{SQL: expr, Vars: args}
+}
+
 // SetupJoinTable setup join table schema
 func (db *DB) SetupJoinTable(model interface{}, field string, joinTable interface{}) error {
 	var (
@@ -600,12 +1082,19 @@ func (db *DB) SetupJoinTable(model interface{}, field string, joinTable interfac
 	return nil
 }
 
-// Use use plugin
+// Use use plugin, the plugins it requires (see PluginRequirer) must be registered already
 func (db *DB) Use(plugin Plugin) error {
 	name := plugin.Name()
 	if _, ok := db.Plugins[name]; ok {
 		return ErrRegistered
 	}
+	if requirer, ok := plugin.(PluginRequirer); ok {
+		for _, required := range requirer.Requires() {
+			if _, ok := db.Plugins[required]; !ok {
+				return fmt.Errorf("%w: plugin %s requires %s", ErrPluginNotRegistered, name, required)
+			}
+		}
+	}
 	if err := plugin.Initialize(db); err != nil {
 		return err
 	}
@@ -613,6 +1102,73 @@ func (db *DB) Use(plugin Plugin) error {
 	return nil
 }
 
+// UseAll use plugins, they are initialized after the plugins they require (see PluginRequirer), otherwise in the given order
+//
+//	db.UseAll(auditPlugin, tenantPlugin) // tenantPlugin is initialized first if auditPlugin requires it
+func (db *DB) UseAll(plugins ...Plugin) error {
+	pending := make(map[string]Plugin, len(plugins))
+	for _, plugin := range plugins {
+		name := plugin.Name()
+		if _, ok := pending[name]; ok {
+			return fmt.Errorf("%w: plugin %s", ErrRegistered, name)
+		}
+		if _, ok := db.Plugins[name]; ok {
+			return fmt.Errorf("%w: plugin %s", ErrRegistered, name)
+		}
+		pending[name] = plugin
+	}
+
+	var (
+		sorted   = make([]Plugin, 0, len(plugins))
+		visited  = map[string]bool{}
+		visiting []string
+		visit    func(plugin Plugin) error
+	)
+
+	visit = func(plugin Plugin) error {
+		name := plugin.Name()
+		for idx, n := range visiting {
+			if n == name {
+				return fmt.Errorf("%w: %s", ErrPluginDependencyCycle, strings.Join(append(visiting[idx:], name), " -> "))
+			}
+		}
+		if visited[name] {
+			return nil
+		}
+
+		visiting = append(visiting, name)
+		if requirer, ok := plugin.(PluginRequirer); ok {
+			for _, required := range requirer.Requires() {
+				if p, ok := pending[required]; ok {
+					if err := visit(p); err != nil {
+						return err
+					}
+				} else if _, ok := db.Plugins[required]; !ok {
+					return fmt.Errorf("%w: plugin %s requires %s", ErrPluginNotRegistered, name, required)
+				}
+			}
+		}
+		visiting = visiting[:len(visiting)-1]
+
+		visited[name] = true
+		sorted = append(sorted, plugin)
+		return nil
+	}
+
+	for _, plugin := range plugins {
+		if err := visit(plugin); err != nil {
+			return err
+		}
+	}
+
+	for _, plugin := range sorted {
+		if err := db.Use(plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ToSQL for generate SQL string.
 //
 //	db.ToSQL(func(tx *gorm.DB) *gorm.DB {
@@ -627,3 +1183,263 @@ func (db *DB) ToSQL(queryFn func(tx *DB) *DB) string {
 
 	return db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
 }
+
+// BuildSQL generates the SQL like ToSQL, but returns the parameterized SQL and its vars separately without interpolating,
+// the returned vars is a copy that can be used safely
+//
+//	sql, vars, err := db.BuildSQL(func(tx *gorm.DB) *gorm.DB {
+//		return tx.Model(&User{}).Where("name = ?", "jinzhu").Find(&[]User{})
+//	})
+func (db *DB) BuildSQL(queryFn func(tx *DB) *DB) (sql string, vars []interface{}, err error) {
+	stmt, err := db.dryRunStatement(queryFn)
+	if err != nil {
+		return "", nil, err
+	}
+	return stmt.SQL.String(), append([]interface{}(nil), stmt.Vars...), nil
+}
+
+// dryRunStatement builds the statement of queryFn in dry run mode without executing it
+func (db *DB) dryRunStatement(queryFn func(tx *DB) *DB) (*Statement, error) {
+	tx := queryFn(db.Session(&Session{DryRun: true, SkipDefaultTransaction: true}).getInstance())
+	if tx == nil {
+		return nil, ErrInvalidDB
+	}
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx.Statement, nil
+}
+
+// ExplainResult the statement built by DB.Explain without executing it
+type ExplainResult struct {
+	SQL   string
+	Vars  []interface{}
+	Table string
+	// Operation the kind of the SQL, one of SELECT, INSERT, UPDATE and DELETE, empty for raw SQL
+	Operation string
+	// Clauses the clauses set on the statement, e.g: check Clauses["WHERE"] to know whether the statement has conditions,
+	// it's a snapshot safe to retain after queryFn returns, expressions of built-in clauses are copied with their slices
+	Clauses map[string]clause.Clause
+}
+
+// Explain builds the statement of queryFn like ToSQL in dry run mode, and returns its SQL, vars and clauses
+//
+//	result, err := db.Explain(func(tx *gorm.DB) *gorm.DB {
+//		return tx.Model(&User{}).Where("name = ?", "jinzhu").Update("age", 20)
+//	})
+//	// result.Operation: UPDATE, result.Table: users
+func (db *DB) Explain(queryFn func(tx *DB) *DB) (*ExplainResult, error) {
+	stmt, err := db.dryRunStatement(queryFn)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExplainResult{
+		SQL:       stmt.SQL.String(),
+		Vars:      append([]interface{}(nil), stmt.Vars...),
+		Table:     stmt.Table,
+		Operation: stmt.operation(),
+		Clauses:   make(map[string]clause.Clause, len(stmt.Clauses)),
+	}
+	for name, c := range stmt.Clauses {
+		c.BeforeExpression = copyExpression(c.BeforeExpression)
+		c.AfterNameExpression = copyExpression(c.AfterNameExpression)
+		c.AfterExpression = copyExpression(c.AfterExpression)
+		c.Expression = copyExpression(c.Expression)
+		result.Clauses[name] = c
+	}
+	return result, nil
+}
+
+// copyExpression copies the expression of built-in clauses recursively with their slices, so modifying the statement
+// doesn't change the copy, values referenced by vars and custom expressions are shared
+func copyExpression(expr clause.Expression) clause.Expression {
+	switch v := expr.(type) {
+	case clause.Where:
+		return clause.Where{Exprs: copyExpressions(v.Exprs)}
+	case clause.AndConditions:
+		return clause.AndConditions{Exprs: copyExpressions(v.Exprs)}
+	case clause.OrConditions:
+		return clause.OrConditions{Exprs: copyExpressions(v.Exprs)}
+	case clause.NotConditions:
+		return clause.NotConditions{Exprs: copyExpressions(v.Exprs)}
+	case clause.CommaExpression:
+		return clause.CommaExpression{Exprs: copyExpressions(v.Exprs)}
+	case clause.Select:
+		v.DistinctOn = append([]clause.Column(nil), v.DistinctOn...)
+		v.Columns = append([]clause.Column(nil), v.Columns...)
+		v.Expression = copyExpression(v.Expression)
+		return v
+	case clause.OrderBy:
+		v.Columns = append([]clause.OrderByColumn(nil), v.Columns...)
+		v.Expression = copyExpression(v.Expression)
+		return v
+	case clause.GroupBy:
+		return clause.GroupBy{Columns: append([]clause.Column(nil), v.Columns...), Having: copyExpressions(v.Having)}
+	case clause.Set:
+		return append(clause.Set(nil), v...)
+	case clause.Values:
+		return clause.Values{Columns: append([]clause.Column(nil), v.Columns...), Values: copyValues(v.Values)}
+	case clause.Returning:
+		return clause.Returning{Columns: append([]clause.Column(nil), v.Columns...)}
+	case clause.From:
+		v.Tables = append([]clause.Table(nil), v.Tables...)
+		joins := make([]clause.Join, len(v.Joins))
+		for idx, join := range v.Joins {
+			join.ON = clause.Where{Exprs: copyExpressions(join.ON.Exprs)}
+			join.Using = append([]string(nil), join.Using...)
+			join.Expression = copyExpression(join.Expression)
+			joins[idx] = join
+		}
+		v.Joins = joins
+		return v
+	case clause.Limit:
+		if v.Limit != nil {
+			limit := *v.Limit
+			v.Limit = &limit
+		}
+		return v
+	case clause.OnConflict:
+		v.Columns = append([]clause.Column(nil), v.Columns...)
+		v.TargetExprs = copyExpressions(v.TargetExprs)
+		v.Where = clause.Where{Exprs: copyExpressions(v.Where.Exprs)}
+		v.TargetWhere = clause.Where{Exprs: copyExpressions(v.TargetWhere.Exprs)}
+		v.DoUpdates = append(clause.Set(nil), v.DoUpdates...)
+		return v
+	case clause.With:
+		ctes := make([]clause.CTE, len(v.CTEs))
+		for idx, cte := range v.CTEs {
+			cte.Expression = copyExpression(cte.Expression)
+			ctes[idx] = cte
+		}
+		return clause.With{CTEs: ctes}
+	case clause.Expr:
+		v.Vars = append([]interface{}(nil), v.Vars...)
+		return v
+	case clause.NamedExpr:
+		v.Vars = append([]interface{}(nil), v.Vars...)
+		return v
+	case clause.StrictNamedExpr:
+		v.Vars = append([]interface{}(nil), v.Vars...)
+		return v
+	case clause.IN:
+		v.Values = append([]interface{}(nil), v.Values...)
+		return v
+	case clause.TupleIN:
+		return clause.TupleIN{Columns: append([]clause.Column(nil), v.Columns...), Values: copyValues(v.Values)}
+	default:
+		return expr
+	}
+}
+
+func copyExpressions(exprs []clause.Expression) []clause.Expression {
+	if exprs == nil {
+		return nil
+	}
+
+	copied := make([]clause.Expression, len(exprs))
+	for idx, expr := range exprs {
+		copied[idx] = copyExpression(expr)
+	}
+	return copied
+}
+
+func copyValues(values [][]interface{}) [][]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	copied := make([][]interface{}, len(values))
+	for idx, row := range values {
+		copied[idx] = append([]interface{}(nil), row...)
+	}
+	return copied
+}
+
+// ExplainAnalyzePrefixes statement prefixes of ExplainAnalyze for dialects, e.g: use "ANALYZE " for MariaDB
+var ExplainAnalyzePrefixes = map[string]string{"postgres": "EXPLAIN ANALYZE ", "mysql": "EXPLAIN ANALYZE "}
+
+// errExplainAnalyzeRollback rolls back the transaction of ExplainAnalyze
+var errExplainAnalyzeRollback = errors.New("rollback explain analyze")
+
+// ExplainAnalyze runs the query built by queryFn with EXPLAIN ANALYZE and returns the plan with actual timings.
+//
+// NOTE: the query is EXECUTED, including its side effects, it runs in a transaction (or a savepoint of the current
+// transaction) that's always rolled back, side effects can't be undone if nested transaction is disabled or for
+// statements outside of the transaction, e.g: sequences or DDL of MySQL
+//
+//	plan, err := db.ExplainAnalyze(func(tx *gorm.DB) *gorm.DB {
+//		return tx.Model(&User{}).Where("name = ?", "jinzhu").Update("age", 20)
+//	})
+func (db *DB) ExplainAnalyze(queryFn func(tx *DB) *DB) (plan string, err error) {
+	prefix, ok := ExplainAnalyzePrefixes[db.Dialector.Name()]
+	if !ok {
+		return "", fmt.Errorf("%w: explain analyze isn't supported by %s", ErrUnsupportedDriver, db.Dialector.Name())
+	}
+
+	stmt, err := db.dryRunStatement(queryFn)
+	if err != nil {
+		return "", err
+	}
+
+	query, vars := stmt.SQL.String(), stmt.Vars
+	err = db.Transaction(func(tx *DB) error {
+		rows, err := tx.Statement.ConnPool.QueryContext(tx.Statement.Context, prefix+query, vars...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		if plan, err = ScanPlan(rows); err != nil {
+			return err
+		}
+		return errExplainAnalyzeRollback
+	})
+
+	if errors.Is(err, errExplainAnalyzeRollback) {
+		err = nil
+	}
+	return plan, err
+}
+
+// ScanPlan reads rows of EXPLAIN as text, columns are separated by " | " and rows by new lines
+func ScanPlan(rows Rows) (string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		plan   strings.Builder
+		values = make([]interface{}, len(columns))
+	)
+	for rows.Next() {
+		for idx := range values {
+			values[idx] = new(interface{})
+		}
+
+		if err := rows.Scan(values...); err != nil {
+			return "", err
+		}
+
+		if plan.Len() > 0 {
+			plan.WriteByte('\n')
+		}
+
+		for idx, value := range values {
+			if idx > 0 {
+				plan.WriteString(" | ")
+			}
+
+			switch v := (*value.(*interface{})).(type) {
+			case []byte:
+				plan.Write(v)
+			case nil:
+				plan.WriteString("NULL")
+			default:
+				plan.WriteString(fmt.Sprint(v))
+			}
+		}
+	}
+	return plan.String(), rows.Err()
+}
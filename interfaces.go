@@ -26,6 +26,11 @@ type Plugin interface {
 	Initialize(*DB) error
 }
 
+// PluginRequirer plugin declares the names of the plugins it requires, which must be initialized before it
+type PluginRequirer interface {
+	Requires() []string
+}
+
 type ParamsFilter interface {
 	ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{})
 }
@@ -54,6 +59,12 @@ type ConnPoolBeginner interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (ConnPool, error)
 }
 
+// WriterSelector implemented by ConnPool routing statements between readers and the writer (e.g: a resolver),
+// statements with DB.WriteDB or Session.UseWriter are executed on the ConnPool returned by Writer
+type WriterSelector interface {
+	Writer() ConnPool
+}
+
 // TxCommitter tx committer
 type TxCommitter interface {
 	Commit() error
@@ -10,12 +10,22 @@ import (
 // EvictCallback is used to get a callback when a cache entry is evicted
 type EvictCallback[K comparable, V any] func(key K, value V)
 
+// CostFunc is used to get the cost of a cache entry
+type CostFunc[K comparable, V any] func(key K, value V) int64
+
 // LRU implements a thread-safe LRU with expirable entries.
 type LRU[K comparable, V any] struct {
 	size      int
 	evictList *LruList[K, V]
 	items     map[K]*Entry[K, V]
 	onEvict   EvictCallback[K, V]
+	// onExpire is called for entries evicted by the cache itself, e.g. exceeded size, cost or expired
+	onExpire EvictCallback[K, V]
+
+	// cost options, entries are evicted when the total cost exceeds maxCost
+	maxCost  int64
+	cost     int64
+	costFunc CostFunc[K, V]
 
 	// expirable options
 	mu   sync.Mutex
@@ -103,6 +113,7 @@ func (c *LRU[K, V]) Purge() {
 		}
 		delete(c.items, k)
 	}
+	c.cost = 0
 	for _, b := range c.buckets {
 		for _, ent := range b.entries {
 			delete(b.entries, ent.Key)
@@ -126,20 +137,50 @@ func (c *LRU[K, V]) Add(key K, value V) (evicted bool) {
 		ent.Value = value
 		ent.ExpiresAt = now.Add(c.ttl)
 		c.addToBucket(ent)
-		return false
+		c.setCost(ent)
+		return c.evictOverCost()
 	}
 
 	// Add new item
 	ent := c.evictList.PushFrontExpirable(key, value, now.Add(c.ttl))
 	c.items[key] = ent
 	c.addToBucket(ent) // adds the entry to the appropriate bucket and sets entry.expireBucket
+	c.setCost(ent)
 
 	evict := c.size > 0 && c.evictList.Length() > c.size
 	// Verify size not exceeded
 	if evict {
 		c.removeOldest()
 	}
-	return evict
+	return c.evictOverCost() || evict
+}
+
+// SetMaxCost sets the cost budget of the cache, the oldest entries are evicted when the total cost
+// exceeds maxCost, the newest entry is always kept. maxCost of 0 means unlimited.
+func (c *LRU[K, V]) SetMaxCost(maxCost int64, costFunc CostFunc[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxCost, c.costFunc = maxCost, costFunc
+	c.cost = 0
+	for _, ent := range c.items {
+		c.setCost(ent)
+	}
+	c.evictOverCost()
+}
+
+// SetExpireCallback sets the callback of entries evicted by the cache itself because of exceeded size, cost or
+// expiration, it's not called for Remove or Purge and is called with the lock held.
+func (c *LRU[K, V]) SetExpireCallback(onExpire EvictCallback[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onExpire = onExpire
+}
+
+// Cost returns the total cost of the entries in the cache.
+func (c *LRU[K, V]) Cost() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cost
 }
 
 // Get looks up a key's value from the cache.
@@ -301,10 +342,37 @@ func (c *LRU[K, V]) Resize(size int) (evicted int) {
 //	close(c.done)
 // }
 
+// setCost updates the cost of the entry and the total cost. Has to be called with lock!
+func (c *LRU[K, V]) setCost(e *Entry[K, V]) {
+	if c.costFunc == nil {
+		return
+	}
+	c.cost -= e.cost
+	e.cost = c.costFunc(e.Key, e.Value)
+	c.cost += e.cost
+}
+
+// evictOverCost removes the oldest items until the total cost fits the budget. Has to be called with lock!
+func (c *LRU[K, V]) evictOverCost() (evicted bool) {
+	for c.maxCost > 0 && c.cost > c.maxCost && c.evictList.Length() > 1 {
+		c.removeOldest()
+		evicted = true
+	}
+	return evicted
+}
+
 // removeOldest removes the oldest item from the cache. Has to be called with lock!
 func (c *LRU[K, V]) removeOldest() {
 	if ent := c.evictList.Back(); ent != nil {
 		c.removeElement(ent)
+		c.expired(ent)
+	}
+}
+
+// expired calls onExpire for the entry evicted by the cache. Has to be called with lock!
+func (c *LRU[K, V]) expired(e *Entry[K, V]) {
+	if c.onExpire != nil {
+		c.onExpire(e.Key, e.Value)
 	}
 }
 
@@ -313,6 +381,7 @@ func (c *LRU[K, V]) removeElement(e *Entry[K, V]) {
 	c.evictList.Remove(e)
 	delete(c.items, e.Key)
 	c.removeFromBucket(e)
+	c.cost -= e.cost
 	if c.onEvict != nil {
 		c.onEvict(e.Key, e.Value)
 	}
@@ -332,6 +401,7 @@ func (c *LRU[K, V]) deleteExpired() {
 	}
 	for _, ent := range c.buckets[bucketIdx].entries {
 		c.removeElement(ent)
+		c.expired(ent)
 	}
 	c.nextCleanupBucket = (c.nextCleanupBucket + 1) % numBuckets
 	c.mu.Unlock()
@@ -380,6 +450,9 @@ type Entry[K comparable, V any] struct {
 
 	// The expiry bucket item was put in, optional
 	ExpireBucket uint8
+
+	// The cost of this element, optional
+	cost int64
 }
 
 // PrevEntry returns the previous list element or nil.
@@ -18,19 +18,98 @@ type Stmt struct {
 	// 标识当前 stmt 是否已初始化完成
 	prepared   chan struct{}
 	prepareErr error
+	// SQL 模板占用的字节数，用于限制缓存的内存占用
+	bytes int64
+	// SQL 模板
+	query string
+	// 正在执行的语句数，关闭时等待其归零后再关闭底层 statement
+	mux      sync.Mutex
+	inflight int
+	closing  bool
+	drained  chan struct{}
+	// 底层 statement 只关闭一次
+	closeOnce sync.Once
+	closeErr  error
 }
 
 func (stmt *Stmt) Error() error {
 	return stmt.prepareErr
 }
 
+// Acquire marks the statement in use by an execution, Close waits until it's released,
+// returns false if the statement is being closed, then it shouldn't be used
+func (stmt *Stmt) Acquire() bool {
+	stmt.mux.Lock()
+	defer stmt.mux.Unlock()
+	if stmt.closing {
+		return false
+	}
+	stmt.inflight++
+	return true
+}
+
+// Release marks an execution of the statement finished, the statement is closed by the last release after closing
+func (stmt *Stmt) Release() {
+	stmt.mux.Lock()
+	stmt.inflight--
+	drained := stmt.closing && stmt.inflight <= 0
+	stmt.mux.Unlock()
+
+	if drained {
+		stmt.closeStmt()
+		close(stmt.drained)
+	}
+}
+
+// closeStmt closes the underlying statement once
+func (stmt *Stmt) closeStmt() error {
+	stmt.closeOnce.Do(func() {
+		if stmt.Stmt != nil {
+			stmt.closeErr = stmt.Stmt.Close()
+		}
+	})
+	return stmt.closeErr
+}
+
+// Close closes the statement after the in-flight executions are released
 func (stmt *Stmt) Close() error {
+	return stmt.CloseContext(context.Background())
+}
+
+// CloseContext closes the statement after the in-flight executions are released, if the context is done before that,
+// the context error is returned and the statement is closed when the last execution is released
+func (stmt *Stmt) CloseContext(ctx context.Context) error {
 	<-stmt.prepared
 
-	if stmt.Stmt != nil {
-		return stmt.Stmt.Close()
+	stmt.mux.Lock()
+	if stmt.closing {
+		drained := stmt.drained
+		stmt.mux.Unlock()
+		if drained == nil {
+			return stmt.closeStmt()
+		}
+		return stmt.waitDrained(ctx, drained)
+	}
+
+	stmt.closing = true
+	if stmt.inflight <= 0 {
+		stmt.mux.Unlock()
+		return stmt.closeStmt()
+	}
+	stmt.drained = make(chan struct{})
+	drained := stmt.drained
+	stmt.mux.Unlock()
+
+	return stmt.waitDrained(ctx, drained)
+}
+
+func (stmt *Stmt) waitDrained(ctx context.Context, drained chan struct{}) error {
+	select {
+	case <-drained:
+		return stmt.closeStmt()
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return nil
 }
 
 // Store defines an interface for managing the caching operations of SQL statements (Stmt).
@@ -40,14 +119,15 @@ type Store interface {
 	// New creates a new Stmt object and caches it.
 	// Parameters:
 	//   ctx: The context for the request, which can carry deadlines, cancellation signals, etc.
-	//   key: The key representing the SQL query, used for caching and preparing the statement.
+	//   key: The key used for caching the statement.
+	//   query: The SQL query used for preparing the statement.
 	//   isTransaction: Indicates whether this operation is part of a transaction, which may affect the caching strategy.
 	//   connPool: A connection pool that provides database connections.
 	//   locker: A synchronization lock that is unlocked after initialization to avoid deadlocks.
 	// Returns:
-	//   *Stmt: A newly created statement object for executing SQL operations.
+	//   *Stmt: A newly created statement object for executing SQL operations, it's acquired and must be released after use.
 	//   error: An error if the statement preparation fails.
-	New(ctx context.Context, key string, isTransaction bool, connPool ConnPool, locker sync.Locker) (*Stmt, error)
+	New(ctx context.Context, key, query string, isTransaction bool, connPool ConnPool, locker sync.Locker) (*Stmt, error)
 
 	// Keys returns a slice of all cache keys in the store.
 	Keys() []string
@@ -70,6 +150,13 @@ type Store interface {
 	// Parameters:
 	//   key: The key associated with the Stmt object to be deleted.
 	Delete(key string)
+
+	// Bytes returns the total bytes of the SQL templates of the cached statements.
+	Bytes() int64
+
+	// OnEvict registers a callback called with the query of the statement evicted by the store because of
+	// exceeded size, bytes or TTL, it's not called for Delete. The callback must not access the store.
+	OnEvict(fn func(query string))
 }
 
 // defaultMaxSize defines the default maximum capacity of the cache.
@@ -90,6 +177,8 @@ const (
 //     it defaults to defaultMaxSize.
 //   - ttl: The time-to-live duration for each cache entry. If the provided ttl is less than or equal to 0,
 //     it defaults to defaultTTL.
+//   - maxBytes: The maximum total bytes of the SQL templates of the cached statements, the least recently used
+//     statements are evicted when it's exceeded. If the provided maxBytes is less than or equal to 0, it's unlimited.
 //
 // This function defines an onEvicted callback that is invoked when a cache entry is evicted.
 // The callback ensures that if the evicted value (v) is not nil, its Close method is called asynchronously
@@ -98,7 +187,7 @@ const (
 // Returns:
 //   - A Store instance implemented by lruStore, which internally uses an LRU cache with the specified size,
 //     eviction callback, and TTL.
-func New(size int, ttl time.Duration) Store {
+func New(size int, ttl time.Duration, maxBytes int64) Store {
 	if size <= 0 {
 		size = defaultMaxSize
 	}
@@ -112,11 +201,31 @@ func New(size int, ttl time.Duration) Store {
 			go v.Close()
 		}
 	}
-	return &lruStore{lru: lru.NewLRU[string, *Stmt](size, onEvicted, ttl)}
+	store := lru.NewLRU[string, *Stmt](size, onEvicted, ttl)
+	store.SetMaxCost(maxBytes, func(k string, v *Stmt) int64 {
+		if v != nil {
+			return v.bytes
+		}
+		return 0
+	})
+	s := &lruStore{lru: store}
+	store.SetExpireCallback(func(k string, v *Stmt) {
+		s.mux.RLock()
+		defer s.mux.RUnlock()
+		if v != nil {
+			for _, fn := range s.onEvict {
+				fn(v.query)
+			}
+		}
+	})
+	return s
 }
 
 type lruStore struct {
 	lru *lru.LRU[string, *Stmt]
+
+	mux     sync.RWMutex
+	onEvict []func(query string)
 }
 
 func (s *lruStore) Keys() []string {
@@ -139,6 +248,16 @@ func (s *lruStore) Delete(key string) {
 	s.lru.Remove(key)
 }
 
+func (s *lruStore) Bytes() int64 {
+	return s.lru.Cost()
+}
+
+func (s *lruStore) OnEvict(fn func(query string)) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.onEvict = append(s.onEvict, fn)
+}
+
 type ConnPool interface {
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
@@ -148,21 +267,30 @@ type ConnPool interface {
 // Parameters:
 //
 //	ctx: Context for the request, used to carry deadlines, cancellation signals, etc.
-//	key: The key representing the SQL query, used for caching and preparing the statement.
+//	key: The key used for caching the statement.
+//	query: The SQL query used for preparing the statement.
 //	isTransaction: Indicates whether this operation is part of a transaction, affecting cache strategy.
 //	conn: A connection pool that provides database connections.
 //	locker: A synchronization lock that is unlocked after initialization to avoid deadlocks.
 //
 // Returns:
 //
-//	*Stmt: A newly created statement object for executing SQL operations.
+//	*Stmt: A newly created statement object for executing SQL operations, it's acquired and must be released after use.
 //	error: An error if the statement preparation fails.
-func (s *lruStore) New(ctx context.Context, key string, isTransaction bool, conn ConnPool, locker sync.Locker) (_ *Stmt, err error) {
+func (s *lruStore) New(ctx context.Context, key, query string, isTransaction bool, conn ConnPool, locker sync.Locker) (_ *Stmt, err error) {
 	// Create a Stmt object and set its Transaction property.
 	// The prepared channel is used to synchronize the statement preparation state.
 	cacheStmt := &Stmt{
 		Transaction: isTransaction,
 		prepared:    make(chan struct{}),
+		bytes:       int64(len(query)),
+		query:       query,
+		// acquired before cached, so it's not closed before returned to the caller
+		inflight: 1,
+	}
+	// keys set by PreparedKey are retained besides the query
+	if key != query {
+		cacheStmt.bytes += int64(len(key))
 	}
 	// Cache the Stmt object with the associated key.
 	s.Set(key, cacheStmt)
@@ -173,10 +301,11 @@ func (s *lruStore) New(ctx context.Context, key string, isTransaction bool, conn
 	defer close(cacheStmt.prepared)
 
 	// Prepare the SQL statement using the provided connection.
-	cacheStmt.Stmt, err = conn.PrepareContext(ctx, key)
+	cacheStmt.Stmt, err = conn.PrepareContext(ctx, query)
 	if err != nil {
 		// If statement preparation fails, record the error and remove the invalid Stmt object from the cache.
 		cacheStmt.prepareErr = err
+		cacheStmt.Release()
 		s.Delete(key)
 		return &Stmt{}, err
 	}
@@ -68,6 +68,19 @@ type Interface interface {
 	Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error)
 }
 
+// StructuredTracer optional interface of loggers, it's called instead of Trace with the statement as structured fields:
+//
+//	sql          string  the SQL with vars explained
+//	rows         int64   rows affected, -1 if unknown
+//	duration_ms  float64 elapsed time in milliseconds
+//	error        error   the error of the statement, nil if succeeded
+//	caller       string  file:line of the caller
+//
+// the log level and slow threshold are left to the implementation
+type StructuredTracer interface {
+	TraceStructured(ctx context.Context, fields map[string]interface{})
+}
+
 var (
 	// Discard logger will print any log to io.Discard
 	Discard = New(log.New(io.Discard, "", log.LstdFlags), Config{})
@@ -1,9 +1,12 @@
 package gorm
 
 import (
+	"context"
 	"reflect"
+	"time"
 
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 )
 
@@ -21,9 +24,115 @@ func (db *DB) Migrator() Migrator {
 
 // AutoMigrate run auto migration for given models
 func (db *DB) AutoMigrate(dst ...interface{}) error {
+	if db.Config.MigrationLock {
+		unlock, err := db.lockMigration()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	return db.Migrator().AutoMigrate(dst...)
 }
 
+const (
+	migrationLockName = "gorm:migration_lock"
+	// defaultMigrationLockTimeout the lock row held longer than it is stale, see Config.MigrationLockTimeout
+	defaultMigrationLockTimeout = 10 * time.Minute
+)
+
+// migrationLock lock table for databases don't support advisory locks
+type migrationLock struct {
+	Name     string `gorm:"primaryKey;size:64"`
+	LockedAt time.Time
+}
+
+func (migrationLock) TableName() string {
+	return "gorm_migration_locks"
+}
+
+// lockMigration acquires the migration lock, blocks until it's released by others, becomes stale or the context is done
+func (db *DB) lockMigration() (unlock func(), err error) {
+	ctx := context.Background()
+	if db.Statement != nil && db.Statement.Context != nil {
+		ctx = db.Statement.Context
+	}
+
+	switch db.Dialector.Name() {
+	case "postgres", "mysql":
+		// advisory locks belong to the session, lock and unlock on the same connection
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// statements run on Statement.ConnPool, pin it to the connection holding the lock
+		tx := db.Session(&Session{NewDB: true, Context: ctx})
+		tx.Statement.ConnPool = conn
+
+		lockSQL, unlockSQL := "SELECT pg_advisory_lock(hashtext(?))", "SELECT pg_advisory_unlock(hashtext(?))"
+		if db.Dialector.Name() == "mysql" {
+			lockSQL, unlockSQL = "SELECT GET_LOCK(?, -1)", "SELECT RELEASE_LOCK(?)"
+		}
+
+		if err := tx.Exec(lockSQL, migrationLockName).Error; err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		return func() {
+			tx.Session(&Session{Context: context.Background()}).Exec(unlockSQL, migrationLockName)
+			conn.Close()
+		}, nil
+	default:
+		tx := db.Session(&Session{NewDB: true, Context: ctx})
+		if !tx.Migrator().HasTable(&migrationLock{}) {
+			if err := tx.Migrator().CreateTable(&migrationLock{}); err != nil && !tx.Migrator().HasTable(&migrationLock{}) {
+				return nil, err
+			}
+		}
+
+		timeout := db.Config.MigrationLockTimeout
+		if timeout <= 0 {
+			timeout = defaultMigrationLockTimeout
+		}
+
+		// conflicts are expected when the lock is held by others, don't log them
+		silentTx := tx.Session(&Session{Logger: tx.Logger.LogMode(logger.Silent)})
+		for {
+			err := silentTx.Create(&migrationLock{Name: migrationLockName, LockedAt: db.NowFunc()}).Error
+			if err == nil {
+				break
+			}
+
+			var count int64
+			if tx.Model(&migrationLock{}).Where("name = ?", migrationLockName).Count(&count); count == 0 {
+				return nil, err
+			}
+
+			// the holder of a stale lock is gone, remove it and compete for the lock again
+			if stale := tx.Where("name = ? AND locked_at < ?", migrationLockName, db.NowFunc().Add(-timeout)).Delete(&migrationLock{}); stale.Error == nil && stale.RowsAffected > 0 {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+
+		return func() {
+			tx.Session(&Session{Context: context.Background()}).Delete(&migrationLock{Name: migrationLockName})
+		}, nil
+	}
+}
+
 // ViewOption view option
 type ViewOption struct {
 	Replace     bool   // If true, exec `CREATE`. If false, exec `CREATE OR REPLACE`
@@ -31,6 +140,12 @@ type ViewOption struct {
 	Query       *DB    // required subquery.
 }
 
+// TruncateOptions truncate option
+type TruncateOptions struct {
+	Cascade         bool // truncate tables referencing the truncated tables as well, e.g. `CASCADE` of Postgres
+	RestartIdentity bool // reset the sequences owned by the tables, e.g. `RESTART IDENTITY` of Postgres
+}
+
 // ColumnType column type interface
 type ColumnType interface {
 	Name() string
@@ -79,6 +194,7 @@ type Migrator interface {
 	DropTable(dst ...interface{}) error
 	HasTable(dst interface{}) bool
 	RenameTable(oldName, newName interface{}) error
+	RenameTableIfExists(oldName, newName interface{}) error
 	GetTables() (tableList []string, err error)
 	TableType(dst interface{}) (TableType, error)
 
@@ -91,11 +207,14 @@ type Migrator interface {
 	MigrateColumnUnique(dst interface{}, field *schema.Field, columnType ColumnType) error
 	HasColumn(dst interface{}, field string) bool
 	RenameColumn(dst interface{}, oldName, field string) error
+	RenameColumnIfExists(dst interface{}, oldName, field string) error
 	ColumnTypes(dst interface{}) ([]ColumnType, error)
 
 	// Views
 	CreateView(name string, option ViewOption) error
 	DropView(name string) error
+	RefreshMaterializedView(name string, concurrently bool) error
+	TruncateTables(opts TruncateOptions, dst ...interface{}) error
 
 	// Constraints
 	CreateConstraint(dst interface{}, name string) error
@@ -74,6 +74,16 @@ func (m Migrator) RunWithValue(value interface{}, fc func(*gorm.Statement) error
 	return fc(stmt)
 }
 
+// isMaterializedView whether the model is marked with the `materializedView` tag
+func (m Migrator) isMaterializedView(value interface{}) bool {
+	if _, ok := value.(string); ok {
+		return false
+	}
+
+	stmt := &gorm.Statement{DB: m.DB}
+	return stmt.Parse(value) == nil && stmt.Schema.MaterializedView
+}
+
 // DataTypeOf return field's db data type
 func (m Migrator) DataTypeOf(field *schema.Field) string {
 	fieldValue := reflect.New(field.IndirectFieldType)
@@ -120,6 +130,11 @@ func (m Migrator) GetQueryAndExecTx() (queryTx, execTx *gorm.DB) {
 // AutoMigrate auto migrate values
 func (m Migrator) AutoMigrate(values ...interface{}) error {
 	for _, value := range m.ReorderModels(values, true) {
+		// materialized views are managed with views' DDL, not migrated as tables
+		if m.isMaterializedView(value) {
+			continue
+		}
+
 		queryTx, execTx := m.GetQueryAndExecTx()
 		if !queryTx.Migrator().HasTable(value) {
 			if err := execTx.Migrator().CreateTable(value); err != nil {
@@ -150,14 +165,19 @@ func (m Migrator) AutoMigrate(values ...interface{}) error {
 						}
 					}
 
+					field := stmt.Schema.FieldsByDBName[dbName]
 					if foundColumn == nil {
 						// not found, add column
-						if err = execTx.Migrator().AddColumn(value, dbName); err != nil {
+						if after := field.TagSettings["MIGRATEAFTER"]; after != "" {
+							err = m.addColumnAfter(execTx, value, stmt, field, after, columnTypes)
+						} else {
+							err = execTx.Migrator().AddColumn(value, dbName)
+						}
+						if err != nil {
 							return err
 						}
 					} else {
 						// found, smartly migrate
-						field := stmt.Schema.FieldsByDBName[dbName]
 						if err = execTx.Migrator().MigrateColumn(value, field, foundColumn); err != nil {
 							return err
 						}
@@ -329,6 +349,74 @@ func (m Migrator) DropTable(values ...interface{}) error {
 	return nil
 }
 
+// TruncateTables removes all rows of the tables, tables referencing others are truncated first,
+// foreign key checks are disabled during truncating for MySQL
+func (m Migrator) TruncateTables(opts gorm.TruncateOptions, values ...interface{}) error {
+	values = m.ReorderModels(values, false)
+
+	if m.Dialector.Name() == "postgres" {
+		// tables referenced by foreign keys can only be truncated with the referencing tables in the same command
+		tables := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+				tables = append(tables, m.CurrentTable(stmt))
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		sql := "TRUNCATE TABLE " + strings.TrimSuffix(strings.Repeat("?,", len(tables)), ",")
+		if opts.RestartIdentity {
+			sql += " RESTART IDENTITY"
+		}
+		if opts.Cascade {
+			sql += " CASCADE"
+		}
+		return m.DB.Exec(sql, tables...).Error
+	}
+
+	truncate := func(tx *gorm.DB) error {
+		for i := len(values) - 1; i >= 0; i-- {
+			if err := m.RunWithValue(values[i], func(stmt *gorm.Statement) error {
+				switch m.Dialector.Name() {
+				case "mysql":
+					// TRUNCATE always resets the AUTO_INCREMENT counter of MySQL
+					return tx.Exec("TRUNCATE TABLE ?", m.CurrentTable(stmt)).Error
+				default:
+					if err := tx.Exec("DELETE FROM ?", m.CurrentTable(stmt)).Error; err != nil {
+						return err
+					}
+					if opts.RestartIdentity && m.Dialector.Name() == "sqlite" && tx.Migrator().HasTable("sqlite_sequence") {
+						return tx.Exec("DELETE FROM sqlite_sequence WHERE name = ?", stmt.Table).Error
+					}
+					return nil
+				}
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if m.Dialector.Name() != "mysql" {
+		return truncate(m.DB.Session(&gorm.Session{}))
+	}
+
+	// FOREIGN_KEY_CHECKS is a session variable, keep the statements on the same connection
+	return m.DB.Connection(func(tx *gorm.DB) (err error) {
+		if err = tx.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer func() {
+			if resetErr := tx.Exec("SET FOREIGN_KEY_CHECKS = 1").Error; err == nil {
+				err = resetErr
+			}
+		}()
+		return truncate(tx)
+	})
+}
+
 // HasTable returns table exists or not for value, value could be a struct or string
 func (m Migrator) HasTable(value interface{}) bool {
 	var count int64
@@ -369,6 +457,15 @@ func (m Migrator) RenameTable(oldName, newName interface{}) error {
 	return m.DB.Exec("ALTER TABLE ? RENAME TO ?", oldTable, newTable).Error
 }
 
+// RenameTableIfExists rename table from oldName to newName only if oldName exists and newName doesn't,
+// it is safe to re-run
+func (m Migrator) RenameTableIfExists(oldName, newName interface{}) error {
+	if !m.DB.Migrator().HasTable(oldName) || m.DB.Migrator().HasTable(newName) {
+		return nil
+	}
+	return m.DB.Migrator().RenameTable(oldName, newName)
+}
+
 // AddColumn create `name` column for value
 func (m Migrator) AddColumn(value interface{}, name string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
@@ -392,6 +489,34 @@ func (m Migrator) AddColumn(value interface{}, name string) error {
 	})
 }
 
+// addColumnAfter adds the column after the column of the `migrateAfter` tag, the position is only supported by MySQL,
+// it's ignored by other dialects
+func (m Migrator) addColumnAfter(tx *gorm.DB, value interface{}, stmt *gorm.Statement, field *schema.Field, after string, columnTypes []gorm.ColumnType) error {
+	if f := stmt.Schema.LookUpField(after); f != nil && f.DBName != "" {
+		after = f.DBName
+	}
+
+	found := stmt.Schema.FieldsByDBName[after] != nil
+	for _, columnType := range columnTypes {
+		if columnType.Name() == after {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("failed to add column %s after %s, column not found", field.DBName, after)
+	}
+
+	if m.Dialector.Name() != "mysql" || field.IgnoreMigration || field.PrimaryKey {
+		return tx.Migrator().AddColumn(value, field.DBName)
+	}
+
+	return tx.Exec(
+		"ALTER TABLE ? ADD ? ? AFTER ?",
+		m.CurrentTable(stmt), clause.Column{Name: field.DBName}, tx.Migrator().FullDataTypeOf(field), clause.Column{Name: after},
+	).Error
+}
+
 // DropColumn drop value's `name` column
 func (m Migrator) DropColumn(value interface{}, name string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
@@ -465,6 +590,15 @@ func (m Migrator) RenameColumn(value interface{}, oldName, newName string) error
 	})
 }
 
+// RenameColumnIfExists rename value's field name from oldName to newName only if oldName exists and newName doesn't,
+// it is safe to re-run
+func (m Migrator) RenameColumnIfExists(value interface{}, oldName, newName string) error {
+	if !m.DB.Migrator().HasColumn(value, oldName) || m.DB.Migrator().HasColumn(value, newName) {
+		return nil
+	}
+	return m.DB.Migrator().RenameColumn(value, oldName, newName)
+}
+
 // MigrateColumn migrate column
 func (m Migrator) MigrateColumn(value interface{}, field *schema.Field, columnType gorm.ColumnType) error {
 	if field.IgnoreMigration {
@@ -666,6 +800,15 @@ func (m Migrator) DropView(name string) error {
 	return m.DB.Exec("DROP VIEW IF EXISTS ?", clause.Table{Name: name}).Error
 }
 
+// RefreshMaterializedView refresh materialized view, concurrently refreshes it without locking out concurrent selects,
+// which requires an unique index on the view
+func (m Migrator) RefreshMaterializedView(name string, concurrently bool) error {
+	if concurrently {
+		return m.DB.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY ?", clause.Table{Name: name}).Error
+	}
+	return m.DB.Exec("REFRESH MATERIALIZED VIEW ?", clause.Table{Name: name}).Error
+}
+
 // GuessConstraintAndTable guess statement's constraint and it's table based on name
 //
 // Deprecated: use GuessConstraintInterfaceAndTable instead.
@@ -6,7 +6,9 @@ import (
 	"database/sql/driver"
 	"errors"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/gorm/internal/stmt_store"
@@ -20,6 +22,26 @@ type PreparedStmtDB struct {
 	Mux   *sync.RWMutex
 	// 内置的 ConnPool 字段通常为 database/sql 中的 *DB
 	ConnPool
+	// StrictTxStmtReuse 事务中仅复用同一事务内预编译的语句
+	StrictTxStmtReuse bool
+	// 缓存命中、未命中、逐出次数统计
+	counters *prepareStmtCounters
+	// writer the statements are prepared on the writer returned by WriterSelector
+	writer bool
+}
+
+// PrepareStmtMetrics metrics of the prepared statement cache
+type PrepareStmtMetrics struct {
+	Hits      uint64 // statements reused from the cache
+	Misses    uint64 // statements prepared because not cached
+	Evictions uint64 // statements evicted by the cache because of exceeded size, bytes or TTL
+	Size      int    // statements currently cached
+}
+
+type prepareStmtCounters struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
 }
 
 // NewPreparedStmtDB creates and initializes a new instance of PreparedStmtDB.
@@ -32,10 +54,58 @@ type PreparedStmtDB struct {
 // Returns:
 // - A pointer to a PreparedStmtDB instance, which manages prepared statements using the provided connection pool and configuration.
 func NewPreparedStmtDB(connPool ConnPool, maxSize int, ttl time.Duration) *PreparedStmtDB {
-	return &PreparedStmtDB{
-		ConnPool: connPool,                     // Assigns the provided connection pool to manage database connections.
-		Stmts:    stmt_store.New(maxSize, ttl), // Initializes a new statement store with the specified maximum size and TTL.
-		Mux:      &sync.RWMutex{},              // Sets up a read-write mutex for synchronizing access to the statement store.
+	return NewPreparedStmtDBWithMaxBytes(connPool, maxSize, ttl, 0)
+}
+
+// NewPreparedStmtDBWithMaxBytes creates a PreparedStmtDB like NewPreparedStmtDB, and also limits the total bytes
+// of the SQL templates in the store by maxBytes, zero means unlimited. The least recently used statements are
+// removed when it's exceeded.
+func NewPreparedStmtDBWithMaxBytes(connPool ConnPool, maxSize int, ttl time.Duration, maxBytes int64) *PreparedStmtDB {
+	db := &PreparedStmtDB{
+		ConnPool: connPool,                               // Assigns the provided connection pool to manage database connections.
+		Stmts:    stmt_store.New(maxSize, ttl, maxBytes), // Initializes a new statement store with the specified maximum size, TTL and bytes.
+		Mux:      &sync.RWMutex{},                        // Sets up a read-write mutex for synchronizing access to the statement store.
+		counters: &prepareStmtCounters{},                 // Counts hits, misses and evictions of the statement store.
+	}
+
+	counters := db.counters
+	db.Stmts.OnEvict(func(string) {
+		atomic.AddUint64(&counters.evictions, 1)
+	})
+	return db
+}
+
+// Metrics returns the hits, misses and evictions of the statement cache since created or last reset
+func (db *PreparedStmtDB) Metrics() PrepareStmtMetrics {
+	metrics := PrepareStmtMetrics{Size: len(db.Stmts.Keys())}
+	if db.counters != nil {
+		metrics.Hits = atomic.LoadUint64(&db.counters.hits)
+		metrics.Misses = atomic.LoadUint64(&db.counters.misses)
+		metrics.Evictions = atomic.LoadUint64(&db.counters.evictions)
+	}
+	return metrics
+}
+
+// ResetMetrics resets the counters of Metrics, cached statements are kept
+func (db *PreparedStmtDB) ResetMetrics() {
+	if db.counters != nil {
+		atomic.StoreUint64(&db.counters.hits, 0)
+		atomic.StoreUint64(&db.counters.misses, 0)
+		atomic.StoreUint64(&db.counters.evictions, 0)
+	}
+}
+
+// hit counts a statement reused from the cache
+func (c *prepareStmtCounters) hit() {
+	if c != nil {
+		atomic.AddUint64(&c.hits, 1)
+	}
+}
+
+// miss counts a statement prepared because not cached
+func (c *prepareStmtCounters) miss() {
+	if c != nil {
+		atomic.AddUint64(&c.misses, 1)
 	}
 }
 
@@ -52,7 +122,28 @@ func (db *PreparedStmtDB) GetDBConn() (*sql.DB, error) {
 	return nil, ErrInvalidDB
 }
 
-// Close closes all prepared statements in the store
+// Writer returns the PreparedStmtDB preparing statements on the writer if the ConnPool implements WriterSelector,
+// the statements are cached separately in the same store as they're prepared on another connection
+func (db *PreparedStmtDB) Writer() ConnPool {
+	if selector, ok := db.ConnPool.(WriterSelector); ok && !db.writer {
+		return &PreparedStmtDB{
+			Stmts:             db.Stmts,
+			Mux:               db.Mux,
+			ConnPool:          selector.Writer(),
+			StrictTxStmtReuse: db.StrictTxStmtReuse,
+			counters:          db.counters,
+			writer:            true,
+		}
+	}
+	return db
+}
+
+// StmtsBytes returns the total bytes of the SQL templates of the cached statements
+func (db *PreparedStmtDB) StmtsBytes() int64 {
+	return db.Stmts.Bytes()
+}
+
+// Close closes all prepared statements in the store, statements being executed are closed after they finish
 func (db *PreparedStmtDB) Close() {
 	db.Mux.Lock()
 	defer db.Mux.Unlock()
@@ -62,6 +153,33 @@ func (db *PreparedStmtDB) Close() {
 	}
 }
 
+// CloseContext closes all prepared statements in the store like Close, but waits for the statements being executed
+// to finish, if the context is done before that, the context error is returned and the statements are closed when
+// their executions finish, rows being read keep their statements alive until they are closed
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := preparedStmtDB.CloseContext(ctx)
+func (db *PreparedStmtDB) CloseContext(ctx context.Context) (err error) {
+	db.Mux.Lock()
+	keys := db.Stmts.Keys()
+	stmts := make([]*stmt_store.Stmt, 0, len(keys))
+	for _, key := range keys {
+		if stmt, ok := db.Stmts.Get(key); ok && stmt != nil {
+			stmts = append(stmts, stmt)
+		}
+		db.Stmts.Delete(key)
+	}
+	db.Mux.Unlock()
+
+	for _, stmt := range stmts {
+		if closeErr := stmt.CloseContext(ctx); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
 // Reset Deprecated use Close instead
 func (db *PreparedStmtDB) Reset() {
 	db.Close()
@@ -70,13 +188,18 @@ func (db *PreparedStmtDB) Reset() {
 // 加读锁，然后以 sql 模板为 key，尝试从 db.Stmts map 中获取 stmt 复用
 // 倘若 stmt 不存在，则加写锁 double check
 // 调用 conn.PrepareContext(...) 方法，创建新的 stmt，并存放到 map 中供后续复用
+// 返回的 stmt 已被标记为使用中（Acquire），调用方执行完毕后需要 Release，关闭时会等待其释放
 func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransaction bool, query string) (_ *stmt_store.Stmt, err error) {
+	key := db.stmtKey(ctx, isTransaction, query)
+
 	// 并发场景下，只允许有一个 goroutine 完成 stmt 的初始化操作
 	db.Mux.RLock()
 	if db.Stmts != nil {
 		// 以 sql 模板为 key，优先复用已有的 stmt
-		if stmt, ok := db.Stmts.Get(query); ok && (!stmt.Transaction || isTransaction) {
+		// statements being closed, e.g: expired, can't be acquired, they are prepared again
+		if stmt, ok := db.Stmts.Get(key); ok && (!stmt.Transaction || isTransaction) && (stmt.Error() != nil || stmt.Acquire()) {
 			db.Mux.RUnlock()
+			db.counters.hit()
 			return stmt, stmt.Error()
 		}
 	}
@@ -86,13 +209,116 @@ func (db *PreparedStmtDB) prepare(ctx context.Context, conn ConnPool, isTransact
 	// 加锁 double check，确认未完成 stmt 初始化则执行初始化操作
 	db.Mux.Lock()
 	if db.Stmts != nil {
-		if stmt, ok := db.Stmts.Get(query); ok && (!stmt.Transaction || isTransaction) {
+		if stmt, ok := db.Stmts.Get(key); ok && (!stmt.Transaction || isTransaction) && (stmt.Error() != nil || stmt.Acquire()) {
 			db.Mux.Unlock()
+			db.counters.hit()
 			return stmt, stmt.Error()
 		}
 	}
 
-	return db.Stmts.New(ctx, query, isTransaction, conn, db.Mux)
+	db.counters.miss()
+	return db.Stmts.New(ctx, key, query, isTransaction, conn, db.Mux)
+}
+
+type preparedKeyCtxKey struct{}
+
+// withPreparedKey passes the key set by DB.PreparedKey to the prepared statement manager with the statement context
+// while executing, as ConnPool methods only receive the context, returns the func restoring the context
+func (db *DB) withPreparedKey() func() {
+	stmt := db.Statement
+	key, ok := stmt.Settings.Load("gorm:prepared_key")
+	if !ok {
+		return func() {}
+	}
+
+	ctx, parent := stmt.Context, stmt.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	stmt.Context = context.WithValue(parent, preparedKeyCtxKey{}, key)
+	return func() {
+		stmt.Context = ctx
+	}
+}
+
+// preparedStmtKey returns the cache key of the prepared statement, it's the query by default,
+// or the key set by DB.PreparedKey combined with the query without comments and redundant whitespaces
+func preparedStmtKey(ctx context.Context, query string) string {
+	if key, ok := ctx.Value(preparedKeyCtxKey{}).(string); ok {
+		query = key + "\x00" + normalizePreparedSQL(query)
+	}
+	return query
+}
+
+// stmtKey returns the cache key of the prepared statement, statements prepared on the writer are cached separately,
+// statements of transactions are prepared on the connection of the transaction, they share the same key
+func (db *PreparedStmtDB) stmtKey(ctx context.Context, isTransaction bool, query string) string {
+	if db.writer && !isTransaction {
+		return "writer\x00" + preparedStmtKey(ctx, query)
+	}
+	return preparedStmtKey(ctx, query)
+}
+
+// normalizePreparedSQL strips comments and collapses whitespaces outside of quotes, optimizer hints `/*+ ... */` and
+// MySQL executable comments `/*! ... */` change the statement, they are kept
+func normalizePreparedSQL(query string) string {
+	var (
+		builder    strings.Builder
+		quote      byte
+		whitespace bool
+	)
+
+	builder.Grow(len(query))
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if quote != 0 {
+			builder.WriteByte(c)
+			if c == '\\' && i+1 < len(query) {
+				i++
+				builder.WriteByte(query[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			whitespace = true
+			continue
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := len(query)
+			if idx := strings.Index(query[i+2:], "*/"); idx >= 0 {
+				end = i + idx + 4
+			}
+
+			if i+2 < len(query) && (query[i+2] == '+' || query[i+2] == '!') {
+				if whitespace && builder.Len() > 0 {
+					builder.WriteByte(' ')
+				}
+				builder.WriteString(query[i:end])
+			}
+			i = end - 1
+			whitespace = true
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			whitespace = true
+			continue
+		}
+
+		if whitespace && builder.Len() > 0 {
+			builder.WriteByte(' ')
+		}
+		whitespace = false
+		builder.WriteByte(c)
+	}
+	return builder.String()
 }
 
 func (db *PreparedStmtDB) BeginTx(ctx context.Context, opt *sql.TxOptions) (ConnPool, error) {
@@ -123,9 +349,10 @@ func (db *PreparedStmtDB) BeginTx(ctx context.Context, opt *sql.TxOptions) (Conn
 func (db *PreparedStmtDB) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
+		defer stmt.Release()
 		result, err = stmt.ExecContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
-			db.Stmts.Delete(query)
+			db.Stmts.Delete(db.stmtKey(ctx, false, query))
 		}
 	}
 	return result, err
@@ -138,9 +365,10 @@ func (db *PreparedStmtDB) ExecContext(ctx context.Context, query string, args ..
 func (db *PreparedStmtDB) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
+		defer stmt.Release()
 		rows, err = stmt.QueryContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
-			db.Stmts.Delete(query)
+			db.Stmts.Delete(db.stmtKey(ctx, false, query))
 		}
 	}
 	return rows, err
@@ -149,6 +377,7 @@ func (db *PreparedStmtDB) QueryContext(ctx context.Context, query string, args .
 func (db *PreparedStmtDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	stmt, err := db.prepare(ctx, db.ConnPool, false, query)
 	if err == nil {
+		defer stmt.Release()
 		return stmt.QueryRowContext(ctx, args...)
 	}
 	return &sql.Row{}
@@ -162,9 +391,67 @@ func (db *PreparedStmtDB) Ping() error {
 	return conn.Ping()
 }
 
+func (db *PreparedStmtDB) PingContext(ctx context.Context) error {
+	conn, err := db.GetDBConn()
+	if err != nil {
+		return err
+	}
+	return conn.PingContext(ctx)
+}
+
 type PreparedStmtTX struct {
 	Tx
 	PreparedStmtDB *PreparedStmtDB
+
+	// statements prepared in the transaction, used when StrictTxStmtReuse enabled
+	mux   sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+// prepare returns the statement bound to the transaction, statements in the shared cache are reused
+// unless StrictTxStmtReuse enabled, then only the statements prepared in the transaction are reused,
+// release must be called after the statement is executed
+func (tx *PreparedStmtTX) prepare(ctx context.Context, query string) (_ *sql.Stmt, release func(), err error) {
+	if !tx.PreparedStmtDB.StrictTxStmtReuse {
+		stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tx.Tx.StmtContext(ctx, stmt.Stmt), stmt.Release, nil
+	}
+
+	key := preparedStmtKey(ctx, query)
+
+	tx.mux.Lock()
+	defer tx.mux.Unlock()
+
+	if stmt, ok := tx.stmts[key]; ok {
+		return stmt, func() {}, nil
+	}
+
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tx.stmts == nil {
+		tx.stmts = map[string]*sql.Stmt{}
+	}
+	tx.stmts[key] = stmt
+	return stmt, func() {}, nil
+}
+
+// deleteStmt removes the statement of the query after bad connection errors
+func (tx *PreparedStmtTX) deleteStmt(ctx context.Context, query string) {
+	key := preparedStmtKey(ctx, query)
+	if !tx.PreparedStmtDB.StrictTxStmtReuse {
+		tx.PreparedStmtDB.Stmts.Delete(key)
+		return
+	}
+
+	tx.mux.Lock()
+	defer tx.mux.Unlock()
+	delete(tx.stmts, key)
 }
 
 func (db *PreparedStmtTX) GetDBConn() (*sql.DB, error) {
@@ -186,31 +473,34 @@ func (tx *PreparedStmtTX) Rollback() error {
 }
 
 func (tx *PreparedStmtTX) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
-	stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
+	stmt, release, err := tx.prepare(ctx, query)
 	if err == nil {
-		result, err = tx.Tx.StmtContext(ctx, stmt.Stmt).ExecContext(ctx, args...)
+		defer release()
+		result, err = stmt.ExecContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
-			tx.PreparedStmtDB.Stmts.Delete(query)
+			tx.deleteStmt(ctx, query)
 		}
 	}
 	return result, err
 }
 
 func (tx *PreparedStmtTX) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
-	stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
+	stmt, release, err := tx.prepare(ctx, query)
 	if err == nil {
-		rows, err = tx.Tx.StmtContext(ctx, stmt.Stmt).QueryContext(ctx, args...)
+		defer release()
+		rows, err = stmt.QueryContext(ctx, args...)
 		if errors.Is(err, driver.ErrBadConn) {
-			tx.PreparedStmtDB.Stmts.Delete(query)
+			tx.deleteStmt(ctx, query)
 		}
 	}
 	return rows, err
 }
 
 func (tx *PreparedStmtTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	stmt, err := tx.PreparedStmtDB.prepare(ctx, tx.Tx, true, query)
+	stmt, release, err := tx.prepare(ctx, query)
 	if err == nil {
-		return tx.Tx.StmtContext(ctx, stmt.Stmt).QueryRowContext(ctx, args...)
+		defer release()
+		return stmt.QueryRowContext(ctx, args...)
 	}
 	return &sql.Row{}
 }
@@ -222,3 +512,11 @@ func (tx *PreparedStmtTX) Ping() error {
 	}
 	return conn.Ping()
 }
+
+func (tx *PreparedStmtTX) PingContext(ctx context.Context) error {
+	conn, err := tx.GetDBConn()
+	if err != nil {
+		return err
+	}
+	return conn.PingContext(ctx)
+}
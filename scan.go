@@ -3,6 +3,8 @@ package gorm
 import (
 	"database/sql"
 	"database/sql/driver"
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"time"
@@ -65,15 +67,32 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 	}
 
 	db.RowsAffected++
-	db.AddError(rows.Scan(values...))
+	var skipped map[int]bool
+	if err := rows.Scan(values...); err != nil && db.OnScanError != nil {
+		skipped, err = db.handleScanError(rows, values, fields, err)
+		db.AddError(err)
+	} else {
+		db.AddError(err)
+	}
+	if db.ScanLocation != nil {
+		for _, value := range values {
+			convertTimeLocation(value, db.ScanLocation)
+		}
+	}
+
 	joinedNestedSchemaMap := make(map[string]interface{})
 	for idx, field := range fields {
 		if field == nil {
 			continue
 		}
 
-		if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
-			db.AddError(field.Set(db.Statement.Context, reflectValue, values[idx]))
+		if skipped[idx] {
+			// the column is skipped by OnScanError, leave the field zero
+			if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
+				field.ReflectValueOf(db.Statement.Context, reflectValue).Set(reflect.Zero(field.FieldType))
+			}
+		} else if len(joinFields) == 0 || len(joinFields[idx]) == 0 {
+			db.setScannedValue(field, reflectValue, values[idx])
 		} else { // joinFields count is larger than 2 when using join
 			var isNilPtrValue bool
 			var relValue reflect.Value
@@ -103,7 +122,7 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 
 			if !isNilPtrValue { // ignore if value is nil
 				f := joinFields[idx][len(joinFields[idx])-1]
-				db.AddError(f.Set(db.Statement.Context, relValue, values[idx]))
+				db.setScannedValue(f, relValue, values[idx])
 			}
 		}
 
@@ -112,6 +131,135 @@ func (db *DB) scanIntoStruct(rows Rows, reflectValue reflect.Value, values []int
 	}
 }
 
+// handleScanError passes the conversion errors of columns to OnScanError, the failing columns are found by scanning
+// each column into its destination while the others are scanned as raw values, returns the skipped columns
+func (db *DB) handleScanError(rows Rows, values []interface{}, fields []*schema.Field, err error) (map[int]bool, error) {
+	raws := make([]interface{}, len(values))
+	for i := range raws {
+		raws[i] = new(interface{})
+	}
+	if rawErr := rows.Scan(raws...); rawErr != nil {
+		return nil, err
+	}
+
+	var (
+		skipped = map[int]bool{}
+		dests   = make([]interface{}, len(values))
+	)
+	for idx, value := range values {
+		copy(dests, raws)
+		dests[idx] = value
+
+		columnErr := rows.Scan(dests...)
+		if columnErr == nil {
+			continue
+		} else if fields[idx] == nil {
+			return skipped, columnErr
+		}
+
+		if cause := errors.Unwrap(columnErr); cause != nil {
+			columnErr = cause
+		}
+		if columnErr = db.OnScanError(fields[idx], *raws[idx].(*interface{}), columnErr); columnErr != nil {
+			return skipped, columnErr
+		}
+		skipped[idx] = true
+	}
+
+	if len(skipped) == 0 {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+// setScannedValue sets the scanned value to the field, the conversion error is passed to OnScanError if set
+func (db *DB) setScannedValue(field *schema.Field, reflectValue reflect.Value, value interface{}) {
+	err := field.Set(db.Statement.Context, reflectValue, value)
+	if err != nil && db.OnScanError != nil {
+		raw := value
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			raw = rv.Elem().Interface()
+		}
+
+		if err = db.OnScanError(field, raw, err); err == nil {
+			field.ReflectValueOf(db.Statement.Context, reflectValue).Set(reflect.Zero(field.FieldType))
+		}
+	}
+	db.AddError(err)
+}
+
+// conflictKeyedElems indexes the elements by the conflict keys set by the create callback,
+// returns nil if the keys are unknown or not returned
+func conflictKeyedElems(db *DB, reflectValue reflect.Value, fields []*schema.Field) ([]*schema.Field, map[string][]int) {
+	v, ok := db.InstanceGet("gorm:on_conflict_key_fields")
+	if !ok {
+		return nil, nil
+	}
+
+	keyFields, _ := v.([]*schema.Field)
+	if len(keyFields) == 0 {
+		return nil, nil
+	}
+
+	for _, keyField := range keyFields {
+		returned := false
+		for _, field := range fields {
+			if field != nil && field.DBName == keyField.DBName {
+				returned = true
+				break
+			}
+		}
+		if !returned {
+			return nil, nil
+		}
+	}
+
+	keyedElems := make(map[string][]int, reflectValue.Len())
+	for i := 0; i < reflectValue.Len(); i++ {
+		elem := reflectValue.Index(i)
+		for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			continue
+		}
+
+		key := conflictKey(db, keyFields, elem)
+		keyedElems[key] = append(keyedElems[key], i)
+	}
+	return keyFields, keyedElems
+}
+
+func conflictKey(db *DB, keyFields []*schema.Field, elem reflect.Value) string {
+	values := make([]interface{}, len(keyFields))
+	for idx, field := range keyFields {
+		values[idx], _ = field.ValueOf(db.Statement.Context, elem)
+	}
+	return utils.ToStringKey(values...)
+}
+
+// initPtrElem allocates the nil pointers of a slice element, returns the pointer to the struct
+func initPtrElem(elem reflect.Value) reflect.Value {
+	for elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		if elem.Elem().Kind() != reflect.Ptr {
+			break
+		}
+		elem = elem.Elem()
+	}
+	return elem
+}
+
+// convertTimeLocation convert scanned time value to loc
+func convertTimeLocation(value interface{}, loc *time.Location) {
+	if v, ok := value.(**time.Time); ok && *v != nil {
+		t := (*v).In(loc)
+		*v = &t
+	}
+}
+
 // ScanMode scan data mode
 type ScanMode uint8
 
@@ -212,10 +360,13 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 		case reflect.Array, reflect.Slice:
 			reflectValueType = reflectValueType.Elem()
 		}
-		isPtr := reflectValueType.Kind() == reflect.Ptr
-		if isPtr {
+		// elements like *User or **User are scanned into a *User, ptrDepth records the pointer levels
+		ptrDepth := 0
+		for reflectValueType.Kind() == reflect.Ptr {
 			reflectValueType = reflectValueType.Elem()
+			ptrDepth++
 		}
+		isPtr := ptrDepth > 0
 
 		if sch != nil {
 			if reflectValueType != sch.ModelType && reflectValueType.Kind() == reflect.Struct {
@@ -234,21 +385,40 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 			// Not Pluck
 			if sch != nil {
 				matchedFieldCount := make(map[string]int, len(columns))
+				v, _ := db.InstanceGet("gorm:qualified_aliases")
+				aliases, _ := v.(map[string]string)
 				for idx, column := range columns {
+					// columns aliased by SelectQualified, e.g. users_name
+					if name, ok := aliases[column]; ok {
+						column = name
+					}
+
 					if field := sch.LookUpField(column); field != nil && field.Readable {
 						fields[idx] = field
 						if count, ok := matchedFieldCount[column]; ok {
 							// handle duplicate fields
+							resolved := false
 							for _, selectField := range sch.Fields {
 								if selectField.DBName == column && selectField.Readable {
 									if count == 0 {
 										matchedFieldCount[column]++
 										fields[idx] = selectField
+										resolved = true
 										break
 									}
 									count--
 								}
 							}
+
+							// no other field left for the duplicate column, the last one wins unless configured otherwise
+							if !resolved && db.OnAmbiguousColumn != AmbiguousColumnLastWins {
+								err := fmt.Errorf("%w: %s", ErrAmbiguousColumn, column)
+								if db.OnAmbiguousColumn == AmbiguousColumnPrefixWithTable {
+									err = fmt.Errorf("%w, alias the columns with SelectQualified", err)
+								}
+								db.AddError(err)
+								return
+							}
 						} else {
 							matchedFieldCount[column] = 1
 						}
@@ -315,15 +485,47 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 				}
 			}
 
+			// rows skipped by `ON CONFLICT DO NOTHING` are not returned, map the returned rows by the conflict keys
+			var (
+				keyFields  []*schema.Field
+				keyedElems map[string][]int
+			)
+			if update && onConflictDonothing {
+				keyFields, keyedElems = conflictKeyedElems(db, reflectValue, fields)
+			}
+
 			for initialized || rows.Next() {
 			BEGIN:
 				initialized = false
 
+				if keyedElems != nil {
+					returned := reflect.New(reflectValueType)
+					db.scanIntoStruct(rows, returned, values, fields, joinFields)
+
+					key := conflictKey(db, keyFields, returned)
+					if idxes := keyedElems[key]; len(idxes) > 0 {
+						keyedElems[key] = idxes[1:]
+						elem = reflectValue.Index(idxes[0])
+						if isPtr {
+							elem = initPtrElem(elem)
+						}
+						for _, field := range fields {
+							if field != nil {
+								field.ReflectValueOf(db.Statement.Context, elem).Set(field.ReflectValueOf(db.Statement.Context, returned))
+							}
+						}
+					}
+					continue
+				}
+
 				if update {
 					if int(db.RowsAffected) >= reflectValue.Len() {
 						return
 					}
 					elem = reflectValue.Index(int(db.RowsAffected))
+					if isPtr {
+						elem = initPtrElem(elem)
+					}
 					if onConflictDonothing {
 						for _, field := range fields {
 							if _, ok := field.ValueOf(db.Statement.Context, elem); !ok {
@@ -332,16 +534,26 @@ func Scan(rows Rows, db *DB, mode ScanMode) {
 							}
 						}
 					}
-				} else {
+				} else if isPtr || isArrayKind {
+					// pointer elements are allocated once and appended after scanning, array elements are set after scanning
 					elem = reflect.New(reflectValueType)
+				} else {
+					// scan into the appended slice element directly instead of copying a scanned struct
+					reflectValue = reflect.Append(reflectValue, reflect.Zero(reflectValueType))
+					elem = reflectValue.Index(reflectValue.Len() - 1)
 				}
 
 				db.scanIntoStruct(rows, elem, values, fields, joinFields)
 
-				if !update {
+				if !update && (isPtr || isArrayKind) {
 					if !isPtr {
 						elem = elem.Elem()
 					}
+					for i := 1; i < ptrDepth; i++ {
+						ptr := reflect.New(elem.Type())
+						ptr.Elem().Set(elem)
+						elem = ptr
+					}
 					if isArrayKind {
 						if reflectValue.Len() >= int(db.RowsAffected) {
 							reflectValue.Index(int(db.RowsAffected - 1)).Set(elem)
@@ -28,6 +28,8 @@ type (
 	DataType string
 	// TimeType GORM time type
 	TimeType int64
+	// ActorType GORM actor type, the field is populated with the actor of the context, see the `actor` tag
+	ActorType string
 )
 
 // GORM time types
@@ -38,6 +40,12 @@ const (
 	UnixNanosecond  TimeType = 4
 )
 
+// GORM actor types
+const (
+	ActorCreated ActorType = "created"
+	ActorUpdated ActorType = "updated"
+)
+
 // GORM fields types
 const (
 	Bool   DataType = "bool"
@@ -67,6 +75,7 @@ type Field struct {
 	Readable               bool
 	AutoCreateTime         TimeType
 	AutoUpdateTime         TimeType
+	Actor                  ActorType
 	HasDefaultValue        bool
 	DefaultValue           string
 	DefaultValueInterface  interface{}
@@ -313,6 +322,15 @@ func (schema *Schema) ParseField(fieldStruct reflect.StructField) *Field {
 		}
 	}
 
+	if v, ok := field.TagSettings["ACTOR"]; ok {
+		switch actor := ActorType(strings.ToLower(v)); actor {
+		case ActorCreated, ActorUpdated:
+			field.Actor = actor
+		default:
+			schema.err = fmt.Errorf("invalid actor %q for field %s, expects created or updated", v, field.Name)
+		}
+	}
+
 	if field.GORMDataType == "" {
 		field.GORMDataType = field.DataType
 	}
@@ -30,6 +30,31 @@ const (
 // ErrUnsupportedDataType unsupported data type
 var ErrUnsupportedDataType = errors.New("unsupported data type")
 
+var softDeleteClausesMap = sync.Map{}
+
+// RegisterSoftDeleteClauses register the clauses of the soft delete mode, which are used by the fields tagged with
+// `softDelete:<mode>` whose types don't provide clauses themselves, clauses should implement the
+// Query/Update/DeleteClausesInterface
+func RegisterSoftDeleteClauses(mode string, clauses interface{}) {
+	softDeleteClausesMap.Store(strings.ToLower(mode), clauses)
+}
+
+// GetSoftDeleteClauses get the clauses of the soft delete mode, the bare `softDelete` tag uses the time mode
+func GetSoftDeleteClauses(mode string) (clauses interface{}, ok bool) {
+	if strings.EqualFold(mode, "SOFTDELETE") {
+		mode = "time"
+	}
+	return softDeleteClausesMap.Load(strings.ToLower(mode))
+}
+
+func hasClausesInterface(fieldInterface interface{}) bool {
+	switch fieldInterface.(type) {
+	case CreateClausesInterface, QueryClausesInterface, UpdateClausesInterface, DeleteClausesInterface:
+		return true
+	}
+	return false
+}
+
 type Schema struct {
 	Name                      string
 	ModelType                 reflect.Type
@@ -53,6 +78,7 @@ type Schema struct {
 	BeforeDelete, AfterDelete bool
 	BeforeSave, AfterSave     bool
 	AfterFind                 bool
+	MaterializedView          bool // marked with the `materializedView` tag, AutoMigrate skips it
 	err                       error
 	initialized               chan struct{}
 	namer                     Namer
@@ -202,7 +228,13 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 	}
 
 	for i := 0; i < modelType.NumField(); i++ {
-		if fieldStruct := modelType.Field(i); ast.IsExported(fieldStruct.Name) {
+		fieldStruct := modelType.Field(i)
+		// the model reads from a materialized view, usually marked by a blank field, e.g: _ struct{} `gorm:"materializedView"`
+		if _, ok := ParseTagSetting(fieldStruct.Tag.Get("gorm"), ";")["MATERIALIZEDVIEW"]; ok {
+			schema.MaterializedView = true
+		}
+
+		if ast.IsExported(fieldStruct.Name) {
 			if field := schema.ParseField(fieldStruct); field.EmbeddedSchema != nil {
 				schema.Fields = append(schema.Fields, field.EmbeddedSchema.Fields...)
 			} else {
@@ -350,6 +382,13 @@ func ParseWithSpecialTableName(dest interface{}, cacheStore *sync.Map, namer Nam
 
 			fieldValue := reflect.New(field.IndirectFieldType)
 			fieldInterface := fieldValue.Interface()
+			if mode, ok := field.TagSettings["SOFTDELETE"]; ok && !hasClausesInterface(fieldInterface) {
+				if fieldInterface, ok = GetSoftDeleteClauses(mode); !ok {
+					schema.err = fmt.Errorf("unsupported soft delete mode %s of field %s", mode, field.Name)
+					return schema, schema.err
+				}
+			}
+
 			if fc, ok := fieldInterface.(CreateClausesInterface); ok {
 				field.Schema.CreateClauses = append(field.Schema.CreateClauses, fc.CreateClauses(field)...)
 			}
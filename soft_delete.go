@@ -11,6 +11,11 @@ import (
 	"gorm.io/gorm/schema"
 )
 
+func init() {
+	schema.RegisterSoftDeleteClauses("time", DeletedAt{})
+	schema.RegisterSoftDeleteClauses("flag", SoftDeleteFlag{})
+}
+
 type DeletedAt sql.NullTime
 
 // Scan implements the Scanner interface.
@@ -74,6 +79,11 @@ func (sd SoftDeleteQueryClause) MergeClause(*clause.Clause) {
 }
 
 func (sd SoftDeleteQueryClause) ModifyStatement(stmt *Statement) {
+	addSoftDeleteCondition(stmt, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: sd.ZeroValue})
+}
+
+// addSoftDeleteCondition adds the condition filtering out soft deleted records once
+func addSoftDeleteCondition(stmt *Statement, cond clause.Expression) {
 	if _, ok := stmt.Clauses["soft_delete_enabled"]; !ok && !stmt.Statement.Unscoped {
 		if c, ok := stmt.Clauses["WHERE"]; ok {
 			if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) >= 1 {
@@ -88,9 +98,7 @@ func (sd SoftDeleteQueryClause) ModifyStatement(stmt *Statement) {
 			}
 		}
 
-		stmt.AddClause(clause.Where{Exprs: []clause.Expression{
-			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: sd.ZeroValue},
-		}})
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{cond}})
 		stmt.Clauses["soft_delete_enabled"] = clause.Clause{}
 	}
 }
@@ -141,29 +149,125 @@ func (sd SoftDeleteDeleteClause) MergeClause(*clause.Clause) {
 
 func (sd SoftDeleteDeleteClause) ModifyStatement(stmt *Statement) {
 	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
-		curTime := stmt.DB.NowFunc()
-		stmt.AddClause(clause.Set{{Column: clause.Column{Name: sd.Field.DBName}, Value: curTime}})
-		stmt.SetColumn(sd.Field.DBName, curTime, true)
+		setSoftDeleted(stmt, sd.Field, stmt.DB.NowFunc())
+		SoftDeleteQueryClause(sd).ModifyStatement(stmt)
+		stmt.AddClauseIfNotExists(clause.Update{})
+		stmt.Build(stmt.DB.Callback().Update().Clauses...)
+	}
+}
+
+// setSoftDeleted sets the soft delete field to the deleted value, limited to the primary keys of the dest and model
+func setSoftDeleted(stmt *Statement, field *schema.Field, value interface{}) {
+	stmt.AddClause(clause.Set{{Column: clause.Column{Name: field.DBName}, Value: value}})
+	stmt.SetColumn(field.DBName, value, true)
+
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
 
-		if stmt.Schema != nil {
-			_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
-			column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+			_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+			column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
 
 			if len(values) > 0 {
 				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
 			}
+		}
+	}
+}
 
-			if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
-				_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
-				column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+// SoftDeleteFlag soft delete clauses of the fields tagged with `softDelete:flag`, e.g. a legacy `is_deleted` column,
+// deleted records are flagged with true (or 1 for integer fields)
+//
+//	type User struct {
+//		ID        uint
+//		IsDeleted bool `gorm:"softDelete:flag"`
+//	}
+type SoftDeleteFlag struct{}
+
+func (SoftDeleteFlag) QueryClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteFlagQueryClause{Field: f}}
+}
 
-				if len(values) > 0 {
-					stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
-				}
-			}
-		}
+func (SoftDeleteFlag) UpdateClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteFlagUpdateClause{Field: f}}
+}
 
-		SoftDeleteQueryClause(sd).ModifyStatement(stmt)
+func (SoftDeleteFlag) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{SoftDeleteFlagDeleteClause{Field: f}}
+}
+
+// softDeleteFlagValue returns the flag value of the field, bool for boolean fields, otherwise 0 or 1
+func softDeleteFlagValue(f *schema.Field, deleted bool) interface{} {
+	if f.DataType == schema.Bool {
+		return deleted
+	}
+	if deleted {
+		return 1
+	}
+	return 0
+}
+
+type SoftDeleteFlagQueryClause struct {
+	Field *schema.Field
+}
+
+func (sd SoftDeleteFlagQueryClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteFlagQueryClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteFlagQueryClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteFlagQueryClause) ModifyStatement(stmt *Statement) {
+	addSoftDeleteCondition(stmt, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: sd.Field.DBName}, Value: softDeleteFlagValue(sd.Field, false)})
+}
+
+type SoftDeleteFlagUpdateClause struct {
+	Field *schema.Field
+}
+
+func (sd SoftDeleteFlagUpdateClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteFlagUpdateClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteFlagUpdateClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteFlagUpdateClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		SoftDeleteFlagQueryClause(sd).ModifyStatement(stmt)
+	}
+}
+
+type SoftDeleteFlagDeleteClause struct {
+	Field *schema.Field
+}
+
+func (sd SoftDeleteFlagDeleteClause) Name() string {
+	return ""
+}
+
+func (sd SoftDeleteFlagDeleteClause) Build(clause.Builder) {
+}
+
+func (sd SoftDeleteFlagDeleteClause) MergeClause(*clause.Clause) {
+}
+
+func (sd SoftDeleteFlagDeleteClause) ModifyStatement(stmt *Statement) {
+	if stmt.SQL.Len() == 0 && !stmt.Statement.Unscoped {
+		setSoftDeleted(stmt, sd.Field, softDeleteFlagValue(sd.Field, true))
+		SoftDeleteFlagQueryClause(sd).ModifyStatement(stmt)
 		stmt.AddClauseIfNotExists(clause.Update{})
 		stmt.Build(stmt.DB.Callback().Update().Clauses...)
 	}
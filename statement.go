@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -36,6 +37,8 @@ type Statement struct {
 	BuildClauses []string
 	// 是否启用 distinct 模式
 	Distinct bool
+	// DISTINCT ON 的列
+	DistinctOn []string
 	// select 语句
 	Selects []string // selected columns
 	// omit 语句
@@ -63,6 +66,10 @@ type Statement struct {
 	assigns      []interface{}
 	scopes       []func(*DB) *DB
 	Result       *result
+	// 是否已中止执行剩余的 callbacks，见 DB.Abort
+	aborted bool
+	// inlineVars writes integer vars as literals when building LIMIT with Config.InlineLimitOffset
+	inlineVars bool
 }
 
 type join struct {
@@ -102,7 +109,7 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 		if raw {
 			writer.WriteString(str)
 		} else {
-			stmt.DB.Dialector.QuoteTo(writer, str)
+			stmt.quoteTo(writer, str)
 		}
 	}
 
@@ -112,10 +119,10 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 			if stmt.TableExpr != nil {
 				stmt.TableExpr.Build(stmt)
 			} else {
-				write(v.Raw, stmt.Table)
+				write(v.Raw, stmt.withResolvedSchema(v.Raw, stmt.Table))
 			}
 		} else {
-			write(v.Raw, v.Name)
+			write(v.Raw, stmt.withResolvedSchema(v.Raw, v.Name))
 		}
 
 		if v.Alias != "" {
@@ -162,19 +169,122 @@ func (stmt *Statement) QuoteTo(writer clause.Writer, field interface{}) {
 	case clause.Expr:
 		v.Build(stmt)
 	case string:
-		stmt.DB.Dialector.QuoteTo(writer, v)
+		stmt.quoteTo(writer, v)
 	case []string:
 		writer.WriteByte('(')
 		for idx, d := range v {
 			if idx > 0 {
 				writer.WriteByte(',')
 			}
-			stmt.DB.Dialector.QuoteTo(writer, d)
+			stmt.quoteTo(writer, d)
 		}
 		writer.WriteByte(')')
 	default:
-		stmt.DB.Dialector.QuoteTo(writer, fmt.Sprint(field))
+		stmt.quoteTo(writer, fmt.Sprint(field))
+	}
+}
+
+// quoteTo quotes the identifier with Config.QuoteTo, falls back to the dialector if it's not set or returns false
+func (stmt *Statement) quoteTo(writer clause.Writer, str string) {
+	if stmt.DB.Config.QuoteTo != nil && stmt.DB.Config.QuoteTo(writer, str) {
+		return
 	}
+	stmt.DB.Dialector.QuoteTo(writer, str)
+}
+
+// QuoteIdentifierTo writes str as a single identifier enclosed in quote, quote characters in str are doubled to escape them,
+// it helps Config.QuoteTo to quote identifiers safely, e.g:
+//
+//	gorm.QuoteIdentifierTo(writer, "remote.users", '"') // "remote.users"
+func QuoteIdentifierTo(writer clause.Writer, str string, quote byte) {
+	writer.WriteByte(quote)
+	for idx := 0; idx < len(str); idx++ {
+		if str[idx] == quote {
+			writer.WriteByte(quote)
+		}
+		writer.WriteByte(str[idx])
+	}
+	writer.WriteByte(quote)
+}
+
+// DialectName returns the name of current dialector
+func (stmt *Statement) DialectName() string {
+	if stmt.DB != nil && stmt.DB.Dialector != nil {
+		return stmt.DB.Dialector.Name()
+	}
+	return ""
+}
+
+// addOptimizerHint adds the hint after the statement keywords, hints of the same statement are merged,
+// other expressions after the keywords (e.g: set by hints plugins) are kept before the hints,
+// it is a no-op for dialects not in clause.OptimizerHintDialects
+func (stmt *Statement) addOptimizerHint(hint clause.OptimizerHint) {
+	if len(hint.Hints) == 0 || !clause.OptimizerHintDialects[stmt.DialectName()] {
+		return
+	}
+
+	for _, name := range []string{"SELECT", "INSERT", "UPDATE", "DELETE"} {
+		c := stmt.Clauses[name]
+		merged := afterNameHint{}
+		switch existing := c.AfterNameExpression.(type) {
+		case nil:
+		case afterNameHint:
+			merged = existing
+		case clause.OptimizerHint:
+			merged.Hint = existing
+		default:
+			merged.Expression = existing
+		}
+		merged.Hint.Hints = append(append([]string(nil), merged.Hint.Hints...), hint.Hints...)
+		c.AfterNameExpression = merged
+		stmt.Clauses[name] = c
+	}
+}
+
+// afterNameHint the optimizer hint after the statement keywords, following the expression set there before
+type afterNameHint struct {
+	Expression clause.Expression
+	Hint       clause.OptimizerHint
+}
+
+func (h afterNameHint) Build(builder clause.Builder) {
+	if h.Expression != nil {
+		h.Expression.Build(builder)
+		builder.WriteByte(' ')
+	}
+	h.Hint.Build(builder)
+}
+
+// operation returns the kind of the built statement, the clauses to build come first as soft delete builds UPDATE for DELETE
+func (stmt *Statement) operation() string {
+	for _, name := range append(append([]string(nil), stmt.BuildClauses...), "INSERT", "UPDATE", "DELETE", "SELECT") {
+		switch name {
+		case "INSERT", "UPDATE", "DELETE", "SELECT":
+			if c, ok := stmt.Clauses[name]; ok && c.Expression != nil {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// addTableSample samples the table of the FROM clause, it replaces the sample set before
+func (stmt *Statement) addTableSample(sample clause.TableSample) {
+	from, _ := stmt.Clauses["FROM"].Expression.(clause.From)
+	from.Sample = &sample
+	stmt.AddClause(from)
+}
+
+// withResolvedSchema prefixes the table name with the schema resolved by Config.SchemaResolver
+func (stmt *Statement) withResolvedSchema(raw bool, table string) string {
+	if raw || table == "" || stmt.DB.Config.SchemaResolver == nil || strings.Contains(table, ".") {
+		return table
+	}
+
+	if schemaName := stmt.DB.Config.SchemaResolver(stmt.Context); schemaName != "" {
+		return schemaName + "." + table
+	}
+	return table
 }
 
 // Quote returns quoted value
@@ -186,11 +296,25 @@ func (stmt *Statement) Quote(field interface{}) string {
 
 // AddVar add var
 func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
+	if len(vars) > 1 && !stmt.checkMaxVars(len(vars)) {
+		return
+	}
+
 	for idx, v := range vars {
 		if idx > 0 {
 			writer.WriteByte(',')
 		}
 
+		// non-negative integers are written as literals when inlining, other vars are still bound as parameters
+		if n, ok := v.(int); ok && stmt.inlineVars {
+			if n < 0 {
+				stmt.AddError(fmt.Errorf("%w: invalid limit or offset %d", ErrInvalidData, n))
+				continue
+			}
+			writer.WriteString(strconv.Itoa(n))
+			continue
+		}
+
 		switch v := v.(type) {
 		case sql.NamedArg:
 			stmt.Vars = append(stmt.Vars, v.Value)
@@ -263,7 +387,7 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 				} else if rv.Type().Elem() == reflect.TypeOf(uint8(0)) {
 					stmt.Vars = append(stmt.Vars, v)
 					stmt.DB.Dialector.BindVarTo(writer, stmt, v)
-				} else {
+				} else if stmt.checkMaxVars(rv.Len()) {
 					writer.WriteByte('(')
 					for i := 0; i < rv.Len(); i++ {
 						if i > 0 {
@@ -281,6 +405,19 @@ func (stmt *Statement) AddVar(writer clause.Writer, vars ...interface{}) {
 	}
 }
 
+// checkMaxVars reports whether a list of n vars (e.g. values of IN) can be added without exceeding Config.MaxVars,
+// the list isn't built if not
+func (stmt *Statement) checkMaxVars(n int) bool {
+	if stmt.DB.MaxVars <= 0 || len(stmt.Vars)+n <= stmt.DB.MaxVars {
+		return true
+	}
+
+	if !errors.Is(stmt.DB.Error, ErrTooManyVars) {
+		stmt.DB.AddError(fmt.Errorf("%w: binding %d more vars exceeds the limit %d", ErrTooManyVars, n, stmt.DB.MaxVars))
+	}
+	return false
+}
+
 // AddClause add clause
 func (stmt *Statement) AddClause(v clause.Interface) {
 	if optimizer, ok := v.(StatementModifier); ok {
@@ -503,11 +640,19 @@ func (stmt *Statement) Build(clauses ...string) {
 			}
 
 			firstClauseWritten = true
+			// LIMIT/OFFSET numbers are rendered as literals instead of placeholders, see Config.InlineLimitOffset
+			stmt.inlineVars = name == "LIMIT" && stmt.DB.InlineLimitOffset
+
+			if orderBy, ok := c.Expression.(clause.OrderBy); ok && name == "ORDER BY" && stmt.DB.DefaultNullsOrder != clause.NullsDefault {
+				c.Expression = orderBy.WithDefaultNulls(stmt.DB.DefaultNullsOrder)
+			}
+
 			if b, ok := stmt.DB.ClauseBuilders[name]; ok {
 				b(c, stmt)
 			} else {
 				c.Build(stmt)
 			}
+			stmt.inlineVars = false
 		}
 	}
 }
@@ -542,6 +687,7 @@ func (stmt *Statement) clone() *Statement {
 		ReflectValue:         stmt.ReflectValue,
 		Clauses:              map[string]clause.Clause{},
 		Distinct:             stmt.Distinct,
+		DistinctOn:           stmt.DistinctOn,
 		Selects:              stmt.Selects,
 		Omits:                stmt.Omits,
 		ColumnMapping:        stmt.ColumnMapping,
@@ -1,9 +1,13 @@
 package tests_test
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -423,3 +427,39 @@ func TestMany2ManyDuplicateBelongsToAssociation(t *testing.T) {
 	AssertEqual(t, nil, err)
 	AssertEqual(t, user2, findUser2)
 }
+
+func TestMany2ManyAppendWithOnConflict(t *testing.T) {
+	user := *GetUser("many2many-append-on-conflict", Config{Languages: 2})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	var joinSQLs []string
+	tx := DB.Session(&gorm.Session{Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			if sql, _ := fc(); strings.Contains(sql, "user_speaks") && strings.HasPrefix(sql, "INSERT") {
+				joinSQLs = append(joinSQLs, sql)
+			}
+		},
+	}})
+
+	onConflict := clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "language_code"}}, DoNothing: true}
+	if err := tx.Model(&user).Association("Languages").Append(user.Languages, onConflict); err != nil {
+		t.Fatalf("should append linked languages again without error, got %v", err)
+	}
+	AssertAssociationCount(t, user, "Languages", 2, "AfterAppendWithOnConflict")
+
+	if len(joinSQLs) != 1 {
+		t.Fatalf("should insert the join rows once, got %v", joinSQLs)
+	}
+	if DB.Dialector.Name() != "mysql" && !regexp.MustCompile(`ON CONFLICT \(.user_id.,.language_code.\) DO NOTHING`).MatchString(joinSQLs[0]) {
+		t.Errorf("should insert the join rows with the specified on conflict, got %v", joinSQLs[0])
+	}
+
+	language := Language{Code: "many2many-append-on-conflict", Name: "many2many-append-on-conflict"}
+	if err := tx.Model(&user).Association("Languages").Append(&language, onConflict); err != nil {
+		t.Fatalf("should append new language with on conflict, got %v", err)
+	}
+	AssertAssociationCount(t, user, "Languages", 3, "AfterAppendNewWithOnConflict")
+}
@@ -252,3 +252,32 @@ func TestCallbacksRemove(t *testing.T) {
 		t.Errorf("callbacks tests failed, got %v", msg)
 	}
 }
+
+func TestCallbacksDebug(t *testing.T) {
+	db, _ := gorm.Open(nil, nil)
+	createCallback := db.Callback().Create()
+
+	createCallback.Register("c1", c1)
+	createCallback.Before("c1").Register("c2", c2)
+	createCallback.After("*").Register("c3", c3)
+	createCallback.Before("*").Register("c4", c4)
+	db.Callback().Query().Register("q1", c1)
+
+	pipelines := db.Callback().Debug()
+	if !reflect.DeepEqual(pipelines["create"], []string{"c4", "c2", "c1", "c3"}) {
+		t.Errorf("should return the create callbacks in execution order, got %v", pipelines["create"])
+	}
+	if !reflect.DeepEqual(pipelines["query"], []string{"q1"}) {
+		t.Errorf("should return the query callbacks, got %v", pipelines["query"])
+	}
+	for _, name := range []string{"update", "delete", "row", "raw"} {
+		if v, ok := pipelines[name]; !ok || len(v) != 0 {
+			t.Errorf("should return empty callbacks for %v, got %v", name, v)
+		}
+	}
+
+	pipelines["create"][0] = "changed"
+	if db.Callback().Debug()["create"][0] != "c4" {
+		t.Errorf("should not change the registered callbacks")
+	}
+}
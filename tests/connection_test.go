@@ -1,11 +1,18 @@
 package tests_test
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestWithSingleConnection(t *testing.T) {
@@ -44,3 +51,229 @@ func getSetSQL(driverName string) (string, string) {
 		return "", ""
 	}
 }
+
+func TestWarmupConns(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{WarmupConns: 2})
+	if err != nil {
+		t.Fatalf("failed to open connection, got error %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, got error %v", err)
+	}
+	defer sqlDB.Close()
+
+	if stats := sqlDB.Stats(); stats.OpenConnections < 2 || stats.Idle < 2 {
+		t.Errorf("should have 2 warmed up connections, but got %+v", stats)
+	}
+}
+
+func TestWarmupConnsTimeout(t *testing.T) {
+	sqlDB := sql.OpenDB(&fakeConnector{Hang: true})
+	start := time.Now()
+	_, err := gorm.Open(DummyDialector{}, &gorm.Config{
+		ConnPool: sqlDB, DisableAutomaticPing: true, AutomaticPingTimeout: 50 * time.Millisecond, WarmupConns: 2,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("should return the context error when warmup hangs, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("should stop warming up after the timeout, took %v", elapsed)
+	}
+	if err := sqlDB.Ping(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Errorf("should close the db when warmup failed, got %v", err)
+	}
+}
+
+func TestAutomaticPingTimeout(t *testing.T) {
+	sqlDB := sql.OpenDB(&fakeConnector{Hang: true})
+	start := time.Now()
+	_, err := gorm.Open(DummyDialector{}, &gorm.Config{ConnPool: sqlDB, AutomaticPingTimeout: 50 * time.Millisecond})
+	if !errors.Is(err, gorm.ErrPingTimeout) {
+		t.Fatalf("should return ErrPingTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("should stop pinging after the timeout, took %v", elapsed)
+	}
+	if err := sqlDB.Ping(); err == nil || !strings.Contains(err.Error(), "database is closed") {
+		t.Errorf("should close the db when ping failed, got %v", err)
+	}
+
+	refused := errors.New("connection refused")
+	sqlDB = sql.OpenDB(&fakeConnector{ConnectErr: refused})
+	_, err = gorm.Open(DummyDialector{}, &gorm.Config{ConnPool: sqlDB, AutomaticPingTimeout: time.Second})
+	if !errors.Is(err, refused) || errors.Is(err, gorm.ErrPingTimeout) {
+		t.Errorf("should return the connection error instead of timeout, got %v", err)
+	}
+}
+
+func TestPingContext(t *testing.T) {
+	if err := DB.PingContext(context.Background()); err != nil {
+		t.Fatalf("failed to ping, got error %v", err)
+	}
+
+	sqlDB := sql.OpenDB(&fakeConnector{Hang: true})
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(DummyDialector{}, &gorm.Config{ConnPool: sqlDB, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := db.PingContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("should return the context error when ping hangs, got %v", err)
+	}
+}
+
+// newSlowConnector returns a connector whose statements block until the context is done, except `FAST` queries
+func newSlowConnector() *fakeConnector {
+	return &fakeConnector{
+		Exec: func(ctx context.Context, _ int, _ string) (driver.Result, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		Query: func(ctx context.Context, _ int, query string) (driver.Rows, error) {
+			if !strings.HasPrefix(query, "FAST") {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return &fakeRows{columns: []string{"name"}, values: [][]driver.Value{{"fast"}}}, nil
+		},
+	}
+}
+
+func TestDefaultQueryTimeout(t *testing.T) {
+	for _, prepareStmt := range []bool{false, true} {
+		sqlDB := sql.OpenDB(newSlowConnector())
+		db, err := gorm.Open(DummyDialector{}, &gorm.Config{
+			ConnPool: sqlDB, DisableAutomaticPing: true, SkipDefaultTransaction: true,
+			PrepareStmt: prepareStmt, DefaultQueryTimeout: 50 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("failed to open db, got error %v", err)
+		}
+
+		start := time.Now()
+		if err := db.Exec("SLOW").Error; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("should abort the statement after the timeout with prepare stmt %v, got %v", prepareStmt, err)
+		}
+
+		var names []string
+		if err := db.Raw("SLOW").Scan(&names).Error; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("should abort the query after the timeout with prepare stmt %v, got %v", prepareStmt, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("should abort the statements after the timeout, took %v", elapsed)
+		}
+
+		// the earlier deadline of the caller is kept
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		tx := db.Session(&gorm.Session{})
+		tx.Config.DefaultQueryTimeout = time.Hour
+		if err := tx.WithContext(ctx).Exec("SLOW").Error; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("should abort the statement with the caller's deadline, got %v", err)
+		}
+		cancel()
+
+		if err := db.Raw("FAST").Scan(&names).Error; err != nil || len(names) != 1 {
+			t.Errorf("should query within the timeout, got %v, %v", err, names)
+		}
+
+		// rows are read after the call, the timeout isn't applied
+		rows, err := db.Raw("FAST").Rows()
+		if err != nil {
+			t.Fatalf("failed to query rows, got error %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if !rows.Next() || rows.Err() != nil {
+			t.Errorf("rows should not be cancelled by the query timeout, got %v", rows.Err())
+		}
+		rows.Close()
+		sqlDB.Close()
+	}
+}
+
+func TestConnAcquireTimeout(t *testing.T) {
+	sqlDB := sql.OpenDB(newSlowConnector())
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+
+	db, err := gorm.Open(DummyDialector{}, &gorm.Config{
+		ConnPool: sqlDB, DisableAutomaticPing: true, SkipDefaultTransaction: true,
+		ConnAcquireTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	var names []string
+	if err := db.Raw("FAST").Scan(&names).Error; err != nil || len(names) != 1 {
+		t.Fatalf("should query with an available connection, got %v, %v", err, names)
+	}
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("should release the connection after scanning, got %v in use", inUse)
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Raw("FAST").Scan(&names).Error
+	}); err != nil {
+		t.Fatalf("should query in a transaction, got %v", err)
+	}
+	if inUse := sqlDB.Stats().InUse; inUse != 0 {
+		t.Errorf("should release the connection after committing, got %v in use", inUse)
+	}
+
+	// the only connection is held by the rows
+	rows, err := db.Raw("FAST").Rows()
+	if err != nil {
+		t.Fatalf("failed to query rows, got error %v", err)
+	}
+
+	start := time.Now()
+	if err := db.Raw("FAST").Scan(&names).Error; !errors.Is(err, gorm.ErrConnAcquireTimeout) {
+		t.Errorf("should return ErrConnAcquireTimeout when the pool is exhausted, got %v", err)
+	}
+	if err := db.Exec("SLOW").Error; !errors.Is(err, gorm.ErrConnAcquireTimeout) {
+		t.Errorf("should return ErrConnAcquireTimeout when the pool is exhausted, got %v", err)
+	}
+	if err := db.Raw("FAST").Row().Scan(&names); !errors.Is(err, gorm.ErrConnAcquireTimeout) {
+		t.Errorf("should return ErrConnAcquireTimeout from the row when the pool is exhausted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("should fail fast after the acquire timeout, took %v", elapsed)
+	}
+
+	// the earlier deadline of the caller is returned as it is
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if err := db.WithContext(ctx).Raw("FAST").Scan(&names).Error; !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, gorm.ErrConnAcquireTimeout) {
+		t.Errorf("should return the caller's context error, got %v", err)
+	}
+	cancel()
+
+	if !rows.Next() || rows.Err() != nil {
+		t.Errorf("rows holding the connection should be readable, got %v", rows.Err())
+	}
+	rows.Close()
+
+	names = nil
+	if err := db.Raw("FAST").Scan(&names).Error; err != nil || len(names) != 1 {
+		t.Errorf("should query after the connection is released, got %v, %v", err, names)
+	}
+
+	if sqlDB2, err := db.DB(); err != nil || sqlDB2 != sqlDB {
+		t.Errorf("should return the underlying *sql.DB, got %v, %v", sqlDB2, err)
+	}
+
+	if err := db.Session(&gorm.Session{PrepareStmt: true}).Error; !errors.Is(err, gorm.ErrNotImplemented) {
+		t.Errorf("should not prepare statements with ConnAcquireTimeout, got %v", err)
+	}
+
+	if _, err := gorm.Open(DummyDialector{}, &gorm.Config{
+		ConnPool: sqlDB, DisableAutomaticPing: true, ConnAcquireTimeout: time.Second, PrepareStmt: true,
+	}); !errors.Is(err, gorm.ErrNotImplemented) {
+		t.Errorf("should not open with both ConnAcquireTimeout and PrepareStmt, got %v", err)
+	}
+}
@@ -3,8 +3,11 @@ package tests_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"gorm.io/driver/mysql"
@@ -172,3 +175,238 @@ func TestConnPoolWrapper(t *testing.T) {
 		t.Fatalf("Should be able to find committed record, but got %v", err)
 	}
 }
+
+type routeKeyConnPool struct {
+	*sql.DB
+	keys []string
+}
+
+func (c *routeKeyConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	key, _ := gorm.RouteKeyFrom(ctx)
+	c.keys = append(c.keys, key)
+	return c.DB.QueryContext(ctx, query, args...)
+}
+
+func TestRouteKey(t *testing.T) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, got error %v", err)
+	}
+
+	conn := &routeKeyConnPool{DB: sqlDB}
+	tx := DB.Session(&gorm.Session{Initialized: true})
+	tx.Statement.ConnPool = conn
+	tx = tx.Session(&gorm.Session{})
+
+	var users []User
+	if err := tx.RouteKey("replica").Where("name = ?", "route_key").Find(&users).Error; err != nil {
+		t.Fatalf("failed to find users, got error %v", err)
+	}
+
+	if err := tx.Where("name = ?", "route_key").Find(&users).Error; err != nil {
+		t.Fatalf("failed to find users, got error %v", err)
+	}
+
+	AssertEqual(t, conn.keys, []string{"replica", ""})
+
+	if _, ok := gorm.RouteKeyFrom(context.Background()); ok {
+		t.Errorf("should not get route key from a context without it")
+	}
+}
+
+// writerConnPool records the pool executing queries, it routes statements to the writer with gorm.WriterSelector
+type writerConnPool struct {
+	*sql.DB
+	name string
+	used *[]string
+}
+
+func (c *writerConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	*c.used = append(*c.used, c.name)
+	return c.DB.QueryContext(ctx, query, args...)
+}
+
+func (c *writerConnPool) GetDBConn() (*sql.DB, error) {
+	return c.DB, nil
+}
+
+func (c *writerConnPool) Writer() gorm.ConnPool {
+	return &writerConnPool{DB: c.DB, name: "writer", used: c.used}
+}
+
+func TestUseWriter(t *testing.T) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, got error %v", err)
+	}
+
+	var used []string
+	tx := DB.Session(&gorm.Session{Initialized: true})
+	tx.Statement.ConnPool = &writerConnPool{DB: sqlDB, name: "reader", used: &used}
+	tx = tx.Session(&gorm.Session{})
+
+	var users []User
+	tx.Where("name = ?", "use_writer").Find(&users)
+	tx.WriteDB().Where("name = ?", "use_writer").Find(&users)
+	tx.Where("name = ?", "use_writer").Find(&users)
+
+	writerTx := tx.Session(&gorm.Session{UseWriter: true})
+	writerTx.Where("name = ?", "use_writer").Find(&users)
+	writerTx.Where("name = ?", "use_writer").Find(&users)
+	AssertEqual(t, used, []string{"reader", "writer", "reader", "writer", "writer"})
+
+	// the transaction takes precedence, statements use its connection
+	used = nil
+	if err := tx.Transaction(func(tx *gorm.DB) error {
+		return tx.WriteDB().Where("name = ?", "use_writer").Find(&users).Error
+	}); err != nil {
+		t.Fatalf("failed to query in transaction, got error %v", err)
+	}
+	if len(used) != 0 {
+		t.Errorf("should query with the connection of the transaction, got %v", used)
+	}
+}
+
+type localSettingsTx struct {
+	*sql.Tx
+	got *[]string
+}
+
+func (c *localSettingsTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if strings.HasPrefix(query, "SET ") {
+		*c.got = append(*c.got, query)
+		return driver.RowsAffected(0), nil
+	}
+	return c.Tx.ExecContext(ctx, query, args...)
+}
+
+type localSettingsConnPool struct {
+	*sql.DB
+	got []string
+}
+
+func (c *localSettingsConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := c.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &localSettingsTx{Tx: tx, got: &c.got}, nil
+}
+
+// postgresNamedDialector reports the wrapped dialector as postgres to enable postgres only features
+type postgresNamedDialector struct {
+	gorm.Dialector
+}
+
+func (postgresNamedDialector) Name() string { return "postgres" }
+
+func TestWithLocalSettings(t *testing.T) {
+	if DB.Dialector.Name() != "postgres" {
+		var users []User
+		if err := DB.WithLocalSettings(map[string]string{"statement_timeout": "5s"}).Find(&users).Error; !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("should return ErrUnsupportedDriver for %s, got %v", DB.Dialector.Name(), err)
+		}
+	}
+
+	db, err := gorm.Open(postgresNamedDialector{Dialector: DB.Dialector}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, got error %v", err)
+	}
+	defer sqlDB.Close()
+
+	conn := &localSettingsConnPool{DB: sqlDB}
+	tx := db.Session(&gorm.Session{Initialized: true})
+	tx.Statement.ConnPool = conn
+	tx = tx.Session(&gorm.Session{})
+
+	user := *GetUser("with_local_settings", Config{})
+	if err := tx.WithLocalSettings(map[string]string{"work_mem": "64MB", "statement_timeout": "5s"}).Create(&user).Error; err != nil {
+		t.Fatalf("failed to create with local settings, got error %v", err)
+	}
+	AssertEqual(t, conn.got, []string{"SET LOCAL statement_timeout = '5s'", "SET LOCAL work_mem = '64MB'"})
+
+	conn.got = nil
+	if err := tx.Transaction(func(tx *gorm.DB) error {
+		var users []User
+		return tx.WithLocalSettings(map[string]string{"app.name": "it's"}).Find(&users, "name = ?", user.Name).Error
+	}); err != nil {
+		t.Fatalf("failed to find with local settings in transaction, got error %v", err)
+	}
+	AssertEqual(t, conn.got, []string{"SET LOCAL app.name = 'it''s'"})
+
+	// outside a transaction, the settings are issued on a pinned connection, which sqlite doesn't support
+	var users []User
+	if err := tx.WithLocalSettings(map[string]string{"statement_timeout": "5s"}).Find(&users).Error; err == nil || !strings.Contains(err.Error(), "SET") {
+		t.Errorf("should issue the settings on a pinned connection, got error %v", err)
+	}
+
+	if err := tx.Find(&users, "name = ?", user.Name).Error; err != nil || len(users) != 1 {
+		t.Errorf("the pinned connection should be released, got %v users, error %v", len(users), err)
+	}
+
+	if err := tx.WithLocalSettings(map[string]string{"statement_timeout; DROP": "5s"}).Find(&users).Error; err == nil {
+		t.Errorf("should return error for invalid setting name")
+	}
+
+	if err := tx.WithLocalSettings(map[string]string{"statement_timeout": "5s\\' OR 1"}).Find(&users).Error; err == nil {
+		t.Errorf("should return error for invalid setting value")
+	}
+}
+
+var errRowsClose = errors.New("rows close error")
+
+// newCloseErrConnector returns a connector whose queries return two rows and fail when closed,
+// like a deferred network error surfacing at close
+func newCloseErrConnector() *fakeConnector {
+	return &fakeConnector{
+		Query: func(_ context.Context, _ int, query string) (driver.Rows, error) {
+			if strings.HasPrefix(query, "SELECT * ") {
+				return &fakeRows{
+					columns:  []string{"id", "name"},
+					values:   [][]driver.Value{{int64(1), "close_error"}, {int64(2), "close_error"}},
+					closeErr: errRowsClose,
+				}, nil
+			}
+			return &fakeRows{columns: []string{"name"}, values: [][]driver.Value{{"close_error"}, {"close_error"}}, closeErr: errRowsClose}, nil
+		},
+	}
+}
+
+func TestRowsCloseError(t *testing.T) {
+	sqlDB := sql.OpenDB(newCloseErrConnector())
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(DummyDialector{}, &gorm.Config{ConnPool: sqlDB, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	// the rows are closed after scanning the first row without reaching the end
+	var user User
+	if err := db.Take(&user).Error; !errors.Is(err, errRowsClose) {
+		t.Errorf("should return the rows close error, got %v", err)
+	}
+	if user.ID != 1 || user.Name != "close_error" {
+		t.Errorf("should scan the row before closing, got %+v", user)
+	}
+
+	if name, err := gorm.ScalarValue[string](db.Model(&User{}), "name"); !errors.Is(err, errRowsClose) || name != "close_error" {
+		t.Errorf("should return the rows close error with ScalarValue, got %v, %v", name, err)
+	}
+
+	ignored := db.Session(&gorm.Session{})
+	ignored.Config.IgnoreRowsCloseError = true
+	user = User{}
+	if err := ignored.Take(&user).Error; err != nil || user.ID != 1 {
+		t.Errorf("should ignore the rows close error, got %v, %+v", err, user)
+	}
+
+	if name, err := gorm.ScalarValue[string](ignored.Model(&User{}), "name"); err != nil || name != "close_error" {
+		t.Errorf("should ignore the rows close error with ScalarValue, got %v, %v", name, err)
+	}
+}
@@ -1,13 +1,16 @@
 package tests_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jinzhu/now"
+	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
@@ -100,6 +103,115 @@ func TestCreateInBatchesWithDefaultSize(t *testing.T) {
 	}
 }
 
+func TestCreateWithDisableBatching(t *testing.T) {
+	var inserts int
+	countInserts := Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			if sql, _ := fc(); strings.HasPrefix(sql, "INSERT INTO") && strings.Contains(sql, "languages") {
+				inserts++
+			}
+		},
+	}
+
+	langs := []Language{
+		{Code: "disable_batching_1", Name: "disable_batching"},
+		{Code: "disable_batching_2", Name: "disable_batching"},
+		{Code: "disable_batching_3", Name: "disable_batching"},
+	}
+	result := DB.Session(&gorm.Session{CreateBatchSize: 10, DisableBatching: true, Logger: countInserts}).Create(&langs)
+	if result.Error != nil || result.RowsAffected != 3 {
+		t.Fatalf("failed to create with batching disabled, got %v, %v", result.Error, result.RowsAffected)
+	}
+	if inserts != 3 {
+		t.Errorf("should insert one row per statement, got %v statements", inserts)
+	}
+
+	inserts = 0
+	langs = []Language{{Code: "disable_batching_4", Name: "disable_batching"}, {Code: "disable_batching_5", Name: "disable_batching"}}
+	if err := DB.Session(&gorm.Session{Logger: countInserts}).Create(&langs).Error; err != nil {
+		t.Fatalf("failed to create, got %v", err)
+	}
+	if inserts != 1 {
+		t.Errorf("should insert rows in one statement by default, got %v statements", inserts)
+	}
+
+	var count int64
+	DB.Model(&Language{}).Where("name = ?", "disable_batching").Count(&count)
+	AssertEqual(t, count, 5)
+}
+
+func TestCreateInBatchesWithBatchTransaction(t *testing.T) {
+	langs := []Language{
+		{Code: "batch_tx_1", Name: "batch_tx"}, {Code: "batch_tx_2", Name: "batch_tx"},
+		{Code: "batch_tx_3", Name: "batch_tx"}, {Code: "batch_tx_4", Name: "batch_tx"},
+		{Code: "batch_tx_5", Name: "batch_tx"}, {Code: "batch_tx_1", Name: "batch_tx"},
+	}
+
+	result := DB.Session(&gorm.Session{BatchTransaction: true}).CreateInBatches(&langs, 2)
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "batch 3 failed, 2 batches committed") {
+		t.Fatalf("should report the committed batches, got %v", result.Error)
+	}
+
+	if result.RowsAffected != 4 {
+		t.Errorf("rows affected should be 4, got %v", result.RowsAffected)
+	}
+
+	var codes []string
+	DB.Model(&Language{}).Where("name = ?", "batch_tx").Order("code").Pluck("code", &codes)
+	AssertEqual(t, codes, []string{"batch_tx_1", "batch_tx_2", "batch_tx_3", "batch_tx_4"})
+
+	DB.Where("name = ?", "batch_tx").Delete(&Language{})
+	if err := DB.CreateInBatches(&langs, 2).Error; err == nil {
+		t.Fatalf("should return error for duplicated code")
+	}
+
+	var count int64
+	if DB.Model(&Language{}).Where("name = ?", "batch_tx").Count(&count); count != 0 {
+		t.Errorf("should rollback all batches without batch transaction, got %v rows", count)
+	}
+}
+
+func TestCreateWithMaxVars(t *testing.T) {
+	var inserts int
+	tx := DB.Session(&gorm.Session{Logger: Tracer{
+		Logger: DB.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			if sql, _ := fc(); strings.HasPrefix(sql, "INSERT") {
+				inserts++
+			}
+		},
+	}})
+	tx.Config.MaxVars = 5
+
+	langs := make([]Language, 5)
+	for i := range langs {
+		langs[i] = Language{Code: fmt.Sprintf("max_vars_%v", i), Name: fmt.Sprintf("max_vars_%v", i)}
+	}
+
+	// two vars per row, at most two rows per batch
+	if result := tx.Create(&langs); result.Error != nil || result.RowsAffected != 5 {
+		t.Fatalf("failed to create with max vars, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	if inserts != 3 {
+		t.Errorf("should split into 3 batches, but got %v", inserts)
+	}
+
+	inserts = 0
+	if result := tx.Where("code LIKE ?", "max_vars_%").Delete(&Language{}); result.Error != nil || result.RowsAffected != 5 {
+		t.Fatalf("failed to delete languages, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	if result := tx.CreateInBatches(&langs, 4); result.Error != nil || result.RowsAffected != 5 {
+		t.Fatalf("failed to create in batches with max vars, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	if inserts != 3 {
+		t.Errorf("should shrink the batch size to 2, but got %v batches", inserts)
+	}
+}
+
 func TestCreateFromMap(t *testing.T) {
 	if err := DB.Model(&User{}).Create(map[string]interface{}{"Name": "create_from_map", "Age": 18}).Error; err != nil {
 		t.Fatalf("failed to create data from map, got error: %v", err)
@@ -360,6 +472,32 @@ func TestCreateEmptySlice(t *testing.T) {
 	}
 }
 
+func TestCreateEmptySliceWithAllowEmptyCreate(t *testing.T) {
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.AllowEmptyCreate = true
+
+	data := []User{}
+	if result := tx.Create(&data); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("create empty slice should be no-op, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	sliceMap := []map[string]interface{}{}
+	if result := tx.Model(&User{}).Create(&sliceMap); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("create empty slice of map should be no-op, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+
+	for _, db := range []*gorm.DB{DB, tx} {
+		if result := db.CreateInBatches(&data, 10); result.Error != nil || result.RowsAffected != 0 {
+			t.Errorf("create empty batch should be no-op, got error %v, rows affected %v", result.Error, result.RowsAffected)
+		}
+	}
+
+	batchTx := tx.Session(&gorm.Session{CreateBatchSize: 10})
+	if result := batchTx.Create(&data); result.Error != nil || result.RowsAffected != 0 {
+		t.Errorf("create empty slice with batch size should be no-op, got error %v, rows affected %v", result.Error, result.RowsAffected)
+	}
+}
+
 func TestCreateInvalidSlice(t *testing.T) {
 	users := []*User{
 		GetUser("invalid_slice_1", Config{}),
@@ -791,3 +929,202 @@ func TestCreateFromMapWithTable(t *testing.T) {
 		t.Errorf("failed to create data from map with table, @id != id")
 	}
 }
+
+type DefaultValuesRow struct {
+	ID uint
+}
+
+func TestCreateWithDefaultValuesOnly(t *testing.T) {
+	DB.Migrator().DropTable(&DefaultValuesRow{})
+	if err := DB.AutoMigrate(&DefaultValuesRow{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	row := DefaultValuesRow{}
+	if err := DB.Create(&row).Error; err != nil || row.ID == 0 {
+		t.Fatalf("failed to create row with default values only, got %+v, error %v", row, err)
+	}
+
+	rows := []DefaultValuesRow{{}, {}, {}}
+	if err := DB.Create(&rows).Error; err != nil {
+		t.Fatalf("failed to create rows with default values only, got error %v", err)
+	}
+
+	var count int64
+	DB.Model(&DefaultValuesRow{}).Count(&count)
+	AssertEqual(t, count, int64(4))
+
+	if rows[0].ID == 0 || rows[0].ID == rows[1].ID || rows[1].ID == rows[2].ID {
+		t.Errorf("should create each row, got %+v", rows)
+	}
+
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open mysql dialector, got error %v", err)
+	}
+
+	if sql := mysqlDB.Create(&DefaultValuesRow{}).Statement.SQL.String(); sql != "INSERT INTO `default_values_rows` VALUES()" {
+		t.Errorf("should create with VALUES() for mysql, got %v", sql)
+	}
+
+	if sql := mysqlDB.Create(&[]DefaultValuesRow{{}, {}}).Statement.SQL.String(); sql != "INSERT INTO `default_values_rows` (`id`) VALUES (DEFAULT),(DEFAULT)" {
+		t.Errorf("should create rows with DEFAULT for mysql, got %v", sql)
+	}
+}
+
+func TestCreateAndUpdateWithActor(t *testing.T) {
+	type actorKey struct{}
+	type ActorArticle struct {
+		ID        uint
+		Title     string
+		CreatedBy string `gorm:"actor:created"`
+		UpdatedBy string `gorm:"actor:updated"`
+	}
+
+	DB.Migrator().DropTable(&ActorArticle{})
+	if err := DB.AutoMigrate(&ActorArticle{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.ActorFunc = func(ctx context.Context) (interface{}, bool) {
+		actor, ok := ctx.Value(actorKey{}).(string)
+		return actor, ok
+	}
+
+	article := ActorArticle{Title: "actor"}
+	if err := tx.WithContext(context.WithValue(context.Background(), actorKey{}, "jinzhu")).Create(&article).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	AssertEqual(t, article.CreatedBy, "jinzhu")
+	AssertEqual(t, article.UpdatedBy, "jinzhu")
+
+	ctx := context.WithValue(context.Background(), actorKey{}, "tom")
+	if err := tx.WithContext(ctx).Model(&article).Update("title", "actor_map").Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	AssertEqual(t, article.UpdatedBy, "tom")
+
+	var result ActorArticle
+	tx.First(&result, article.ID)
+	AssertEqual(t, result.CreatedBy, "jinzhu")
+	AssertEqual(t, result.UpdatedBy, "tom")
+
+	ctx = context.WithValue(context.Background(), actorKey{}, "bob")
+	if err := tx.WithContext(ctx).Model(&article).Updates(ActorArticle{Title: "actor_struct"}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	tx.First(&result, article.ID)
+	AssertEqual(t, result.CreatedBy, "jinzhu")
+	AssertEqual(t, result.UpdatedBy, "bob")
+
+	// no-op without an actor of the context
+	if err := tx.Model(&article).Update("title", "no_actor").Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	noActor := ActorArticle{Title: "no_actor"}
+	tx.Create(&noActor)
+	tx.First(&result, article.ID)
+	AssertEqual(t, result.UpdatedBy, "bob")
+	var noActorResult ActorArticle
+	tx.First(&noActorResult, noActor.ID)
+	AssertEqual(t, noActorResult.CreatedBy, "")
+	AssertEqual(t, noActorResult.UpdatedBy, "")
+}
+
+func TestBindInterceptors(t *testing.T) {
+	type NormalizedAccount struct {
+		ID       uint
+		Email    string  `gorm:"normalize:email"`
+		Nickname *string `gorm:"normalize:trim"`
+		Note     string
+	}
+
+	DB.Migrator().DropTable(&NormalizedAccount{})
+	if err := DB.AutoMigrate(&NormalizedAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.BindInterceptors = map[string]func(value interface{}) interface{}{
+		"email": func(value interface{}) interface{} { return strings.ToLower(strings.TrimSpace(value.(string))) },
+		"trim":  func(value interface{}) interface{} { return strings.TrimSpace(value.(string)) },
+	}
+
+	nickname := "  jinzhu  "
+	accounts := []NormalizedAccount{{Email: " JinZhu@Example.COM", Nickname: &nickname, Note: " note "}, {Email: "Tom@Example.com"}}
+	if err := tx.Create(&accounts).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	var result NormalizedAccount
+	tx.First(&result, accounts[0].ID)
+	AssertEqual(t, result.Email, "jinzhu@example.com")
+	AssertEqual(t, *result.Nickname, "jinzhu")
+	AssertEqual(t, result.Note, " note ")
+
+	result = NormalizedAccount{}
+	tx.First(&result, accounts[1].ID)
+	AssertEqual(t, result.Email, "tom@example.com")
+	AssertEqual(t, result.Nickname, nil)
+
+	if err := tx.Model(&accounts[1]).Updates(map[string]interface{}{"email": "BOB@Example.com", "nickname": "  bob "}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	result = NormalizedAccount{}
+	tx.First(&result, accounts[1].ID)
+	AssertEqual(t, result.Email, "bob@example.com")
+	AssertEqual(t, *result.Nickname, "bob")
+
+	if err := tx.Model(&accounts[1]).Updates(NormalizedAccount{Email: "Alice@Example.com"}).Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	result = NormalizedAccount{}
+	tx.First(&result, accounts[1].ID)
+	AssertEqual(t, result.Email, "alice@example.com")
+
+	if err := tx.Model(&accounts[1]).Update("email", gorm.Expr("UPPER(email)")).Error; err != nil {
+		t.Fatalf("failed to update with expression, got error %v", err)
+	}
+	result = NormalizedAccount{}
+	tx.First(&result, accounts[1].ID)
+	AssertEqual(t, result.Email, "ALICE@EXAMPLE.COM")
+}
+
+func TestCreateInPartition(t *testing.T) {
+	DB.Migrator().DropTable("languages_partition")
+	if err := DB.Table("languages_partition").AutoMigrate(&Language{}); err != nil {
+		t.Fatalf("failed to create partition table, got %v", err)
+	}
+
+	lang := Language{Code: "partition", Name: "Partition"}
+	if err := DB.Partition("languages_partition").Create(&lang).Error; err != nil {
+		t.Fatalf("failed to create in partition, got %v", err)
+	}
+
+	lang2 := Language{Code: "partition2", Name: "Partition2"}
+	if err := DB.Partition("languages_partition").Create(&lang2).Error; err != nil {
+		t.Fatalf("failed to create in partition again, got %v", err)
+	}
+
+	var count int64
+	DB.Table("languages_partition").Where("code IN ?", []string{lang.Code, lang2.Code}).Count(&count)
+	if count != 2 {
+		t.Errorf("should create in the partition table, got %v", count)
+	}
+
+	DB.Model(&Language{}).Where("code = ?", lang.Code).Count(&count)
+	if count != 0 {
+		t.Errorf("should not create in the parent table, got %v", count)
+	}
+
+	if err := DB.Partition("languages_partition_missing").Create(&Language{Code: "partition_missing"}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for missing partition, got %v", err)
+	}
+
+	// the partition dropped after creating in it isn't valid any more
+	DB.Migrator().DropTable("languages_partition")
+	if err := DB.Partition("languages_partition").Create(&Language{Code: "partition_dropped"}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for dropped partition, got %v", err)
+	}
+}
@@ -1,7 +1,9 @@
 package tests_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"gorm.io/gorm"
@@ -109,3 +111,84 @@ func TestSupportedDialectorWithErrForeignKeyViolated(t *testing.T) {
 		t.Fatalf("expected err: %v got err: %v", gorm.ErrForeignKeyViolated, err)
 	}
 }
+
+type codeError struct {
+	code string
+}
+
+func (e codeError) Error() string {
+	return "error with code " + e.code
+}
+
+func (e codeError) Code() string {
+	return e.code
+}
+
+func TestErrorCodeMap(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	translatedErr := errors.New("translated error")
+	db, _ := gorm.Open(tests.DummyDialector{TranslatedErr: translatedErr}, &gorm.Config{
+		TranslateError: true,
+		ErrorCodeMap:   map[string]error{"P0001": errQuota, "23505": gorm.ErrDuplicatedKey},
+	})
+
+	if err := db.Session(&gorm.Session{}).AddError(codeError{code: "P0001"}); !errors.Is(err, errQuota) {
+		t.Errorf("expected err: %v got err: %v", errQuota, err)
+	}
+
+	if err := db.Session(&gorm.Session{}).AddError(fmt.Errorf("wrapped: %w", codeError{code: "23505"})); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("expected err: %v got err: %v", gorm.ErrDuplicatedKey, err)
+	}
+
+	// fallback to the dialector translator if the code isn't mapped
+	if err := db.Session(&gorm.Session{}).AddError(codeError{code: "42000"}); !errors.Is(err, translatedErr) {
+		t.Errorf("expected err: %v got err: %v", translatedErr, err)
+	}
+
+	if err := db.Session(&gorm.Session{}).AddError(errors.New("no code")); !errors.Is(err, translatedErr) {
+		t.Errorf("expected err: %v got err: %v", translatedErr, err)
+	}
+}
+
+func TestAddErrorSkipsDuplicates(t *testing.T) {
+	errFirst, errSecond := errors.New("first error"), errors.New("second error")
+	db, _ := gorm.Open(tests.DummyDialector{})
+
+	tx := db.Session(&gorm.Session{})
+	tx.AddError(context.Canceled)
+	tx.AddError(context.Canceled)
+	tx.AddError(context.Canceled)
+	if tx.Error != context.Canceled {
+		t.Errorf("should keep only the first occurrence, got %v", tx.Error)
+	}
+
+	tx = db.Session(&gorm.Session{})
+	tx.AddError(fmt.Errorf("query: %w", context.Canceled))
+	tx.AddError(context.Canceled)
+	if tx.Error.Error() != "query: context canceled" || !errors.Is(tx.Error, context.Canceled) {
+		t.Errorf("should skip errors wrapped by the existing one, got %v", tx.Error)
+	}
+
+	tx = db.Session(&gorm.Session{})
+	tx.AddError(errFirst)
+	tx.AddError(errSecond)
+	tx.AddError(errSecond)
+	tx.AddError(context.Canceled)
+	tx.AddError(context.Canceled)
+	if tx.Error.Error() != "first error; second error; context canceled" {
+		t.Errorf("should append distinct errors once, got %v", tx.Error)
+	}
+	if !errors.Is(tx.Error, context.Canceled) {
+		t.Errorf("should keep the last distinct error in the chain, got %v", tx.Error)
+	}
+
+	// the translated error stays the head
+	translatedErr := errors.New("translated error")
+	db, _ = gorm.Open(tests.DummyDialector{TranslatedErr: translatedErr}, &gorm.Config{TranslateError: true})
+	tx = db.Session(&gorm.Session{})
+	tx.AddError(errFirst)
+	tx.AddError(errFirst)
+	if tx.Error != translatedErr {
+		t.Errorf("should keep the translated error as the only error, got %v", tx.Error)
+	}
+}
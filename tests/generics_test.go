@@ -850,3 +850,113 @@ func TestGenericsToSQL(t *testing.T) {
 		t.Errorf("ToSQL: got wrong sql with Generics API %v", sql)
 	}
 }
+
+func TestGenericsAssociationFind(t *testing.T) {
+	user := *GetUser("generics_association_find", Config{Pets: 3, Languages: 2})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+	defer DB.Model(&user).Association("Languages").Clear()
+
+	pets, err := gorm.AssociationFind[Pet](DB.Order("name desc").Limit(2), &user, "Pets")
+	if err != nil {
+		t.Fatalf("failed to find association, got error %v", err)
+	}
+
+	if len(pets) != 2 || pets[0].Name != user.Pets[2].Name || pets[1].Name != user.Pets[1].Name {
+		t.Errorf("should find ordered and limited pets, got %+v", pets)
+	}
+
+	pets, err = gorm.AssociationFind[Pet](DB, &user, "Pets", "name = ?", user.Pets[0].Name)
+	if err != nil || len(pets) != 1 || pets[0].ID != user.Pets[0].ID {
+		t.Errorf("should find pets with conditions, got %+v, error %v", pets, err)
+	}
+
+	languages, err := gorm.AssociationFind[Language](DB, &user, "Languages")
+	if err != nil || len(languages) != 2 {
+		t.Errorf("should find many2many association, got %+v, error %v", languages, err)
+	}
+
+	owner := User{Model: gorm.Model{ID: user.ID}}
+	if _, err := gorm.AssociationFind[Pet](DB, &owner, "Pets"); err != nil || len(owner.Pets) != 0 {
+		t.Errorf("should not modify the owner, got %+v, error %v", owner.Pets, err)
+	}
+
+	if _, err := gorm.AssociationFind[Pet](DB, &user, "NotExisting"); !errors.Is(err, gorm.ErrUnsupportedRelation) {
+		t.Errorf("should return ErrUnsupportedRelation, got %v", err)
+	}
+}
+
+func TestGenericsFirstOrInit(t *testing.T) {
+	user := User{Name: "TestGenericsFirstOrInit", Age: 18}
+	DB.Create(&user)
+
+	result, found, err := gorm.FirstOrInit[User](DB, User{Name: user.Name})
+	if err != nil || !found {
+		t.Fatalf("should find existing record, found: %v, error: %v", found, err)
+	}
+	CheckUser(t, result, user)
+
+	result, found, err = gorm.FirstOrInit[User](DB.Attrs(User{Age: 20}), map[string]interface{}{"name": "TestGenericsFirstOrInitNotFound"})
+	if err != nil || found {
+		t.Fatalf("should not find record, found: %v, error: %v", found, err)
+	}
+
+	if result.ID != 0 || result.Name != "TestGenericsFirstOrInitNotFound" || result.Age != 20 {
+		t.Errorf("should initialize with conds and attrs, got %+v", result)
+	}
+
+	if _, _, err := gorm.FirstOrInit[User](DB.Where("invalid_column = ?", 1)); err == nil {
+		t.Errorf("should return error for invalid query")
+	}
+}
+
+func TestGenericsScalarValue(t *testing.T) {
+	users := []User{{Name: "TestGenericsScalarValue", Age: 10}, {Name: "TestGenericsScalarValue", Age: 20}}
+	DB.Create(&users)
+
+	total, err := gorm.ScalarValue[int64](DB.Model(&User{}).Where("name = ?", "TestGenericsScalarValue"), "SUM(age)")
+	if err != nil || total != 30 {
+		t.Errorf("should get sum of ages, got %v, error %v", total, err)
+	}
+
+	maxAge, err := gorm.ScalarValue[float64](DB.Model(&User{}).Where("name = ?", "TestGenericsScalarValue"), "MAX(age)")
+	if err != nil || maxAge != 20 {
+		t.Errorf("should get max age, got %v, error %v", maxAge, err)
+	}
+
+	total, err = gorm.ScalarValue[int64](DB.Model(&User{}).Where("name = ?", "TestGenericsScalarValueNotFound"), "SUM(age)")
+	if err != nil || total != 0 {
+		t.Errorf("should get zero value for NULL, got %v, error %v", total, err)
+	}
+
+	name, err := gorm.ScalarValue[string](DB.Model(&User{}).Where("name = ?", "TestGenericsScalarValueNotFound"), "name")
+	if !errors.Is(err, gorm.ErrRecordNotFound) || name != "" {
+		t.Errorf("should get ErrRecordNotFound, got %v, error %v", name, err)
+	}
+}
+
+func TestGenericsSetGetValue(t *testing.T) {
+	tx := gorm.SetValue(DB, "generics_set_value", 10)
+
+	if v, ok := gorm.GetValue[int](tx, "generics_set_value"); !ok || v != 10 {
+		t.Errorf("should get typed value, got %v, %v", v, ok)
+	}
+
+	if v, ok := tx.Get("generics_set_value"); !ok || v != 10 {
+		t.Errorf("should get value set with SetValue by Get, got %v, %v", v, ok)
+	}
+
+	if v, ok := gorm.GetValue[string](tx, "generics_set_value"); ok || v != "" {
+		t.Errorf("should not get value with mismatched type, got %v, %v", v, ok)
+	}
+
+	tx = tx.Set("generics_set", "value")
+	if v, ok := gorm.GetValue[string](tx, "generics_set"); !ok || v != "value" {
+		t.Errorf("should get value set with Set by GetValue, got %v, %v", v, ok)
+	}
+
+	if _, ok := gorm.GetValue[int](DB, "generics_set_value"); ok {
+		t.Errorf("should not get value from original db")
+	}
+}
@@ -1,11 +1,18 @@
 package tests_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 
 	"gorm.io/driver/mysql"
 
 	"gorm.io/gorm"
+	. "gorm.io/gorm/utils/tests"
 )
 
 func TestOpen(t *testing.T) {
@@ -101,3 +108,142 @@ func TestReturningWithNullToZeroValues(t *testing.T) {
 
 	}
 }
+
+type forkCtxKey struct{}
+
+func TestFork(t *testing.T) {
+	users := []User{*GetUser("fork-1", Config{}), *GetUser("fork-2", Config{}), *GetUser("fork-3", Config{})}
+	DB.Create(&users)
+
+	ctx := context.WithValue(context.Background(), forkCtxKey{}, "fork")
+	tx := DB.WithContext(ctx).Where("name = ?", "not-exists").Limit(1)
+	fork := tx.Fork()
+
+	if fork.Statement == tx.Statement || fork.Config == tx.Config {
+		t.Fatalf("forked db should not share statement or config")
+	}
+
+	if fork.Statement.Context.Value(forkCtxKey{}) != "fork" {
+		t.Errorf("forked db should keep the context")
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(users))
+	for idx, user := range users {
+		wg.Add(1)
+		go func(idx int, tx *gorm.DB, name string) {
+			defer wg.Done()
+			var result User
+			errs[idx] = tx.Where("name = ?", name).First(&result).Error
+		}(idx, tx.Fork(), user.Name)
+	}
+	wg.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			t.Errorf("forked db %v should not keep the conditions, got error %v", idx, err)
+		}
+	}
+
+	var count int64
+	fork.Model(&User{}).Where("name = ?", users[0].Name).Count(&count)
+	fork.Model(&User{}).Where("name = ?", users[1].Name).Count(&count)
+	if count != 1 {
+		t.Errorf("conditions should not be accumulated on the forked db, got count %v", count)
+	}
+
+	if len(fork.Statement.Clauses) != 0 || len(tx.Statement.Clauses) != 2 {
+		t.Errorf("queries on forked db should not change any statement, got %v, %v", fork.Statement.Clauses, tx.Statement.Clauses)
+	}
+}
+
+func TestClone(t *testing.T) {
+	ctx := context.WithValue(context.Background(), forkCtxKey{}, "clone")
+	tx := DB.WithContext(ctx).Model(&User{}).Where("age > ?", 18)
+	clone := tx.Clone()
+
+	if clone.Statement == tx.Statement || clone.Config == tx.Config {
+		t.Fatalf("cloned db should not share statement or config")
+	}
+
+	if clone.Statement.Context.Value(forkCtxKey{}) != "clone" {
+		t.Errorf("cloned db should keep the context")
+	}
+
+	var wg sync.WaitGroup
+	sqls := make([]string, 50)
+	for idx := range sqls {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			sqls[idx] = clone.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Where("name = ?", fmt.Sprintf("clone-%d", idx)).Find(&[]User{})
+			})
+		}(idx)
+	}
+	wg.Wait()
+
+	for idx, sql := range sqls {
+		if !regexp.MustCompile(fmt.Sprintf(`WHERE age > 18 AND name = "clone-%d" AND .users.\..deleted_at. IS NULL$`, idx)).MatchString(sql) {
+			t.Errorf("cloned db should keep the conditions without sharing them, got %v", sql)
+		}
+	}
+
+	if len(clone.Statement.Clauses) != 1 || len(tx.Statement.Clauses) != 1 {
+		t.Errorf("queries on cloned db should not change any statement, got %v, %v", clone.Statement.Clauses, tx.Statement.Clauses)
+	}
+}
+
+type requirerPlugin struct {
+	name     string
+	requires []string
+	inits    *[]string
+}
+
+func (p requirerPlugin) Name() string { return p.name }
+
+func (p requirerPlugin) Requires() []string { return p.requires }
+
+func (p requirerPlugin) Initialize(*gorm.DB) error {
+	*p.inits = append(*p.inits, p.name)
+	return nil
+}
+
+func TestPluginRequires(t *testing.T) {
+	var inits []string
+	db, _ := gorm.Open(DummyDialector{}, &gorm.Config{})
+
+	if err := db.Use(requirerPlugin{name: "audit", requires: []string{"tenant"}, inits: &inits}); !errors.Is(err, gorm.ErrPluginNotRegistered) {
+		t.Fatalf("should return ErrPluginNotRegistered, got %v", err)
+	}
+
+	err := db.UseAll(
+		requirerPlugin{name: "audit", requires: []string{"tenant", "metrics"}, inits: &inits},
+		requirerPlugin{name: "metrics", inits: &inits},
+		requirerPlugin{name: "tenant", requires: []string{"metrics"}, inits: &inits},
+	)
+	if err != nil {
+		t.Fatalf("failed to use plugins, got %v", err)
+	}
+	AssertEqual(t, inits, []string{"metrics", "tenant", "audit"})
+
+	if err := db.Use(requirerPlugin{name: "cache", requires: []string{"audit"}, inits: &inits}); err != nil {
+		t.Fatalf("failed to use plugin requires registered plugin, got %v", err)
+	}
+
+	err = db.UseAll(
+		requirerPlugin{name: "a", requires: []string{"b"}, inits: &inits},
+		requirerPlugin{name: "b", requires: []string{"c"}, inits: &inits},
+		requirerPlugin{name: "c", requires: []string{"a"}, inits: &inits},
+	)
+	if !errors.Is(err, gorm.ErrPluginDependencyCycle) || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Fatalf("should return ErrPluginDependencyCycle naming the plugins, got %v", err)
+	}
+	if _, ok := db.Plugins["a"]; ok {
+		t.Fatalf("plugins in a cycle should not be registered")
+	}
+
+	if err := db.UseAll(requirerPlugin{name: "d", requires: []string{"e"}, inits: &inits}); !errors.Is(err, gorm.ErrPluginNotRegistered) {
+		t.Fatalf("should return ErrPluginNotRegistered, got %v", err)
+	}
+}
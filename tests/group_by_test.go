@@ -1,8 +1,11 @@
 package tests_test
 
 import (
+	"regexp"
+	"strings"
 	"testing"
 
+	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -107,3 +110,80 @@ func TestGroupBy(t *testing.T) {
 		}
 	}
 }
+
+func TestGroupByScanIntoMap(t *testing.T) {
+	users := []User{
+		{Name: "groupby_map", Age: 10},
+		{Name: "groupby_map", Age: 20},
+		{Name: "groupby_map1", Age: 30},
+	}
+
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	var totals map[string]int
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Where("name LIKE ?", "groupby_map%").Group("name").ScanIntoMap("name", "total", &totals); err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+
+	if len(totals) != 2 || totals["groupby_map"] != 30 || totals["groupby_map1"] != 30 {
+		t.Errorf("scanned map is not correct, got %v", totals)
+	}
+
+	if err := DB.Model(&User{}).Select("name").Group("name").ScanIntoMap("name", "total", &totals); err == nil {
+		t.Errorf("should returns error when value column is not selected")
+	}
+
+	if err := DB.Model(&User{}).Select("name, sum(age) as total").Group("name").ScanIntoMap("name", "total", totals); err == nil {
+		t.Errorf("should returns error when dest is not a pointer to map")
+	}
+}
+
+func TestAutoGroupBy(t *testing.T) {
+	users := []User{
+		{Name: "auto_groupby", Age: 10, Active: true},
+		{Name: "auto_groupby", Age: 20, Active: true},
+		{Name: "auto_groupby", Age: 30, Active: false},
+	}
+
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	tx := DB.Session(&gorm.Session{AutoGroupBy: true})
+
+	var results []struct {
+		Name   string
+		Active bool
+		Total  int
+	}
+	if err := tx.Model(&User{}).Select("name, users.active, sum(age) as total").Where("name = ?", "auto_groupby").Group("name").Order("active").Find(&results).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+
+	if len(results) != 2 || results[0].Total != 30 || results[1].Total != 30 {
+		t.Errorf("should group by the selected active column, got %+v", results)
+	}
+
+	dryRun := tx.Session(&gorm.Session{DryRun: true})
+	stmt := dryRun.Model(&User{}).Select("name, COUNT(DISTINCT age) AS ages, 1, lower(name) AS lower_name").Group("name").Find(&results).Statement
+	if !regexp.MustCompile(`GROUP BY .name.\s*$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should not group by aggregates, literals or expressions, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryRun.Model(&User{}).Select("Name", "Age").Group("users.name").Find(&results).Statement
+	if !regexp.MustCompile(`GROUP BY .users.\..name.,.age.\s*$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should append the missing plain column, got %v", stmt.SQL.String())
+	}
+
+	stmt = dryRun.Model(&User{}).Select("name, age").Find(&results).Statement
+	if strings.Contains(stmt.SQL.String(), "GROUP BY") {
+		t.Errorf("should not add GROUP BY when it isn't specified, got %v", stmt.SQL.String())
+	}
+
+	stmt = DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Select("name, age").Group("name").Find(&results).Statement
+	if !regexp.MustCompile(`GROUP BY .name.\s*$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should not append columns without AutoGroupBy, got %v", stmt.SQL.String())
+	}
+}
@@ -1,10 +1,14 @@
 package tests_test
 
 import (
+	"context"
+	"database/sql/driver"
+	"io"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -292,3 +296,95 @@ func db(unscoped bool) *gorm.DB {
 		return DB
 	}
 }
+
+// fakeConnector opens connections of a fake driver for tests that need to control the database's behavior,
+// connecting blocks until the context is done if Hang or fails with ConnectErr,
+// statements are handled by Exec and Query with the id of the connection running them
+type fakeConnector struct {
+	Hang       bool
+	ConnectErr error
+	Exec       func(ctx context.Context, conn int, query string) (driver.Result, error)
+	Query      func(ctx context.Context, conn int, query string) (driver.Rows, error)
+
+	mu    sync.Mutex
+	conns int
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.Hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	} else if c.ConnectErr != nil {
+		return nil, c.ConnectErr
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conns++
+	return &fakeConn{connector: c, id: c.conns}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver { return nil }
+
+type fakeConn struct {
+	connector *fakeConnector
+	id        int
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), nil)
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), nil)
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, _ []driver.NamedValue) (driver.Result, error) {
+	if s.conn.connector.Exec == nil {
+		return driver.RowsAffected(0), nil
+	}
+	return s.conn.connector.Exec(ctx, s.conn.id, s.query)
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, _ []driver.NamedValue) (driver.Rows, error) {
+	if s.conn.connector.Query == nil {
+		return &fakeRows{}, nil
+	}
+	return s.conn.connector.Query(ctx, s.conn.id, s.query)
+}
+
+// fakeRows returns the values row by row, and fails with CloseErr when closed
+type fakeRows struct {
+	columns  []string
+	values   [][]driver.Value
+	closeErr error
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return r.closeErr }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -1,6 +1,7 @@
 package tests_test
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -609,3 +611,186 @@ func TestPropagateUnscoped(t *testing.T) {
 		t.Fatalf("unscoped did not propagate")
 	}
 }
+
+func TestCallbackAbort(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	cache := map[uint]User{}
+	var executed int
+	db.Callback().Query().Before("gorm:query").Register("test:cache", func(db *gorm.DB) {
+		if conds, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok && len(conds.Exprs) == 1 {
+			if eq, ok := conds.Exprs[0].(clause.Eq); ok {
+				if id, ok := eq.Value.(uint); ok {
+					if user, ok := cache[id]; ok {
+						*db.Statement.Dest.(*User) = user
+						db.RowsAffected = 1
+						db.Abort()
+					}
+				}
+			}
+		}
+	})
+	db.Callback().Query().After("gorm:query").Register("test:count", func(db *gorm.DB) {
+		executed++
+	})
+	db.Callback().Create().Before("gorm:create").Register("test:abort_create", func(db *gorm.DB) {
+		if user, ok := db.Statement.Dest.(*User); ok && user.Name == "abort_create" {
+			db.Abort()
+		}
+	})
+
+	user := *GetUser("abort_query", Config{})
+	db.Create(&user)
+	cache[user.ID] = User{Model: gorm.Model{ID: user.ID}, Name: "cached"}
+
+	var result User
+	if err := db.Where(clause.Eq{Column: clause.PrimaryColumn, Value: user.ID}).Find(&result).Error; err != nil {
+		t.Fatalf("no error should happen on abort, got %v", err)
+	}
+	if result.Name != "cached" || executed != 0 {
+		t.Errorf("should return the cached user without executing, got %v, executed %v", result.Name, executed)
+	}
+
+	var fresh User
+	if err := db.First(&fresh, "name = ?", user.Name).Error; err != nil || fresh.Name != user.Name || executed != 1 {
+		t.Errorf("should execute the query without cache hit, got %v, %v, executed %v", fresh.Name, err, executed)
+	}
+
+	aborted := User{Name: "abort_create"}
+	if err := db.Create(&aborted).Error; err != nil || aborted.ID != 0 {
+		t.Errorf("should skip creating on abort, got %v, %v", aborted.ID, err)
+	}
+
+	var count int64
+	if db.Model(&User{}).Where("name = ?", "abort_create").Count(&count); count != 0 {
+		t.Errorf("aborted create should not insert, got %v", count)
+	}
+
+	created := *GetUser("abort_create_after", Config{})
+	if err := db.Create(&created).Error; err != nil || created.ID == 0 {
+		t.Errorf("default transaction should be finalized after abort, got %v", err)
+	}
+}
+
+func TestQueryCacheRefreshAndNoCache(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database, got error %v", err)
+	}
+
+	// a read-through cache plugin keyed by the primary key, honoring RefreshCache and NoCache
+	cache := map[uint]User{}
+	cachedID := func(db *gorm.DB) (uint, bool) {
+		// the soft delete condition is added by gorm:query
+		if conds, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok && len(conds.Exprs) > 0 {
+			if eq, ok := conds.Exprs[0].(clause.Eq); ok {
+				id, ok := eq.Value.(uint)
+				return id, ok
+			}
+		}
+		return 0, false
+	}
+	db.Callback().Query().Before("gorm:query").Register("test:cache_read", func(db *gorm.DB) {
+		if read, _ := gorm.QueryCacheFrom(db.Statement.Context); read {
+			if id, ok := cachedID(db); ok {
+				if user, ok := cache[id]; ok {
+					*db.Statement.Dest.(*User) = user
+					db.RowsAffected = 1
+					db.Abort()
+				}
+			}
+		}
+	})
+	db.Callback().Query().After("gorm:query").Register("test:cache_write", func(db *gorm.DB) {
+		if _, write := gorm.QueryCacheFrom(db.Statement.Context); write && db.Error == nil {
+			if id, ok := cachedID(db); ok {
+				cache[id] = *db.Statement.Dest.(*User)
+			}
+		}
+	})
+
+	user := *GetUser("query_cache_refresh", Config{})
+	db.Create(&user)
+
+	find := func(tx *gorm.DB) (result User) {
+		if err := tx.Where(clause.Eq{Column: clause.PrimaryColumn, Value: user.ID}).Find(&result).Error; err != nil {
+			t.Fatalf("failed to find, got error %v", err)
+		}
+		return
+	}
+
+	find(db)
+	db.Model(&User{}).Where("id = ?", user.ID).Update("name", "query_cache_refreshed")
+
+	if result := find(db); result.Name != "query_cache_refresh" {
+		t.Errorf("should read the cached user, got %v", result.Name)
+	}
+
+	if result := find(db.NoCache()); result.Name != "query_cache_refreshed" {
+		t.Errorf("should skip reading the cache with NoCache, got %v", result.Name)
+	}
+	if cache[user.ID].Name != "query_cache_refresh" {
+		t.Errorf("should not write the cache with NoCache, got %v", cache[user.ID].Name)
+	}
+
+	if result := find(db.RefreshCache()); result.Name != "query_cache_refreshed" {
+		t.Errorf("should skip reading the cache with RefreshCache, got %v", result.Name)
+	}
+	if result := find(db); result.Name != "query_cache_refreshed" {
+		t.Errorf("should write the fresh result back with RefreshCache, got %v", result.Name)
+	}
+
+	if read, write := gorm.QueryCacheFrom(context.Background()); !read || !write {
+		t.Errorf("should read and write the cache by default, got %v, %v", read, write)
+	}
+}
+
+type writeEvent struct {
+	op           string
+	table        string
+	rowsAffected int64
+	keys         []interface{}
+}
+
+func TestOnWrite(t *testing.T) {
+	var events []writeEvent
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.OnWrite = func(ctx context.Context, op string, table string, rowsAffected int64, keys []interface{}) {
+		events = append(events, writeEvent{op: op, table: table, rowsAffected: rowsAffected, keys: keys})
+	}
+
+	users := []User{*GetUser("on_write_1", Config{}), *GetUser("on_write_2", Config{})}
+	if err := tx.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got %v", err)
+	}
+	AssertEqual(t, events, []writeEvent{{op: "create", table: "users", rowsAffected: 2, keys: []interface{}{users[0].ID, users[1].ID}}})
+
+	events = nil
+	if err := tx.Model(&users[0]).Update("age", 30).Error; err != nil {
+		t.Fatalf("failed to update user, got %v", err)
+	}
+	AssertEqual(t, events, []writeEvent{{op: "update", table: "users", rowsAffected: 1, keys: []interface{}{users[0].ID}}})
+
+	events = nil
+	if err := tx.Model(&User{}).Where("name IN ?", []string{"on_write_1", "on_write_2"}).Update("age", 40).Error; err != nil {
+		t.Fatalf("failed to update users, got %v", err)
+	}
+	AssertEqual(t, events, []writeEvent{{op: "update", table: "users", rowsAffected: 2, keys: []interface{}{}}})
+
+	events = nil
+	if err := tx.Delete(&users[1]).Error; err != nil {
+		t.Fatalf("failed to delete user, got %v", err)
+	}
+	AssertEqual(t, events, []writeEvent{{op: "delete", table: "users", rowsAffected: 1, keys: []interface{}{users[1].ID}}})
+
+	events = nil
+	tx.Find(&users)
+	tx.Session(&gorm.Session{DryRun: true}).Delete(&users[0])
+	if err := tx.Delete(&User{}).Error; err == nil {
+		t.Errorf("should return error for deleting without conditions")
+	}
+	AssertEqual(t, len(events), 0)
+}
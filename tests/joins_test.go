@@ -1,13 +1,21 @@
 package tests_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -476,3 +484,141 @@ func TestJoinsPreload_Issue7013_NoEntries(t *testing.T) {
 
 	AssertEqual(t, len(entries), 0)
 }
+
+func TestWarnOnImplicitCrossJoin(t *testing.T) {
+	var buf bytes.Buffer
+	tx := DB.Session(&gorm.Session{DryRun: true, Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn})})
+	tx.Config.WarnOnImplicitCrossJoin = true
+
+	tx.Joins("JOIN pets").Find(&[]User{})
+	if !strings.Contains(buf.String(), "cartesian product: JOIN pets") {
+		t.Errorf("should warn on join without condition, got %q", buf.String())
+	}
+
+	buf.Reset()
+	tx.Joins("JOIN pets ON pets.user_id = users.id").Joins("CROSS JOIN languages").Joins("Company").Find(&[]User{})
+	tx.Joins("JOIN accounts USING (user_id)").Find(&[]User{})
+	if buf.Len() != 0 {
+		t.Errorf("should not warn on joins with conditions or explicit cross joins, got %q", buf.String())
+	}
+
+	buf.Reset()
+	DB.Session(&gorm.Session{DryRun: true, Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn})}).Joins("JOIN pets").Find(&[]User{})
+	if buf.Len() != 0 {
+		t.Errorf("should not warn when WarnOnImplicitCrossJoin is disabled, got %q", buf.String())
+	}
+}
+
+func TestJoinsWithConditionsInOnClause(t *testing.T) {
+	users := []User{
+		*GetUser("joins_on_conditions_1", Config{Company: true}),
+		*GetUser("joins_on_conditions_2", Config{Company: true}),
+	}
+	DB.Create(&users)
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Joins("Company", DB.Where("Company.name = ?", users[0].Company.Name)).Find(&[]User{}).Statement
+	if !regexp.MustCompile(`LEFT JOIN .companies. .Company. ON .users.\..company_id. = .Company.\..id. AND Company.name = `).MatchString(stmt.SQL.String()) ||
+		strings.Contains(stmt.SQL.String(), "WHERE Company.name") {
+		t.Errorf("should add the conditions to ON clause, got %v", stmt.SQL.String())
+	}
+
+	var results []User
+	if err := DB.Joins("Company", DB.Where("Company.name = ?", users[0].Company.Name)).
+		Where("users.name IN ?", []string{users[0].Name, users[1].Name}).Order("users.id").Find(&results).Error; err != nil {
+		t.Fatalf("failed to join with conditions, got error %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("left join with conditions in ON should keep all users, got %v", len(results))
+	}
+
+	if results[0].Company.Name != users[0].Company.Name || results[1].Company.ID != 0 {
+		t.Errorf("should only join the matched company, got %+v, %+v", results[0].Company, results[1].Company)
+	}
+}
+
+func TestJoinsWithTableFunction(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	start, end := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	stmt := postgresDB.Table("orders").Select("bucket, count(orders.id)").Clauses(clause.From{Joins: []clause.Join{{
+		Type: clause.CrossJoin,
+		Expression: clause.TableFunction{
+			Name: "generate_series", Args: []interface{}{start, end, clause.Expr{SQL: "interval '1 day'"}}, Alias: "bucket", Lateral: true,
+		},
+	}}}).Group("bucket").Find(&[]map[string]interface{}{}).Statement
+
+	if sql := stmt.SQL.String(); sql != `SELECT bucket, count(orders.id) FROM "orders" CROSS JOIN LATERAL generate_series($1,$2,interval '1 day') AS "bucket" GROUP BY "bucket"` {
+		t.Errorf("should join the table function, got %v", sql)
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{start, end})
+
+	if err := postgresDB.Table("orders").Clauses(clause.From{Joins: []clause.Join{{
+		Type: clause.CrossJoin, Expression: clause.TableFunction{Name: "generate_series(1); DROP TABLE orders; --"},
+	}}}).Find(&[]map[string]interface{}{}).Error; err == nil {
+		t.Errorf("should return error for invalid table function name")
+	}
+}
+
+func TestJoinsWithAmbiguousColumns(t *testing.T) {
+	user := *GetUser("ambiguous_column", Config{Pets: 1})
+	DB.Create(&user)
+
+	type result struct {
+		Name string
+	}
+
+	query := func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("users").Select("users.name, pets.name").Joins("JOIN pets ON pets.user_id = users.id").Where("users.id = ?", user.ID)
+	}
+
+	var lastWins result
+	if err := query(DB).Scan(&lastWins).Error; err != nil || lastWins.Name != user.Pets[0].Name {
+		t.Errorf("the last duplicate column should win, got %v, %v", lastWins.Name, err)
+	}
+
+	for _, strategy := range []gorm.AmbiguousColumnStrategy{gorm.AmbiguousColumnError, gorm.AmbiguousColumnPrefixWithTable} {
+		tx := DB.Session(&gorm.Session{})
+		tx.Config.OnAmbiguousColumn = strategy
+
+		var errResult result
+		if err := query(tx).Scan(&errResult).Error; !errors.Is(err, gorm.ErrAmbiguousColumn) {
+			t.Errorf("should return ErrAmbiguousColumn with strategy %v, got %v", strategy, err)
+		}
+	}
+
+	var users []User
+	if err := DB.Model(&User{}).SelectQualified().Joins("JOIN pets ON pets.user_id = users.id").Where("users.id = ?", user.ID).Find(&users).Error; err != nil {
+		t.Fatalf("failed to find users with qualified select, got %v", err)
+	}
+	if len(users) != 1 || users[0].Name != user.Name || users[0].ID != user.ID {
+		t.Errorf("should scan the qualified columns into the user, got %+v", users)
+	}
+
+	// only the aliases generated for the qualified select are mapped back, selected aliases are kept
+	var selected struct {
+		Name      string
+		UsersName string
+	}
+	if err := DB.Table("users").SelectQualified().Select("name, name AS users_name").Where("id = ?", user.ID).Scan(&selected).Error; err != nil {
+		t.Fatalf("failed to scan selected columns with qualified select, got %v", err)
+	}
+	if selected.Name != user.Name || selected.UsersName != user.Name {
+		t.Errorf("should scan the selected alias into its own field, got %+v", selected)
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.OnAmbiguousColumn = gorm.AmbiguousColumnPrefixWithTable
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Model(&User{}).Joins("JOIN pets ON pets.user_id = users.id").Find(&[]User{}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "`users`.`name` AS `users_name`") {
+		t.Errorf("should alias the columns with the table name, got %v", sql)
+	}
+
+	var prefixed []User
+	if err := tx.Model(&User{}).Joins("JOIN pets ON pets.user_id = users.id").Where("users.id = ?", user.ID).Find(&prefixed).Error; err != nil || len(prefixed) != 1 || prefixed[0].Name != user.Name {
+		t.Errorf("should scan the prefixed columns into the user, got %+v, %v", prefixed, err)
+	}
+}
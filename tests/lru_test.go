@@ -53,6 +53,31 @@ func TestLRU_Add_UnlimitedSize_NoEviction(t *testing.T) {
 	}
 }
 
+func TestLRU_Add_ExceedsMaxCost_RemovesOldest(t *testing.T) {
+	lru := lru.NewLRU[string, string](10, nil, time.Hour)
+	lru.SetMaxCost(10, func(key string, value string) int64 { return int64(len(value)) })
+	lru.Add("key1", "1234")
+	lru.Add("key2", "1234")
+	lru.Add("key3", "1234")
+
+	if _, ok := lru.Get("key1"); ok {
+		t.Errorf("Expected key1 to be removed, but it still exists")
+	}
+	if cost := lru.Cost(); cost != 8 {
+		t.Errorf("Expected cost to be 8, got %v", cost)
+	}
+
+	lru.Add("key4", "123456789012")
+	if keys := lru.Keys(); len(keys) != 1 || keys[0] != "key4" || lru.Cost() != 12 {
+		t.Errorf("Expected only the newest entry to be kept, got %v, cost %v", keys, lru.Cost())
+	}
+
+	lru.Remove("key4")
+	if cost := lru.Cost(); cost != 0 {
+		t.Errorf("Expected cost to be 0 after removing, got %v", cost)
+	}
+}
+
 func TestLRU_Add_Eviction(t *testing.T) {
 	lru := lru.NewLRU[string, int](0, nil, time.Second*2)
 	lru.Add("key1", 1)
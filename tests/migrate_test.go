@@ -3,12 +3,15 @@ package tests_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -367,6 +370,79 @@ func TestMigrateTable(t *testing.T) {
 	}
 }
 
+func TestMigrateRenameTableIfExists(t *testing.T) {
+	type RenameIfExistsTable struct {
+		gorm.Model
+		Name string
+	}
+
+	DB.Migrator().DropTable(&RenameIfExistsTable{}, "new_rename_if_exists_tables")
+	DB.AutoMigrate(&RenameIfExistsTable{})
+
+	for i := 0; i < 2; i++ {
+		if err := DB.Migrator().RenameTableIfExists(&RenameIfExistsTable{}, "new_rename_if_exists_tables"); err != nil {
+			t.Fatalf("Failed to rename table #%v, got error %v", i, err)
+		}
+	}
+
+	if DB.Migrator().HasTable(&RenameIfExistsTable{}) || !DB.Migrator().HasTable("new_rename_if_exists_tables") {
+		t.Fatal("should found renamed table only")
+	}
+
+	// both exist, should keep them as they are
+	DB.AutoMigrate(&RenameIfExistsTable{})
+	if err := DB.Migrator().RenameTableIfExists(&RenameIfExistsTable{}, "new_rename_if_exists_tables"); err != nil {
+		t.Fatalf("Failed to rename table, got error %v", err)
+	}
+
+	if !DB.Migrator().HasTable(&RenameIfExistsTable{}) || !DB.Migrator().HasTable("new_rename_if_exists_tables") {
+		t.Fatal("should keep both tables")
+	}
+
+	DB.Migrator().DropTable(&RenameIfExistsTable{}, "new_rename_if_exists_tables")
+}
+
+func TestMigrateRenameColumnIfExists(t *testing.T) {
+	type RenameIfExistsColumn struct {
+		gorm.Model
+		Name string
+	}
+
+	type RenameIfExistsColumn2 struct {
+		gorm.Model
+		FullName string
+	}
+
+	DB.Migrator().DropTable(&RenameIfExistsColumn{})
+	DB.AutoMigrate(&RenameIfExistsColumn{})
+	DB.Create(&RenameIfExistsColumn{Name: "rename_if_exists"})
+
+	for i := 0; i < 2; i++ {
+		if err := DB.Table("rename_if_exists_columns").Migrator().RenameColumnIfExists(&RenameIfExistsColumn2{}, "name", "full_name"); err != nil {
+			t.Fatalf("Failed to rename column #%v, got error %v", i, err)
+		}
+	}
+
+	if DB.Migrator().HasColumn(&RenameIfExistsColumn{}, "name") || !DB.Migrator().HasColumn(&RenameIfExistsColumn{}, "full_name") {
+		t.Fatal("should found renamed column only")
+	}
+
+	var result RenameIfExistsColumn2
+	if err := DB.Table("rename_if_exists_columns").First(&result).Error; err != nil || result.FullName != "rename_if_exists" {
+		t.Fatalf("should preserve data of renamed column, got %+v, error %v", result, err)
+	}
+
+	if err := DB.Migrator().RenameColumnIfExists(&RenameIfExistsColumn{}, "not_exists", "name"); err != nil {
+		t.Fatalf("should skip renaming a not existing column, got error %v", err)
+	}
+
+	if DB.Migrator().HasColumn(&RenameIfExistsColumn{}, "name") {
+		t.Fatal("should not create column when source doesn't exist")
+	}
+
+	DB.Migrator().DropTable(&RenameIfExistsColumn{})
+}
+
 func TestMigrateWithQuotedIndex(t *testing.T) {
 	if DB.Dialector.Name() != "mysql" {
 		t.Skip()
@@ -782,6 +858,58 @@ func TestMigrateIndexesWithDynamicTableName(t *testing.T) {
 
 // check column order after migration, flaky test
 // https://github.com/go-gorm/gorm/issues/4351
+func TestMigrateColumnAfter(t *testing.T) {
+	type UserMigrateAfter struct {
+		ID   uint
+		Name string
+		Age  int
+	}
+	DB.Migrator().DropTable(&UserMigrateAfter{})
+	if err := DB.AutoMigrate(&UserMigrateAfter{}); err != nil {
+		t.Fatalf("failed to auto migrate, got error: %v", err)
+	}
+
+	type UserMigrateAfter2 struct {
+		ID       uint
+		Name     string
+		Age      int
+		Nickname string `gorm:"migrateAfter:Name"`
+	}
+	if err := DB.Table("user_migrate_afters").AutoMigrate(&UserMigrateAfter2{}); err != nil {
+		t.Fatalf("failed to auto migrate, got error: %v", err)
+	}
+
+	columnTypes, err := DB.Table("user_migrate_afters").Migrator().ColumnTypes(&UserMigrateAfter2{})
+	if err != nil {
+		t.Fatalf("failed to get column types, got error: %v", err)
+	}
+
+	var columns []string
+	for _, columnType := range columnTypes {
+		columns = append(columns, columnType.Name())
+	}
+
+	// the position is only supported by mysql
+	if DB.Dialector.Name() == "mysql" {
+		AssertEqual(t, columns, []string{"id", "name", "nickname", "age"})
+	} else {
+		AssertEqual(t, columns, []string{"id", "name", "age", "nickname"})
+	}
+
+	type UserMigrateAfter3 struct {
+		ID    uint
+		Name  string
+		Email string `gorm:"migrateAfter:missing"`
+	}
+	if err := DB.Table("user_migrate_afters").AutoMigrate(&UserMigrateAfter3{}); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("should return error for not existing column, got %v", err)
+	}
+
+	if DB.Table("user_migrate_afters").Migrator().HasColumn(&UserMigrateAfter3{}, "email") {
+		t.Errorf("should not add column after not existing column")
+	}
+}
+
 func TestMigrateColumnOrder(t *testing.T) {
 	type UserMigrateColumn struct {
 		ID uint
@@ -1987,3 +2115,243 @@ func TestMigrateWithUniqueIndexAndUnique(t *testing.T) {
 		}
 	}
 }
+
+func TestAutoMigrateWithMigrationLock(t *testing.T) {
+	type MigrationLockStruct struct {
+		gorm.Model
+		Name string
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.MigrationLock = true
+
+	DB.Migrator().DropTable(&MigrationLockStruct{})
+	defer DB.Migrator().DropTable(&MigrationLockStruct{})
+
+	if err := tx.AutoMigrate(&MigrationLockStruct{}); err != nil {
+		t.Fatalf("failed to auto migrate with migration lock, got error %v", err)
+	}
+
+	if !DB.Migrator().HasTable(&MigrationLockStruct{}) {
+		t.Fatalf("should create table with migration lock")
+	}
+
+	if DB.Dialector.Name() != "sqlite" {
+		return
+	}
+
+	// hold the lock, AutoMigrate should wait until it's released
+	if err := DB.Exec("INSERT INTO gorm_migration_locks (name, locked_at) VALUES (?, ?)", "gorm:migration_lock", time.Now()).Error; err != nil {
+		t.Fatalf("failed to hold migration lock, got error %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tx.AutoMigrate(&MigrationLockStruct{})
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("should wait for the migration lock, but finished with error %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	DB.Exec("DELETE FROM gorm_migration_locks WHERE name = ?", "gorm:migration_lock")
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to auto migrate after the lock released, got error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("should auto migrate after the lock released")
+	}
+
+	var count int64
+	if DB.Table("gorm_migration_locks").Count(&count); count != 0 {
+		t.Errorf("should release migration lock after migrated, got %v locks", count)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	DB.Exec("INSERT INTO gorm_migration_locks (name, locked_at) VALUES (?, ?)", "gorm:migration_lock", time.Now())
+	defer DB.Exec("DELETE FROM gorm_migration_locks WHERE name = ?", "gorm:migration_lock")
+	if err := tx.WithContext(ctx).AutoMigrate(&MigrationLockStruct{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("should stop waiting for the migration lock when context is done, got %v", err)
+	}
+
+	// the lock held longer than MigrationLockTimeout is stale, e.g: the holder crashed
+	DB.Exec("UPDATE gorm_migration_locks SET locked_at = ? WHERE name = ?", time.Now().Add(-time.Hour), "gorm:migration_lock")
+	tx.Config.MigrationLockTimeout = time.Minute
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tx.WithContext(ctx).AutoMigrate(&MigrationLockStruct{}); err != nil {
+		t.Errorf("should take over the stale migration lock, got %v", err)
+	}
+
+	if DB.Table("gorm_migration_locks").Count(&count); count != 0 {
+		t.Errorf("should release migration lock after taking over the stale lock, got %v locks", count)
+	}
+}
+
+type advisoryLockDialector struct{ DummyDialector }
+
+func (advisoryLockDialector) Name() string { return "postgres" }
+
+func (advisoryLockDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return advisoryLockMigrator{db: db}
+}
+
+type advisoryLockMigrator struct {
+	gorm.Migrator
+	db *gorm.DB
+}
+
+// AutoMigrate runs a statement on the pool while the lock is held
+func (m advisoryLockMigrator) AutoMigrate(...interface{}) error {
+	return m.db.Exec("MIGRATE").Error
+}
+
+func TestMigrationLockSession(t *testing.T) {
+	// records the connection executing each statement
+	var (
+		mu       sync.Mutex
+		sessions = map[string]int{}
+	)
+	sqlDB := sql.OpenDB(&fakeConnector{
+		Exec: func(_ context.Context, conn int, query string) (driver.Result, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			sessions[query] = conn
+			return driver.RowsAffected(0), nil
+		},
+	})
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(advisoryLockDialector{}, &gorm.Config{ConnPool: sqlDB, DisableAutomaticPing: true, MigrationLock: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	if err := db.AutoMigrate(&User{}); err != nil {
+		t.Fatalf("failed to auto migrate, got error %v", err)
+	}
+
+	lock, unlock := sessions["SELECT pg_advisory_lock(hashtext(?))"], sessions["SELECT pg_advisory_unlock(hashtext(?))"]
+	if lock == 0 || lock != unlock {
+		t.Errorf("should lock and unlock on the same session, got %v", sessions)
+	}
+
+	if migrate := sessions["MIGRATE"]; migrate == 0 || migrate == lock {
+		t.Errorf("should migrate on the pool while holding the lock, got %v", sessions)
+	}
+}
+
+func TestMaterializedView(t *testing.T) {
+	type UserSummary struct {
+		_     struct{} `gorm:"materializedView"`
+		Name  string
+		Total int
+	}
+
+	DB.Exec("DROP MATERIALIZED VIEW IF EXISTS user_summaries")
+	DB.Migrator().DropTable(&UserSummary{})
+
+	if DB.Dialector.Name() == "postgres" {
+		if err := DB.Exec("CREATE MATERIALIZED VIEW user_summaries AS SELECT name, count(*) AS total FROM users GROUP BY name").Error; err != nil {
+			t.Fatalf("failed to create materialized view, got error: %v", err)
+		}
+		defer DB.Exec("DROP MATERIALIZED VIEW IF EXISTS user_summaries")
+	}
+
+	if err := DB.AutoMigrate(&UserSummary{}, &User{}); err != nil {
+		t.Fatalf("failed to auto migrate, got error: %v", err)
+	}
+
+	if DB.Dialector.Name() == "postgres" {
+		if err := DB.Migrator().RefreshMaterializedView("user_summaries", false); err != nil {
+			t.Errorf("failed to refresh materialized view, got error: %v", err)
+		}
+
+		var summaries []UserSummary
+		if err := DB.Find(&summaries).Error; err != nil {
+			t.Errorf("failed to query materialized view, got error: %v", err)
+		}
+	} else if DB.Migrator().HasTable(&UserSummary{}) {
+		t.Errorf("should not create table for materialized view")
+	}
+
+	var sqls []string
+	pgDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: Tracer{
+		Logger: logger.Discard,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	pgDB.Migrator().RefreshMaterializedView("user_summaries", false)
+	pgDB.Migrator().RefreshMaterializedView("user_summaries", true)
+	AssertEqual(t, sqls, []string{`REFRESH MATERIALIZED VIEW "user_summaries"`, `REFRESH MATERIALIZED VIEW CONCURRENTLY "user_summaries"`})
+}
+
+func TestTruncateTables(t *testing.T) {
+	type TruncateParent struct {
+		ID   uint
+		Name string
+	}
+	type TruncateChild struct {
+		ID               uint
+		Name             string
+		TruncateParentID uint
+		TruncateParent   TruncateParent
+	}
+
+	DB.Migrator().DropTable(&TruncateChild{}, &TruncateParent{})
+	if err := DB.AutoMigrate(&TruncateParent{}, &TruncateChild{}); err != nil {
+		t.Fatalf("failed to auto migrate, got error: %v", err)
+	}
+
+	child := TruncateChild{Name: "child", TruncateParent: TruncateParent{Name: "parent"}}
+	if err := DB.Create(&child).Error; err != nil {
+		t.Fatalf("failed to create child, got error: %v", err)
+	}
+
+	if err := DB.Migrator().TruncateTables(gorm.TruncateOptions{RestartIdentity: true}, &TruncateParent{}, &TruncateChild{}); err != nil {
+		t.Fatalf("failed to truncate tables, got error: %v", err)
+	}
+
+	var parents, children int64
+	DB.Model(&TruncateParent{}).Count(&parents)
+	DB.Model(&TruncateChild{}).Count(&children)
+	if parents != 0 || children != 0 {
+		t.Errorf("tables should be empty after truncating, got %v parents, %v children", parents, children)
+	}
+
+	parent := TruncateParent{Name: "parent"}
+	if err := DB.Create(&parent).Error; err != nil || parent.ID != 1 {
+		t.Errorf("identity should be restarted, got %v, %v", parent.ID, err)
+	}
+
+	var sqls []string
+	pgDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: Tracer{
+		Logger: logger.Discard,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			sqls = append(sqls, sql)
+		},
+	}})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	pgDB.Migrator().TruncateTables(gorm.TruncateOptions{Cascade: true, RestartIdentity: true}, &TruncateParent{}, &TruncateChild{})
+	pgDB.Migrator().TruncateTables(gorm.TruncateOptions{}, &TruncateChild{}, &TruncateParent{})
+	AssertEqual(t, sqls, []string{
+		`TRUNCATE TABLE "truncate_parents","truncate_children" RESTART IDENTITY CASCADE`,
+		`TRUNCATE TABLE "truncate_children","truncate_parents"`,
+	})
+}
@@ -106,6 +106,49 @@ func TestNestedPreloadForSlice(t *testing.T) {
 	}
 }
 
+func TestPreloadWithBatchSize(t *testing.T) {
+	users := []User{
+		*GetUser("preload_batch_size_1", Config{Pets: 2, Languages: 1}),
+		*GetUser("preload_batch_size_2", Config{Pets: 1, Languages: 2}),
+		*GetUser("preload_batch_size_3", Config{Pets: 3, Languages: 1}),
+	}
+
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	var userIDs []uint
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	var petsQueries, languagesQueries int
+	sess := DB.Session(&gorm.Session{PreloadBatchSize: 2, Logger: Tracer{
+		Logger: DB.Config.Logger,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			sql, _ := fc()
+			if regexp.MustCompile(`FROM .pets. WHERE`).MatchString(sql) {
+				petsQueries++
+			} else if regexp.MustCompile(`FROM .user_speaks. WHERE`).MatchString(sql) {
+				languagesQueries++
+			}
+		},
+	}})
+
+	var users2 []User
+	if err := sess.Preload("Pets").Preload("Languages").Order("id").Find(&users2, "id IN ?", userIDs).Error; err != nil {
+		t.Fatalf("failed to preload in batches, got error %v", err)
+	}
+
+	for idx, user := range users2 {
+		CheckUser(t, user, users[idx])
+	}
+
+	if petsQueries != 2 || languagesQueries != 2 {
+		t.Errorf("should preload in batches of 2, got %v pets queries and %v user_speaks queries", petsQueries, languagesQueries)
+	}
+}
+
 func TestPreloadWithConds(t *testing.T) {
 	users := []User{
 		*GetUser("slice_nested_preload_1", Config{Account: true}),
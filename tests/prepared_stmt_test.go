@@ -2,12 +2,17 @@ package tests_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/internal/stmt_store"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -227,6 +232,84 @@ func TestPreparedStmtClose(t *testing.T) {
 	}
 }
 
+func TestPreparedStmtWithPreparedKey(t *testing.T) {
+	tx := DB.Session(&gorm.Session{PrepareStmt: true})
+
+	user := *GetUser("prepared_stmt_with_key", Config{})
+	tx.Create(&user)
+
+	pdb, ok := tx.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+
+	queries := []string{
+		"/* page 1 */ SELECT name FROM users WHERE name = ?",
+		"/* page 2 */ SELECT name\n  FROM users -- comment\n WHERE name = ?",
+	}
+
+	countKeys := func(prefix string) (count int) {
+		pdb.Mux.Lock()
+		defer pdb.Mux.Unlock()
+		for _, key := range pdb.Stmts.Keys() {
+			if strings.HasPrefix(key, prefix) {
+				count++
+			}
+		}
+		return
+	}
+
+	for _, key := range []string{"prepared_key_1", "prepared_key_2"} {
+		for _, query := range queries {
+			var names []string
+			if err := tx.PreparedKey(key).Raw(query, user.Name).Scan(&names).Error; err != nil || len(names) != 1 {
+				t.Fatalf("failed to query with prepared key, got %v, error %v", names, err)
+			}
+		}
+
+		if count := countKeys(key + "\x00"); count != 1 {
+			t.Errorf("statements only differ in comments should share the key %v, got %v", key, count)
+		}
+	}
+
+	for _, query := range []string{
+		"SELECT /*+ NO_INDEX(users) */ name FROM users WHERE name = ?",
+		"SELECT /*! STRAIGHT_JOIN */ name FROM users WHERE name = ?",
+	} {
+		if err := tx.PreparedKey("prepared_key_hint").Raw(query, user.Name).Scan(&[]string{}).Error; err != nil {
+			t.Fatalf("failed to query with prepared key, got error %v", err)
+		}
+	}
+
+	if count := countKeys("prepared_key_hint\x00"); count != 2 {
+		t.Errorf("statements differ in optimizer hints should not share the key, got %v", count)
+	}
+
+	if err := tx.Raw(queries[0], user.Name).Scan(&[]string{}).Error; err != nil {
+		t.Fatalf("failed to query, got error %v", err)
+	}
+
+	if count := countKeys(queries[0]); count != 1 {
+		t.Errorf("statement without prepared key should be cached by SQL, got %v", count)
+	}
+
+	// the key is stored in the settings, and kept by the statements of the session
+	keyTx := tx.Session(&gorm.Session{}).PreparedKey("prepared_key_session").Session(&gorm.Session{})
+	if key, ok := keyTx.Get("gorm:prepared_key"); !ok || key != "prepared_key_session" {
+		t.Errorf("should store the prepared key in the settings, got %v", key)
+	}
+
+	for _, query := range queries {
+		if err := keyTx.Raw(query, user.Name).Scan(&[]string{}).Error; err != nil {
+			t.Fatalf("failed to query with prepared key, got error %v", err)
+		}
+	}
+
+	if count := countKeys("prepared_key_session\x00"); count != 1 {
+		t.Errorf("statements of the session should share the prepared key, got %v", count)
+	}
+}
+
 func isUsingClosedConnError(err error) bool {
 	// https://github.com/golang/go/blob/e705a2d16e4ece77e08e80c168382cdb02890f5b/src/database/sql/sql.go#L2717
 	return err.Error() == "sql: statement is closed"
@@ -287,3 +370,356 @@ func TestPreparedStmtConcurrentClose(t *testing.T) {
 		t.Fatalf("should is a unexpected error")
 	}
 }
+
+func TestPreparedStmtCloseContext(t *testing.T) {
+	users := []User{*GetUser("prepared_stmt_close_context_1", Config{}), *GetUser("prepared_stmt_close_context_2", Config{})}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("failed to create users, got %v", err)
+	}
+
+	// create a new connection to keep away from other tests
+	tx, err := OpenTestConnection(&gorm.Config{PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to open test connection due to %s", err)
+	}
+	pdb, ok := tx.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+
+	rows, err := tx.Model(&User{}).Where("name LIKE ?", "prepared_stmt_close_context_%").Order("id").Rows()
+	if err != nil {
+		t.Fatalf("failed to query users, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pdb.CloseContext(ctx); err != nil {
+		t.Fatalf("failed to close prepared statements, got %v", err)
+	}
+
+	var names []string
+	for rows.Next() {
+		var user User
+		if err := tx.ScanRows(rows, &user); err != nil {
+			t.Fatalf("failed to scan rows after closing prepared statements, got %v", err)
+		}
+		names = append(names, user.Name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows should be readable after closing prepared statements, got %v", err)
+	}
+	rows.Close()
+
+	if len(names) != 2 {
+		t.Fatalf("should read 2 users after closing prepared statements, got %v", names)
+	}
+
+	pdb.Mux.Lock()
+	defer pdb.Mux.Unlock()
+	if len(pdb.Stmts.Keys()) != 0 {
+		t.Fatalf("prepared stmt should be empty")
+	}
+}
+
+func TestPreparedStmtStrictTxStmtReuse(t *testing.T) {
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.StrictTxStmtReuse = true
+	db := tx.Session(&gorm.Session{PrepareStmt: true})
+
+	conn, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok || !conn.StrictTxStmtReuse {
+		t.Fatalf("should use strict prepared statement manager, got %#v", db.ConnPool)
+	}
+
+	hasStmt := func() bool {
+		for _, key := range conn.Stmts.Keys() {
+			if strings.Contains(key, "strict_tx_stmt") {
+				return true
+			}
+		}
+		return false
+	}
+
+	query := func(tx *gorm.DB) error {
+		var value int
+		if err := tx.Raw("SELECT ? AS strict_tx_stmt", 1).Scan(&value).Error; err != nil {
+			return err
+		}
+		if value != 1 {
+			return errors.New("unexpected value")
+		}
+		return nil
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if _, ok := tx.Statement.ConnPool.(*gorm.PreparedStmtTX); !ok {
+			t.Errorf("should use prepared statement transaction, got %#v", tx.Statement.ConnPool)
+		}
+		if err := query(tx); err != nil {
+			return err
+		}
+		return query(tx)
+	}); err != nil {
+		t.Fatalf("failed to query in transaction, got %v", err)
+	}
+
+	if hasStmt() {
+		t.Errorf("statements prepared in transaction should not be cached")
+	}
+
+	if err := query(db); err != nil {
+		t.Fatalf("failed to query, got %v", err)
+	}
+
+	if !hasStmt() {
+		t.Errorf("statements prepared outside of transaction should be cached")
+	}
+
+	if err := db.Transaction(query); err != nil {
+		t.Errorf("failed to query in transaction after caching, got %v", err)
+	}
+}
+
+func TestPreparedStmtMaxBytes(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{PrepareStmt: true, PrepareStmtMaxBytes: 100})
+	if err != nil {
+		t.Fatalf("failed to connect database, got %v", err)
+	}
+
+	conn, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+
+	conn.Close()
+	longQuery := "SELECT " + strings.Repeat("1 + ", 10) + "1 AS max_bytes_long"
+	if err := db.Exec(longQuery).Error; err != nil {
+		t.Fatalf("failed to exec, got %v", err)
+	}
+	if bytes := conn.StmtsBytes(); bytes != int64(len(longQuery)) {
+		t.Errorf("bytes should be the length of the query, got %v", bytes)
+	}
+
+	shortQuery := "SELECT 1 AS max_bytes_short"
+	for i := 0; i < 3; i++ {
+		if err := db.Exec(fmt.Sprintf("%v_%d", shortQuery, i)).Error; err != nil {
+			t.Fatalf("failed to exec, got %v", err)
+		}
+	}
+
+	if bytes := conn.StmtsBytes(); bytes > 100 {
+		t.Errorf("bytes should be capped by max bytes, got %v", bytes)
+	}
+
+	for _, key := range conn.Stmts.Keys() {
+		if key == longQuery {
+			t.Errorf("the least recently used statement should be evicted")
+		}
+	}
+
+	conn.Close()
+	if bytes := conn.StmtsBytes(); bytes != 0 {
+		t.Errorf("bytes should be released after closing, got %v", bytes)
+	}
+}
+
+func TestPreparedStmtMetrics(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{PrepareStmt: true, PrepareStmtMaxSize: 2})
+	if err != nil {
+		t.Fatalf("failed to connect database, got %v", err)
+	}
+
+	conn, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+	conn.Close()
+	conn.ResetMetrics()
+
+	var evicted []string
+	conn.Stmts.OnEvict(func(query string) {
+		evicted = append(evicted, query)
+	})
+
+	queries := []string{"SELECT 1 AS metrics_a", "SELECT 1 AS metrics_a", "SELECT 1 AS metrics_b", "SELECT 1 AS metrics_c"}
+	for _, query := range queries {
+		if err := db.Exec(query).Error; err != nil {
+			t.Fatalf("failed to exec, got %v", err)
+		}
+	}
+
+	AssertEqual(t, conn.Metrics(), gorm.PrepareStmtMetrics{Hits: 1, Misses: 3, Evictions: 1, Size: 2})
+	AssertEqual(t, evicted, []string{"SELECT 1 AS metrics_a"})
+
+	if err := db.Session(&gorm.Session{}).Exec("SELECT 1 AS metrics_c").Error; err != nil {
+		t.Fatalf("failed to exec, got %v", err)
+	}
+	if hits := conn.Metrics().Hits; hits != 2 {
+		t.Errorf("sessions should share the metrics, got %v hits", hits)
+	}
+
+	conn.ResetMetrics()
+	AssertEqual(t, conn.Metrics(), gorm.PrepareStmtMetrics{Size: 2})
+}
+
+func TestPreparedStmtSkipPrepare(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got %v", err)
+	}
+
+	conn, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+	conn.Close()
+
+	skipped := db.SkipPrepare()
+	if _, ok := skipped.Statement.ConnPool.(*gorm.PreparedStmtDB); ok {
+		t.Fatalf("should use the underlying connection when skipping prepare")
+	}
+
+	if err := skipped.Exec("SELECT 1 AS skip_prepare_db").Error; err != nil {
+		t.Fatalf("failed to exec, got %v", err)
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		skippedTx := tx.SkipPrepare()
+		if _, ok := skippedTx.Statement.ConnPool.(*gorm.PreparedStmtTX); ok {
+			t.Errorf("should use the underlying transaction when skipping prepare")
+		}
+		return skippedTx.Exec("SELECT 1 AS skip_prepare_tx").Error
+	}); err != nil {
+		t.Fatalf("failed to exec in transaction, got %v", err)
+	}
+
+	if keys := conn.Stmts.Keys(); len(keys) != 0 {
+		t.Errorf("skipped statements should not be cached, got %v", keys)
+	}
+
+	if err := db.Exec("SELECT 1 AS skip_prepare_db").Error; err != nil {
+		t.Fatalf("failed to exec, got %v", err)
+	}
+	if keys := conn.Stmts.Keys(); len(keys) != 1 {
+		t.Errorf("statements should be cached without skipping prepare, got %v", keys)
+	}
+}
+
+func TestPreparedStmtUseWriter(t *testing.T) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, got error %v", err)
+	}
+
+	var used []string
+	db, err := gorm.Open(sqlite.New(sqlite.Config{Conn: &writerConnPool{DB: sqlDB, name: "reader", used: &used}}), &gorm.Config{PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to connect database, got %v", err)
+	}
+
+	conn, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		t.Fatalf("should assign PreparedStatement Manager back to database when using PrepareStmt mode")
+	}
+
+	for _, tx := range []*gorm.DB{db, db.WriteDB(), db.Session(&gorm.Session{UseWriter: true}), db} {
+		if err := tx.Exec("SELECT 1 AS use_writer").Error; err != nil {
+			t.Fatalf("failed to exec, got %v", err)
+		}
+	}
+
+	// statements prepared on the writer are cached separately
+	if keys := conn.Stmts.Keys(); len(keys) != 2 {
+		t.Errorf("should prepare the statement on the writer separately, got %v", keys)
+	}
+
+	// the hint is ignored in transactions, the statement is prepared with the transaction
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.WriteDB().Exec("SELECT 1 AS use_writer").Error
+	}); err != nil {
+		t.Fatalf("failed to exec in transaction, got %v", err)
+	}
+
+	if keys := conn.Stmts.Keys(); len(keys) != 2 {
+		t.Errorf("should not prepare the statement for the writer in transactions, got %v", keys)
+	}
+}
+
+func TestPreparedStmtCloseAcquired(t *testing.T) {
+	sqlDB := sql.OpenDB(&fakeConnector{})
+	defer sqlDB.Close()
+
+	var mu sync.Mutex
+	mu.Lock()
+	store := stmt_store.New(10, time.Hour, 0)
+	// the statement returned by the store is acquired until released after executing
+	stmt, err := store.New(context.Background(), "EXEC", "EXEC", false, sqlDB, &mu)
+	if err != nil {
+		t.Fatalf("failed to prepare statement, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := stmt.CloseContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("should wait for the acquired statement until the context is done, got %v", err)
+	}
+	if stmt.Acquire() {
+		t.Errorf("should not acquire the statement being closed")
+	}
+
+	if _, err := stmt.ExecContext(context.Background()); err != nil {
+		t.Errorf("the acquired statement should be executable while closing, got %v", err)
+	}
+
+	stmt.Release()
+	if _, err := stmt.ExecContext(context.Background()); err == nil || !strings.Contains(err.Error(), "statement is closed") {
+		t.Errorf("should close the statement after released, got %v", err)
+	}
+}
+
+func TestPreparedStmtCloseConcurrently(t *testing.T) {
+	sqlDB := sql.OpenDB(&fakeConnector{})
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(DummyDialector{}, &gorm.Config{ConnPool: sqlDB, DisableAutomaticPing: true, SkipDefaultTransaction: true, PrepareStmt: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+	pdb := db.ConnPool.(*gorm.PreparedStmtDB)
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+		errs = make(chan error, 8)
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if err := db.Exec("EXEC").Error; err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if err := pdb.CloseContext(context.Background()); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-done
+	close(errs)
+	for err := range errs {
+		t.Errorf("statements should not be closed while executing, got %v", err)
+	}
+}
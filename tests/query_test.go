@@ -1,9 +1,13 @@
 package tests_test
 
 import (
+	"bytes"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
 	"regexp"
 	"sort"
@@ -14,6 +18,7 @@ import (
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -265,6 +270,53 @@ func TestQueryWithAssociation(t *testing.T) {
 	}
 }
 
+func TestFindIntoPointerSlice(t *testing.T) {
+	users := []*User{
+		GetUser("find_into_pointer_slice_1", Config{Account: true, Pets: 2, Toys: 1, Company: true, Manager: true}),
+		GetUser("find_into_pointer_slice_2", Config{Account: true, Pets: 1, Company: true}),
+	}
+
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create users: %v", err)
+	}
+
+	var results []*User
+	if err := DB.Joins("Account").Joins("Company").Joins("Manager").Preload("Pets.Toy").Preload("Toys").
+		Where("users.name IN ?", []string{users[0].Name, users[1].Name}).Order("users.id").Find(&results).Error; err != nil {
+		t.Fatalf("errors happened when find into pointer slice: %v", err)
+	}
+
+	if len(results) != len(users) {
+		t.Fatalf("should find %v users, but got %v", len(users), len(results))
+	}
+
+	for idx, result := range results {
+		if result == nil {
+			t.Fatalf("pointer element %v should be initialized", idx)
+		}
+		CheckUser(t, *result, *users[idx])
+	}
+
+	if results[0] == results[1] || results[0].Manager == results[1].Manager {
+		t.Errorf("each pointer element should be allocated separately")
+	}
+
+	var ptrResults []**User
+	if err := DB.Model(&User{}).Where("name IN ?", []string{users[0].Name, users[1].Name}).Order("id").Find(&ptrResults).Error; err != nil {
+		t.Fatalf("errors happened when find into slice of multi-level pointers: %v", err)
+	}
+
+	if len(ptrResults) != len(users) {
+		t.Fatalf("should find %v users, but got %v", len(users), len(ptrResults))
+	}
+
+	for idx, result := range ptrResults {
+		if result == nil || *result == nil || (*result).Name != users[idx].Name {
+			t.Errorf("multi-level pointer element %v should be initialized, got %v", idx, result)
+		}
+	}
+}
+
 func TestFindInBatches(t *testing.T) {
 	users := []User{
 		*GetUser("find_in_batches", Config{}),
@@ -418,6 +470,37 @@ func TestFindInBatchesWithError(t *testing.T) {
 	}
 }
 
+func TestFindInBatchesWithBatchTransaction(t *testing.T) {
+	users := []User{
+		*GetUser("find_in_batches_with_batch_transaction", Config{}),
+		*GetUser("find_in_batches_with_batch_transaction", Config{}),
+		*GetUser("find_in_batches_with_batch_transaction", Config{}),
+		*GetUser("find_in_batches_with_batch_transaction", Config{}),
+		*GetUser("find_in_batches_with_batch_transaction", Config{}),
+	}
+	DB.Create(&users)
+
+	var results []User
+	result := DB.Session(&gorm.Session{BatchTransaction: true}).Where("name = ?", users[0].Name).FindInBatches(&results, 2, func(tx *gorm.DB, batch int) error {
+		if err := tx.Model(&results).Update("age", 100+batch).Error; err != nil {
+			return err
+		}
+
+		if batch == 2 {
+			return errors.New("batch error")
+		}
+		return nil
+	})
+
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "batch 2 failed, 1 batches committed") {
+		t.Fatalf("should report the committed batches, got %v", result.Error)
+	}
+
+	var ages []int
+	DB.Model(&User{}).Where("name = ?", users[0].Name).Order("id").Pluck("age", &ages)
+	AssertEqual(t, ages, []int{101, 101, 18, 18, 18})
+}
+
 func TestFillSmallerStruct(t *testing.T) {
 	user := User{Name: "SmallerUser", Age: 100}
 	DB.Save(&user)
@@ -882,6 +965,37 @@ func TestMapColumns(t *testing.T) {
 	}
 }
 
+func TestScanWithMapping(t *testing.T) {
+	users := []User{{Name: "ScanWithMappingUser1", Age: 21}, {Name: "ScanWithMappingUser2", Age: 22}}
+	DB.Save(&users)
+
+	type result struct {
+		Title string
+		Years uint
+		Name  string
+	}
+
+	var results []result
+	if err := DB.Table("users").Select("name", "age").Where("name LIKE ?", "ScanWithMappingUser%").Order("id").
+		ScanWithMapping(&results, map[string]string{"name": "Title", "age": "Years"}); err != nil {
+		t.Fatalf("failed to scan with mapping, got error %v", err)
+	}
+
+	if len(results) != 2 || results[0].Title != users[0].Name || results[0].Years != users[0].Age || results[1].Title != users[1].Name {
+		t.Errorf("should scan with the mapping, got %+v", results)
+	}
+
+	var res result
+	if err := DB.Table("users").Select("name", "age").Where("name = ?", users[0].Name).
+		ScanWithMapping(&res, map[string]string{"age": "years"}); err != nil {
+		t.Fatalf("failed to scan with mapping, got error %v", err)
+	}
+
+	if res.Years != users[0].Age || res.Name != "" || res.Title != "" {
+		t.Errorf("should ignore unmapped columns, got %+v", res)
+	}
+}
+
 func TestPluckWithSelect(t *testing.T) {
 	users := []User{
 		{Name: "pluck_with_select_1", Age: 25},
@@ -901,6 +1015,131 @@ func TestPluckWithSelect(t *testing.T) {
 	AssertEqual(t, userAges, []int{26, 27})
 }
 
+func TestPluck2(t *testing.T) {
+	users := []User{
+		{Name: "pluck2_1", Age: 21},
+		{Name: "pluck2_2", Age: 22},
+		{Name: "pluck2_3", Age: 23},
+	}
+
+	DB.Create(&users)
+
+	var names []string
+	var ages []int
+	if err := DB.Model(&User{}).Where("name like ?", "pluck2_%").Order("id").Pluck2("Name", "age", &names, &ages); err != nil {
+		t.Fatalf("got error when pluck2: %v", err)
+	}
+
+	AssertEqual(t, names, []string{"pluck2_1", "pluck2_2", "pluck2_3"})
+	AssertEqual(t, ages, []int{21, 22, 23})
+
+	if err := DB.Model(&User{}).Where("name like ?", "pluck2_%").Select("name, age, id").Pluck2("name", "age", &names, &ages); !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField when column count mismatch, got %v", err)
+	}
+
+	if err := DB.Model(&User{}).Pluck2("name", "age", names, &ages); !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData when dest is not a pointer to slice, got %v", err)
+	}
+}
+
+func TestQualifyWindow(t *testing.T) {
+	users := []User{
+		{Name: "qualify_window_1", Age: 10},
+		{Name: "qualify_window_1", Age: 30},
+		{Name: "qualify_window_2", Age: 20},
+		{Name: "qualify_window_2", Age: 40},
+		{Name: "qualify_window_3", Age: 50},
+	}
+	DB.Create(&users)
+	DB.Delete(&users[4])
+
+	var results []User
+	if err := DB.Model(&User{}).Where("name like ?", "qualify_window_%").
+		Select("*, ROW_NUMBER() OVER (PARTITION BY name ORDER BY age DESC) AS rn").
+		QualifyWindow("t", clause.Eq{Column: clause.Column{Name: "rn"}, Value: 1}).Order("age").Find(&results).Error; err != nil {
+		t.Fatalf("failed to query with qualify window, got error %v", err)
+	}
+
+	if len(results) != 2 || results[0].ID != users[1].ID || results[1].ID != users[3].ID {
+		t.Errorf("should find the oldest user of each name, got %+v", results)
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Select("*, ROW_NUMBER() OVER (ORDER BY age) AS rn").
+		QualifyWindow("t", clause.Lte{Column: clause.Column{Name: "rn"}, Value: 3}).Find(&[]User{}).Statement
+	if !regexp.MustCompile("^SELECT \\* FROM \\(SELECT \\*, ROW_NUMBER\\(\\) OVER \\(ORDER BY age\\) AS rn FROM .users. WHERE .users.\\..deleted_at. IS NULL\\) AS .t. WHERE .rn. <= .+$").MatchString(stmt.SQL.String()) {
+		t.Errorf("should wrap query as derived table, got %v", stmt.SQL.String())
+	}
+}
+
+func TestSelectWithAggregateFilter(t *testing.T) {
+	users := []User{
+		{Name: "aggregate_filter", Age: 10},
+		{Name: "aggregate_filter", Age: 20},
+		{Name: "aggregate_filter", Age: 30},
+	}
+	DB.Create(&users)
+
+	adults := clause.AggregateFilter{
+		Aggregate: clause.Expr{SQL: "count(*)"},
+		Filter:    clause.Where{Exprs: []clause.Expression{clause.Gte{Column: "age", Value: 18}}},
+	}
+
+	var count int64
+	if err := DB.Model(&User{}).Where("name = ?", "aggregate_filter").Select(adults).Scan(&count).Error; err != nil || count != 2 {
+		t.Errorf("should count with aggregate filter, got %v, error %v", count, err)
+	}
+
+	var result struct {
+		Total  int64
+		Adults int64
+	}
+	if err := DB.Model(&User{}).Where("name = ?", "aggregate_filter").Select("count(*) AS total, ? AS adults", adults).Scan(&result).Error; err != nil || result.Total != 3 || result.Adults != 2 {
+		t.Errorf("should count with aggregate filter, got %+v, error %v", result, err)
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Select(adults).Find(&[]User{}).Statement
+	if clause.FilterClauseDialects[DB.Dialector.Name()] && !strings.HasPrefix(stmt.SQL.String(), "SELECT count(*) FILTER (WHERE ") {
+		t.Errorf("should use FILTER clause on %v, got %v", DB.Dialector.Name(), stmt.SQL.String())
+	}
+}
+
+func TestStreamJSON(t *testing.T) {
+	users := []User{{Name: "stream_json_1", Age: 18}, {Name: "stream_json_2", Age: 20}}
+	DB.Create(&users)
+
+	var buf bytes.Buffer
+	if err := DB.Model(&User{}).Where("name like ?", "stream_json_%").Order("id").StreamJSON(&buf); err != nil {
+		t.Fatalf("failed to stream json, got error %v", err)
+	}
+
+	var results []User
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatalf("failed to decode streamed json %v, got error %v", buf.String(), err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("should stream 2 users, got %v", buf.String())
+	}
+	for idx, result := range results {
+		AssertObjEqual(t, result, users[idx], "ID", "Name", "Age")
+	}
+
+	buf.Reset()
+	if err := DB.Table("users").Select("name, age").Where("name = ?", "stream_json_2").StreamJSON(&buf); err != nil {
+		t.Fatalf("failed to stream json, got error %v", err)
+	}
+
+	var maps []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &maps); err != nil || len(maps) != 1 || maps[0]["name"] != "stream_json_2" || maps[0]["age"] != float64(20) {
+		t.Errorf("should stream maps without model, got %v, error %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	if err := DB.Model(&User{}).Where("name = ?", "stream_json_not_found").StreamJSON(&buf); err != nil || buf.String() != "[]" {
+		t.Errorf("should stream empty array when no rows, got %v, error %v", buf.String(), err)
+	}
+}
+
 func TestSelectWithVariables(t *testing.T) {
 	DB.Save(&User{Name: "select_with_variables"})
 
@@ -1034,6 +1273,54 @@ func TestOrder(t *testing.T) {
 	}
 }
 
+func TestOrderWithDefaultNullsOrder(t *testing.T) {
+	birthday := time.Now().Round(time.Second)
+	users := []User{
+		{Name: "default_nulls_order_1", Birthday: &birthday},
+		{Name: "default_nulls_order_2"},
+		{Name: "default_nulls_order_3", Birthday: func() *time.Time { t := birthday.Add(time.Hour); return &t }()},
+	}
+	DB.Create(&users)
+
+	tx := DB.Session(&gorm.Session{})
+	find := func(nulls clause.NullsOrder, order interface{}) (names []string) {
+		tx.Config.DefaultNullsOrder = nulls
+		if err := tx.Model(&User{}).Where("name LIKE ?", "default_nulls_order_%").Order(order).Pluck("name", &names).Error; err != nil {
+			t.Fatalf("failed to query with nulls order, got error %v", err)
+		}
+		return
+	}
+
+	AssertEqual(t, find(clause.NullsLast, "birthday"), []string{"default_nulls_order_1", "default_nulls_order_3", "default_nulls_order_2"})
+	AssertEqual(t, find(clause.NullsLast, "birthday desc"), []string{"default_nulls_order_3", "default_nulls_order_1", "default_nulls_order_2"})
+	AssertEqual(t, find(clause.NullsFirst, "birthday"), []string{"default_nulls_order_2", "default_nulls_order_1", "default_nulls_order_3"})
+
+	// the NULLs order of the column takes precedence
+	AssertEqual(t, find(clause.NullsFirst, clause.OrderByColumn{Column: clause.Column{Name: "birthday"}, Desc: true, Nulls: clause.NullsLast}),
+		[]string{"default_nulls_order_3", "default_nulls_order_1", "default_nulls_order_2"})
+}
+
+func TestOrderBySafe(t *testing.T) {
+	dryDB := DB.Session(&gorm.Session{DryRun: true})
+	allowed := map[string]string{"name": "name", "created": "users.created_at"}
+
+	result := dryDB.OrderBySafe("name asc, created DESC", allowed).Find(&User{})
+	if !regexp.MustCompile("SELECT \\* FROM .*users.* ORDER BY .name.,.users.\\..created_at. DESC").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build Order condition, but got %v", result.Statement.SQL.String())
+	}
+
+	result = dryDB.OrderBySafe("", allowed).Find(&User{})
+	if !regexp.MustCompile("SELECT \\* FROM .*users.* IS NULL$").MatchString(result.Statement.SQL.String()) {
+		t.Fatalf("Build Order condition, but got %v", result.Statement.SQL.String())
+	}
+
+	for _, spec := range []string{"age", "name; DROP TABLE users", "name sideways", "name asc desc"} {
+		if err := dryDB.OrderBySafe(spec, allowed).Find(&User{}).Error; !errors.Is(err, gorm.ErrInvalidSort) {
+			t.Errorf("should returns ErrInvalidSort for spec %v, but got %v", spec, err)
+		}
+	}
+}
+
 func TestOrderWithAllFields(t *testing.T) {
 	dryDB := DB.Session(&gorm.Session{DryRun: true, QueryFields: true})
 	userQuery := "SELECT .*users.*id.*users.*created_at.*users.*updated_at.*users.*deleted_at.*users.*name.*users.*age" +
@@ -1098,6 +1385,90 @@ func TestOffset(t *testing.T) {
 	}
 }
 
+func TestInlineLimitOffset(t *testing.T) {
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.InlineLimitOffset = true
+
+	stmt := tx.Session(&gorm.Session{DryRun: true}).Where("name like ?", "OffsetUser%").Limit(10).Offset(5).Find(&[]User{}).Statement
+	if !regexp.MustCompile(`LIMIT 10 OFFSET 5$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should render limit and offset as literals, got %v", stmt.SQL.String())
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{"OffsetUser%"})
+
+	for i := 0; i < 5; i++ {
+		DB.Save(&User{Name: fmt.Sprintf("InlineLimitOffsetUser%v", i)})
+	}
+
+	var users []User
+	if err := tx.Where("name like ?", "InlineLimitOffsetUser%").Order("id").Limit(2).Offset(1).Find(&users).Error; err != nil {
+		t.Fatalf("failed to query with inline limit offset, got error %v", err)
+	}
+
+	if len(users) != 2 || users[0].Name != "InlineLimitOffsetUser1" {
+		t.Errorf("should query with inline limit offset, got %+v", users)
+	}
+
+	// dialect clause builders receive the statement, e.g: sqlserver asserts *gorm.Statement to build OFFSET FETCH
+	tx.Config.ClauseBuilders = map[string]clause.ClauseBuilder{"LIMIT": func(c clause.Clause, builder clause.Builder) {
+		if _, ok := builder.(*gorm.Statement); !ok {
+			t.Errorf("should pass *gorm.Statement to the LIMIT builder, got %T", builder)
+		}
+		c.Build(builder)
+	}}
+	stmt = tx.Session(&gorm.Session{DryRun: true}).Limit(10).Offset(5).Find(&[]User{}).Statement
+	if !regexp.MustCompile(`LIMIT 10 OFFSET 5$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should render limit and offset as literals with the clause builder, got %v", stmt.SQL.String())
+	}
+}
+
+func TestMaxRows(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		DB.Save(&User{Name: fmt.Sprintf("MaxRowsUser%v", i)})
+	}
+
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.MaxRows = 3
+
+	var users []User
+	if err := tx.Where("name LIKE ?", "MaxRowsUser%").Find(&users).Error; !errors.Is(err, gorm.ErrTooManyRows) {
+		t.Errorf("should return ErrTooManyRows, got %v", err)
+	}
+
+	if err := tx.Where("name LIKE ?", "MaxRowsUser%").Limit(3).Find(&users).Error; err != nil || len(users) != 3 {
+		t.Errorf("should find rows within the limit, got %v rows, error %v", len(users), err)
+	}
+
+	var total int
+	if err := tx.Where("name LIKE ?", "MaxRowsUser%").FindInBatches(&users, 4, func(tx *gorm.DB, batch int) error {
+		total += len(users)
+		return nil
+	}).Error; err != nil || total != 5 {
+		t.Errorf("should not apply to FindInBatches, got %v rows, error %v", total, err)
+	}
+}
+
+func TestMaxVars(t *testing.T) {
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.MaxVars = 3
+
+	var users []User
+	if err := tx.Where("id IN ?", []int{1, 2, 3, 4}).Find(&users).Error; !errors.Is(err, gorm.ErrTooManyVars) {
+		t.Errorf("should return ErrTooManyVars, got %v", err)
+	}
+
+	if err := tx.Find(&users, []int{1, 2, 3, 4}).Error; !errors.Is(err, gorm.ErrTooManyVars) {
+		t.Errorf("should return ErrTooManyVars for primary keys, got %v", err)
+	}
+
+	if err := tx.Where("name = ?", "max_vars").Where("id IN ?", []int{1, 2, 3}).Find(&users).Error; !errors.Is(err, gorm.ErrTooManyVars) {
+		t.Errorf("should count vars of the whole statement, got %v", err)
+	}
+
+	if err := tx.Where("id IN ?", []int{1, 2, 3}).Find(&users).Error; err != nil {
+		t.Errorf("should not return error within the limit, got %v", err)
+	}
+}
+
 func TestSearchWithMap(t *testing.T) {
 	users := []User{
 		*GetUser("map_search_user1", Config{}),
@@ -1453,3 +1824,250 @@ func TestQueryScanToArray(t *testing.T) {
 		t.Error("users[1] should be empty")
 	}
 }
+
+func TestQueryWithTupleIN(t *testing.T) {
+	users := []User{
+		*GetUser("tuple-in-1", Config{}),
+		*GetUser("tuple-in-2", Config{}),
+		*GetUser("tuple-in-3", Config{}),
+	}
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	tupleIN := clause.TupleIN{
+		Columns: []clause.Column{{Name: "name"}, {Name: "age"}},
+		Values:  [][]interface{}{{users[0].Name, users[0].Age}, {users[2].Name, users[2].Age}, {users[1].Name, users[1].Age + 1}},
+	}
+
+	var results []User
+	if err := DB.Where(tupleIN).Order("id").Find(&results).Error; err != nil {
+		t.Fatalf("errors happened when query with tuple in: %v", err)
+	} else if len(results) != 2 || results[0].ID != users[0].ID || results[1].ID != users[2].ID {
+		t.Errorf("should find matched users, but got %+v", results)
+	}
+
+	var expanded []User
+	if err := DB.Where(tupleIN.Expand()).Order("id").Find(&expanded).Error; err != nil {
+		t.Fatalf("errors happened when query with expanded tuple in: %v", err)
+	} else if len(expanded) != 2 || expanded[0].ID != users[0].ID || expanded[1].ID != users[2].ID {
+		t.Errorf("should find matched users, but got %+v", expanded)
+	}
+}
+
+func TestExplainAnalyze(t *testing.T) {
+	user := GetUser("explain-analyze", Config{})
+	DB.Create(user)
+
+	if _, ok := gorm.ExplainAnalyzePrefixes[DB.Dialector.Name()]; !ok {
+		if _, err := DB.ExplainAnalyze(func(tx *gorm.DB) *gorm.DB {
+			return tx.Find(&[]User{})
+		}); !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("should return ErrUnsupportedDriver, got %v", err)
+		}
+
+		// explain only, make sure the statement is built and the plan is read
+		gorm.ExplainAnalyzePrefixes[DB.Dialector.Name()] = "EXPLAIN "
+		defer delete(gorm.ExplainAnalyzePrefixes, DB.Dialector.Name())
+	}
+
+	plan, err := DB.ExplainAnalyze(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("name = ?", user.Name).Find(&[]User{})
+	})
+	if err != nil || plan == "" {
+		t.Errorf("should return the plan, got %v, error %v", plan, err)
+	}
+
+	age := user.Age
+	if _, err := DB.ExplainAnalyze(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(user).Update("age", 100)
+	}); err != nil {
+		t.Errorf("failed to explain update, got error %v", err)
+	}
+
+	var result User
+	DB.First(&result, user.ID)
+	if result.Age != age {
+		t.Errorf("update should be rolled back, got age %v", result.Age)
+	}
+
+	if _, err := DB.ExplainAnalyze(func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("explain_analyze_not_exists").Find(&[]User{})
+	}); err == nil {
+		t.Errorf("should return error for invalid query")
+	}
+}
+
+func TestQueryExplainSample(t *testing.T) {
+	user := GetUser("explain-sample", Config{})
+	DB.Create(user)
+
+	type explained struct{ sql, plan string }
+	explains := make(chan explained, 1)
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.ExplainSampleRate = 1
+	tx.Config.OnExplain = func(sql string, p string) {
+		explains <- explained{sql: sql, plan: p}
+	}
+
+	var result User
+	if err := tx.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	} else if result.ID != user.ID {
+		t.Errorf("query result should not be affected, got %+v", result)
+	}
+
+	select {
+	case e := <-explains:
+		if !regexp.MustCompile("SELECT \\* FROM .users.").MatchString(e.sql) || e.plan == "" {
+			t.Errorf("should explain the sampled query, got sql %v, plan %v", e.sql, e.plan)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("should explain the sampled query in the background")
+	}
+
+	// EXPLAIN runs on the *sql.DB, it isn't prepared and cached
+	prepared := tx.Session(&gorm.Session{PrepareStmt: true})
+	if err := prepared.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	select {
+	case <-explains:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("should explain the sampled prepared query in the background")
+	}
+	if pdb, ok := prepared.ConnPool.(*gorm.PreparedStmtDB); ok {
+		for _, key := range pdb.Stmts.Keys() {
+			if strings.HasPrefix(key, "EXPLAIN") {
+				t.Errorf("should not prepare the EXPLAIN statement, got %v", key)
+			}
+		}
+	}
+
+	if err := tx.Transaction(func(tx *gorm.DB) error {
+		return tx.First(&result, user.ID).Error
+	}); err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+
+	tx.Config.ExplainSampleRate = 0
+	tx.First(&result, user.ID)
+
+	select {
+	case e := <-explains:
+		t.Errorf("should not explain in transactions or when sample rate is zero, got sql %v", e.sql)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSelectWithTotal(t *testing.T) {
+	users := []User{
+		*GetUser("select_with_total", Config{}),
+		*GetUser("select_with_total", Config{}),
+		*GetUser("select_with_total", Config{}),
+	}
+	DB.Create(&users)
+
+	type UserWithTotal struct {
+		User
+		Total int64 `gorm:"->;-:migration"`
+	}
+
+	var results []UserWithTotal
+	if err := DB.Model(&User{}).Where("name = ?", "select_with_total").Order("id").Limit(2).Offset(1).SelectWithTotal(&results, "Total").Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+
+	if len(results) != 2 || results[0].ID != users[1].ID || results[1].ID != users[2].ID {
+		t.Fatalf("should find the paginated users, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Total != 3 || result.Name != "select_with_total" {
+			t.Errorf("should scan total of the matched users, got %+v", result)
+		}
+	}
+
+	type UserNameWithTotal struct {
+		Name  string
+		Total int64
+	}
+
+	var names []UserNameWithTotal
+	if err := DB.Model(&User{}).Select("name").Where("name = ?", "select_with_total").Limit(1).SelectWithTotal(&names, "Total").Error; err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	if len(names) != 1 || names[0].Name != "select_with_total" || names[0].Total != 3 {
+		t.Errorf("should scan total with selected columns, got %+v", names)
+	}
+
+	if err := DB.Model(&User{}).SelectWithTotal(&names, "Count").Error; !errors.Is(err, gorm.ErrInvalidField) {
+		t.Errorf("should return ErrInvalidField for unknown total field, got %v", err)
+	}
+
+	result := DB.Session(&gorm.Session{DryRun: true}).Model(&User{}).Limit(10).SelectWithTotal(&results, "Total")
+	if !regexp.MustCompile(`SELECT .users.\..id.,.*COUNT\(\*\) OVER\(\) AS .total. FROM .users.`).MatchString(result.Statement.SQL.String()) {
+		t.Errorf("invalid sql generated, got %v", result.Statement.SQL.String())
+	}
+}
+
+func TestQueryWithNamedExpr(t *testing.T) {
+	users := []User{*GetUser("named_expr", Config{}), *GetUser("named_expr", Config{}), *GetUser("named_expr", Config{})}
+	users[0].Age, users[1].Age, users[2].Age = 10, 20, 30
+	DB.Create(&users)
+
+	var results []User
+	err := DB.Where("name = ?", "named_expr").Where(gorm.NamedExpr("age > @min AND age < @max OR age = @min", map[string]interface{}{"min": 10, "max": 30})).Order("id").Find(&results).Error
+	if err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	}
+	if len(results) != 2 || results[0].ID != users[0].ID || results[1].ID != users[1].ID {
+		t.Errorf("should find users with named expr, got %+v", results)
+	}
+
+	err = DB.Where(gorm.NamedExpr("age > @min AND age < @max", sql.Named("min", 10))).Find(&results).Error
+	if err == nil || !strings.Contains(err.Error(), "@max") {
+		t.Errorf("should return error for the missing named argument, got %v", err)
+	}
+}
+
+func TestWarnOnUnindexedWhere(t *testing.T) {
+	type UnindexedWhereUser struct {
+		ID        uint
+		Code      string `gorm:"unique"`
+		TenantID  uint   `gorm:"index:idx_tenant_name"`
+		Name      string `gorm:"index:idx_tenant_name"`
+		Age       uint
+		Birthday  time.Time
+		DeletedAt gorm.DeletedAt `gorm:"index"`
+	}
+
+	var buf bytes.Buffer
+	tx := DB.Session(&gorm.Session{DryRun: true, Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn})})
+	tx.Config.WarnOnUnindexedWhere = true
+
+	tx.Where("age > ?", 18).Where(&UnindexedWhereUser{Name: "jinzhu"}).Find(&[]UnindexedWhereUser{})
+	if !strings.Contains(buf.String(), "without index may be slow: unindexed_where_users.age, unindexed_where_users.name,") {
+		t.Errorf("should warn on unindexed columns, got %q", buf.String())
+	}
+
+	buf.Reset()
+	tx.Where("tenant_id = ? AND name = ?", 1, "jinzhu").Or(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "code"}, Value: "x"}).Find(&[]UnindexedWhereUser{})
+	tx.Model(&UnindexedWhereUser{}).Where("id IN ?", []int{1, 2}).Update("age", 20)
+	tx.Where("pets.name = ?", "pet").Delete(&UnindexedWhereUser{})
+	if buf.Len() != 0 {
+		t.Errorf("should not warn on indexed columns or columns of other tables, got %q", buf.String())
+	}
+
+	buf.Reset()
+	tx.Model(&UnindexedWhereUser{}).Where("birthday < ?", time.Now()).Update("age", 20)
+	tx.Where(map[string]interface{}{"birthday": time.Now()}).Delete(&UnindexedWhereUser{})
+	if strings.Count(buf.String(), "without index may be slow: unindexed_where_users.birthday,") != 2 {
+		t.Errorf("should warn on unindexed columns when updating and deleting, got %q", buf.String())
+	}
+
+	buf.Reset()
+	DB.Session(&gorm.Session{DryRun: true, Logger: logger.New(log.New(&buf, "", 0), logger.Config{LogLevel: logger.Warn})}).Where("age > ?", 18).Find(&[]UnindexedWhereUser{})
+	if buf.Len() != 0 {
+		t.Errorf("should not warn when WarnOnUnindexedWhere is disabled, got %q", buf.String())
+	}
+}
@@ -1,6 +1,8 @@
 package tests_test
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -8,6 +10,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 	. "gorm.io/gorm/utils/tests"
 )
 
@@ -339,3 +342,118 @@ func TestScanToEmbedded(t *testing.T) {
 	err := DB.Raw("SELECT * FROM users INNER JOIN users Manager ON users.manager_id = Manager.id WHERE users.id = ?", user.ID).Scan(&user2).Error
 	AssertEqual(t, err, nil)
 }
+
+func TestScanWithScanLocation(t *testing.T) {
+	user := GetUser("scan-location", Config{})
+	birthday := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	user.Birthday = &birthday
+	DB.Create(user)
+
+	loc := time.FixedZone("scan-location", 8*60*60)
+	tx := DB.Session(&gorm.Session{})
+	tx.Config.ScanLocation = loc
+
+	var result User
+	if err := tx.First(&result, user.ID).Error; err != nil {
+		t.Fatalf("failed to query user, got error %v", err)
+	}
+
+	if result.CreatedAt.Location() != loc {
+		t.Errorf("time.Time field should be converted to location %v, got %v", loc, result.CreatedAt.Location())
+	}
+
+	if result.Birthday == nil || result.Birthday.Location() != loc {
+		t.Errorf("*time.Time field should be converted to location %v, got %v", loc, result.Birthday)
+	} else if !result.Birthday.Equal(birthday) {
+		t.Errorf("*time.Time field should keep the same instant, expect %v, got %v", birthday, result.Birthday)
+	}
+
+	var users []User
+	if err := tx.Where("id = ?", user.ID).Find(&users).Error; err != nil || len(users) != 1 {
+		t.Fatalf("failed to query users, got error %v", err)
+	} else if users[0].UpdatedAt.Location() != loc {
+		t.Errorf("time.Time field should be converted to location %v, got %v", loc, users[0].UpdatedAt.Location())
+	}
+}
+
+func TestScanWithOnScanError(t *testing.T) {
+	type ScanErrorResult struct {
+		Name  string
+		Age   uint
+		Score int
+		Tags  []string `gorm:"serializer:json"`
+	}
+
+	query := "SELECT ? AS name, ? AS age, ? AS score, ? AS tags"
+	args := []interface{}{"scan-error", "abc", "xyz", "not-json"}
+
+	var result ScanErrorResult
+	if err := DB.Raw(query, args...).Scan(&result).Error; err == nil {
+		t.Fatalf("should return the scan error without OnScanError")
+	}
+
+	var (
+		tx     = DB.Session(&gorm.Session{})
+		failed = map[string]interface{}{}
+	)
+	tx.Config.OnScanError = func(field *schema.Field, raw interface{}, err error) error {
+		failed[field.Name] = raw
+		return nil
+	}
+
+	result = ScanErrorResult{}
+	if err := tx.Raw(query, args...).Scan(&result).Error; err != nil {
+		t.Fatalf("should skip the failed fields, got error %v", err)
+	}
+
+	AssertEqual(t, result, ScanErrorResult{Name: "scan-error"})
+	if len(failed) != 3 || failed["Age"] != "abc" || failed["Score"] != "xyz" || failed["Tags"] == nil {
+		t.Errorf("should call OnScanError with the raw values, got %v", failed)
+	}
+
+	tx.Config.OnScanError = func(field *schema.Field, raw interface{}, err error) error {
+		return fmt.Errorf("invalid %v %v: %w", field.Name, raw, err)
+	}
+	if err := tx.Raw(query, args...).Scan(&result).Error; err == nil || !strings.Contains(err.Error(), "invalid Age abc") {
+		t.Errorf("should return the error of OnScanError, got %v", err)
+	}
+}
+
+var errInvalidScanCode = errors.New("invalid scan code")
+
+type scanCode string
+
+func (c *scanCode) Scan(src interface{}) error {
+	if v, ok := src.(string); ok && strings.HasPrefix(v, "C") {
+		*c = scanCode(v)
+		return nil
+	}
+	return errInvalidScanCode
+}
+
+func TestScanWithOnScanErrorScanner(t *testing.T) {
+	type ScanCodeResult struct {
+		Code  scanCode
+		Name  string
+		Other scanCode
+	}
+
+	var (
+		tx     = DB.Session(&gorm.Session{})
+		failed []string
+		result ScanCodeResult
+	)
+	tx.Config.OnScanError = func(field *schema.Field, raw interface{}, err error) error {
+		if !errors.Is(err, errInvalidScanCode) {
+			t.Errorf("should pass the error of the scanner, got %v", err)
+		}
+		failed = append(failed, fmt.Sprintf("%v:%v", field.Name, raw))
+		return nil
+	}
+
+	if err := tx.Raw("SELECT ? AS code, ? AS name, ? AS other", "X1", "scan-code", "C2").Scan(&result).Error; err != nil {
+		t.Fatalf("should skip the failed fields, got error %v", err)
+	}
+	AssertEqual(t, result, ScanCodeResult{Name: "scan-code", Other: "C2"})
+	AssertEqual(t, failed, []string{"Code:X1"})
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/jinzhu/now"
 	"gorm.io/gorm"
@@ -167,3 +168,116 @@ func TestSoftDeleteZeroValue(t *testing.T) {
 		t.Errorf("Can't find permanently deleted record")
 	}
 }
+
+func TestSoftDeleteFlag(t *testing.T) {
+	type LegacyFlagBook struct {
+		ID        uint
+		Name      string
+		IsDeleted bool `gorm:"softDelete:flag"`
+	}
+	DB.Migrator().DropTable(&LegacyFlagBook{})
+	if err := DB.AutoMigrate(&LegacyFlagBook{}); err != nil {
+		t.Fatalf("failed to auto migrate soft delete table")
+	}
+
+	book := LegacyFlagBook{Name: "soft_delete_flag"}
+	DB.Save(&book)
+
+	if err := DB.Delete(&book).Error; err != nil {
+		t.Fatalf("No error should happen when soft delete book, but got %v", err)
+	}
+
+	if !book.IsDeleted {
+		t.Errorf("book should be flagged deleted")
+	}
+
+	if err := DB.First(&LegacyFlagBook{}, "name = ?", book.Name).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Can't find a soft deleted record, got err %v", err)
+	}
+
+	var result LegacyFlagBook
+	if err := DB.Unscoped().First(&result, "name = ?", book.Name).Error; err != nil || !result.IsDeleted {
+		t.Errorf("Should find flagged soft deleted record with Unscoped, but got %+v, err %v", result, err)
+	}
+
+	actualSQL := DB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&LegacyFlagBook{})
+	})
+	if !regexp.MustCompile(`WHERE .legacy_flag_books.\..is_deleted. = (false|0)`).MatchString(actualSQL) {
+		t.Fatalf("invalid sql generated, got %v", actualSQL)
+	}
+}
+
+func TestSoftDeleteAliasColumn(t *testing.T) {
+	type LegacyTimeBook struct {
+		ID        uint
+		Name      string
+		RemovedAt *time.Time `gorm:"softDelete;column:removed_at"`
+	}
+	DB.Migrator().DropTable(&LegacyTimeBook{})
+	if err := DB.AutoMigrate(&LegacyTimeBook{}); err != nil {
+		t.Fatalf("failed to auto migrate soft delete table")
+	}
+
+	book := LegacyTimeBook{Name: "soft_delete_alias_column"}
+	DB.Save(&book)
+
+	if err := DB.Delete(&book).Error; err != nil {
+		t.Fatalf("No error should happen when soft delete book, but got %v", err)
+	}
+
+	if book.RemovedAt == nil {
+		t.Errorf("book's removed at should be set")
+	}
+
+	if err := DB.First(&LegacyTimeBook{}, "name = ?", book.Name).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Can't find a soft deleted record, got err %v", err)
+	}
+
+	if err := DB.Unscoped().First(&LegacyTimeBook{}, "name = ?", book.Name).Error; err != nil {
+		t.Errorf("Should find soft deleted record with Unscoped, but got err %v", err)
+	}
+
+	type InvalidSoftDeleteBook struct {
+		ID        uint
+		IsDeleted bool `gorm:"softDelete:unknown"`
+	}
+	if err := DB.First(&InvalidSoftDeleteBook{}).Error; err == nil {
+		t.Errorf("should return error for unsupported soft delete mode")
+	}
+}
+
+func TestUnscopedSession(t *testing.T) {
+	user := *GetUser("UnscopedSession", Config{Pets: 2})
+	DB.Save(&user)
+	DB.Delete(&user.Pets[0])
+	DB.Delete(&user)
+
+	tx := DB.Session(&gorm.Session{Unscoped: true})
+
+	var result User
+	if err := tx.Preload("Pets").First(&result, user.ID).Error; err != nil {
+		t.Fatalf("should find soft deleted record in unscoped session, got error %v", err)
+	}
+
+	if len(result.Pets) != 2 {
+		t.Errorf("should preload soft deleted associations in unscoped session, got %v", len(result.Pets))
+	}
+
+	var count int64
+	if tx.Model(&User{}).Where("name = ?", user.Name).Count(&count); count != 1 {
+		t.Errorf("should count soft deleted record in unscoped session, got %v", count)
+	}
+
+	if err := DB.First(&User{}, user.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("unscoped session should not leak to the parent db, got error %v", err)
+	}
+
+	if err := tx.Delete(&user.Pets[0]).Error; err != nil {
+		t.Fatalf("failed to delete in unscoped session, got error %v", err)
+	}
+
+	if err := DB.Unscoped().First(&Pet{}, user.Pets[0].ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should permanently delete in unscoped session, got error %v", err)
+	}
+}
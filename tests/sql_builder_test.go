@@ -1,11 +1,16 @@
 package tests_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
@@ -192,6 +197,301 @@ func (ageFloat) String() string {
 	return "age"
 }
 
+func TestResourceGroup(t *testing.T) {
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open mysql dialector, got error %v", err)
+	}
+
+	stmt := mysqlDB.ResourceGroup("reporting").Where("age > ?", 18).Find(&[]User{}).Statement
+	if !regexp.MustCompile("^SELECT /\\*\\+ RESOURCE_GROUP\\(reporting\\) \\*/ \\* FROM `users`").MatchString(stmt.SQL.String()) {
+		t.Errorf("should add resource group hint to select, got %v", stmt.SQL.String())
+	}
+
+	stmt = mysqlDB.ResourceGroup("reporting").Model(&User{}).Where("id = ?", 1).Update("age", 20).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "UPDATE /*+ RESOURCE_GROUP(reporting) */ `users` SET") {
+		t.Errorf("should add resource group hint to update, got %v", stmt.SQL.String())
+	}
+
+	stmt = mysqlDB.ResourceGroup("reporting").Model(&User{}).Where("age > ?", 18).Count(new(int64)).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT /*+ RESOURCE_GROUP(reporting) */ count(*) FROM `users`") {
+		t.Errorf("should add resource group hint to count, got %v", stmt.SQL.String())
+	}
+
+	stmt = mysqlDB.Clauses(clause.OptimizerHint{Hints: []string{"MAX_EXECUTION_TIME(1000)"}}).ResourceGroup("reporting").
+		Clauses(clause.OptimizerHint{Hints: []string{"BKA(users)"}}).Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT /*+ MAX_EXECUTION_TIME(1000) RESOURCE_GROUP(reporting) BKA(users) */ * FROM `users`") {
+		t.Errorf("should merge resource group hint with optimizer hints, got %v", stmt.SQL.String())
+	}
+
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+	stmt = dryRunDB.ResourceGroup("reporting").Find(&[]User{}).Statement
+	if strings.Contains(stmt.SQL.String(), "RESOURCE_GROUP") {
+		t.Errorf("resource group should be no-op on %v, got %v", DB.Dialector.Name(), stmt.SQL.String())
+	}
+
+	if err := dryRunDB.ResourceGroup("reporting */ DROP").Find(&[]User{}).Error; err == nil {
+		t.Errorf("should return error for invalid resource group name")
+	}
+}
+
+func TestOptimizerHint(t *testing.T) {
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open mysql dialector, got error %v", err)
+	}
+
+	stmt := mysqlDB.Clauses(clause.OptimizerHint{Hints: []string{"MAX_EXECUTION_TIME(1000)"}}).Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `users`") {
+		t.Errorf("should add optimizer hint to select, got %v", stmt.SQL.String())
+	}
+
+	stmt = mysqlDB.Clauses(clause.OptimizerHint{Hints: []string{"NO_RANGE_OPTIMIZATION(users)"}}).
+		Clauses(clause.OptimizerHint{Hints: []string{"BKA(users)"}}).Model(&User{}).Where("id = ?", 1).Update("age", 20).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "UPDATE /*+ NO_RANGE_OPTIMIZATION(users) BKA(users) */ `users` SET") {
+		t.Errorf("should merge optimizer hints of update, got %v", stmt.SQL.String())
+	}
+
+	tx := mysqlDB.Model(&User{})
+	tx.Statement.Clauses["SELECT"] = clause.Clause{AfterNameExpression: clause.Expr{SQL: "SQL_NO_CACHE"}}
+	stmt = tx.Clauses(clause.OptimizerHint{Hints: []string{"BKA(users)"}}).Clauses(clause.OptimizerHint{Hints: []string{"NO_ICP(users)"}}).Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT SQL_NO_CACHE /*+ BKA(users) NO_ICP(users) */ * FROM `users`") {
+		t.Errorf("should keep other expressions after the keyword, got %v", stmt.SQL.String())
+	}
+
+	if err := mysqlDB.Clauses(clause.OptimizerHint{Hints: []string{"BKA(users) */ DROP"}}).Find(&[]User{}).Error; err == nil {
+		t.Errorf("should return error for invalid optimizer hint")
+	}
+
+	if DB.Dialector.Name() != "mysql" {
+		stmt = DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OptimizerHint{Hints: []string{"MAX_EXECUTION_TIME(1000)"}}).Find(&[]User{}).Statement
+		if strings.Contains(stmt.SQL.String(), "/*+") || strings.Contains(stmt.SQL.String(), "SELECT  ") {
+			t.Errorf("optimizer hint should be no-op on %v, got %v", DB.Dialector.Name(), stmt.SQL.String())
+		}
+	}
+}
+
+func TestFromOnly(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	stmt := postgresDB.Clauses(clause.From{Tables: []clause.Table{{Name: "measurements"}}, Only: true}).Table("measurements").Find(&[]map[string]interface{}{}).Statement
+	if stmt.SQL.String() != `SELECT * FROM ONLY "measurements"` {
+		t.Errorf("should query the table without its children, got %v", stmt.SQL.String())
+	}
+
+	stmt = postgresDB.Clauses(clause.From{Only: true}).Joins("Company").Find(&[]User{}).Statement
+	if !regexp.MustCompile(`^SELECT .* FROM ONLY "users" LEFT JOIN "companies" "Company" ON `).MatchString(stmt.SQL.String()) {
+		t.Errorf("should keep ONLY when joining, got %v", stmt.SQL.String())
+	}
+
+	if DB.Dialector.Name() != "postgres" {
+		stmt = DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.From{Only: true}).Find(&[]User{}).Statement
+		if strings.Contains(stmt.SQL.String(), "ONLY") {
+			t.Errorf("ONLY should be ignored on %v, got %v", DB.Dialector.Name(), stmt.SQL.String())
+		}
+	}
+}
+
+func TestTableSample(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	seed := int64(42)
+	stmt := postgresDB.Clauses(clause.TableSample{Method: "BERNOULLI", Percent: 10, Seed: &seed}).Where("age > ?", 18).Find(&[]User{}).Statement
+	if !regexp.MustCompile(`^SELECT \* FROM "users" TABLESAMPLE BERNOULLI \(\$1\) REPEATABLE \(\$2\) WHERE age > \$3`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should sample the table with seed, got %v", stmt.SQL.String())
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{float64(10), int64(42), 18})
+
+	stmt = postgresDB.Clauses(clause.TableSample{Method: "SYSTEM", Percent: 1.5}).Joins("Company").Find(&[]User{}).Statement
+	if !regexp.MustCompile(`^SELECT .* FROM "users" TABLESAMPLE SYSTEM \(\$1\) LEFT JOIN "companies" "Company" ON `).MatchString(stmt.SQL.String()) {
+		t.Errorf("should sample the table without seed before joins, got %v", stmt.SQL.String())
+	}
+
+	if err := postgresDB.Clauses(clause.TableSample{Method: "BERNOULLI (10); DROP", Percent: 10}).Find(&[]User{}).Error; err == nil {
+		t.Errorf("should return error for invalid table sample method")
+	}
+}
+
+func TestFromMultipleTables(t *testing.T) {
+	user := *GetUser("from_multiple_tables", Config{Pets: 2})
+	DB.Create(&user)
+
+	var users []User
+	if err := DB.Clauses(clause.From{Tables: []clause.Table{{Name: "users"}, {Name: "pets"}}}).
+		Where("users.id = pets.user_id AND pets.name = ?", user.Pets[1].Name).Find(&users).Error; err != nil {
+		t.Fatalf("failed to query from multiple tables, got error %v", err)
+	}
+
+	if len(users) != 1 {
+		t.Fatalf("should find 1 user, got %v", len(users))
+	}
+	AssertObjEqual(t, users[0], user, "ID", "Name", "Age", "CreatedAt")
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.From{Tables: []clause.Table{{Name: "users"}, {Name: "pets"}}}).
+		Where("users.id = pets.user_id").Find(&[]User{}).Statement
+	if !regexp.MustCompile("^SELECT .users.\\..id.,.* FROM .users.,.pets. WHERE users.id = pets.user_id AND .users.\\..deleted_at. IS NULL$").MatchString(stmt.SQL.String()) {
+		t.Errorf("should select columns of the current table from multiple tables, got %v", stmt.SQL.String())
+	}
+
+	type UserWithPet struct {
+		Name    string
+		PetName string
+	}
+	var results []UserWithPet
+	if err := DB.Table("users").Clauses(clause.From{Tables: []clause.Table{{Name: "users"}, {Name: "pets"}}}).
+		Select("users.name, pets.name AS pet_name").Where("users.id = pets.user_id AND pets.name = ?", user.Pets[1].Name).Find(&results).Error; err != nil {
+		t.Fatalf("failed to query from multiple tables into other struct, got error %v", err)
+	}
+	AssertEqual(t, results, []UserWithPet{{Name: user.Name, PetName: user.Pets[1].Name}})
+
+	stmt = DB.Session(&gorm.Session{DryRun: true}).Table("users").Clauses(clause.From{Tables: []clause.Table{{Name: "users"}, {Name: "pets"}}}).
+		Find(&[]UserWithPet{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT * FROM") {
+		t.Errorf("should not select columns of the current table into other struct, got %v", stmt.SQL.String())
+	}
+
+	stmt = DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.From{Tables: []clause.Table{{Name: "users", Alias: "u"}, {Name: "pets", Alias: "p"}}}).
+		Unscoped().Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT * FROM") {
+		t.Errorf("should not select columns of the current table when it's aliased, got %v", stmt.SQL.String())
+	}
+}
+
+func TestRawBuilder(t *testing.T) {
+	users := []User{
+		*GetUser("raw_builder_1", Config{}), *GetUser("raw_builder_2", Config{}),
+		*GetUser("raw_builder_3", Config{}), *GetUser("other_raw_builder", Config{}),
+	}
+	users[0].Age, users[1].Age, users[2].Age, users[3].Age = 10, 20, 30, 40
+	DB.Create(&users)
+	DB.Delete(&users[2])
+
+	var results []User
+	if err := DB.RawBuilder("t", "SELECT * FROM users WHERE age >= ? AND deleted_at IS NULL", 20).Where("name LIKE ?", "raw_builder%").Order("id").Limit(10).Find(&results).Error; err != nil {
+		t.Fatalf("failed to query with raw builder, got error %v", err)
+	}
+
+	if len(results) != 1 || results[0].Name != "raw_builder_2" {
+		t.Errorf("should find raw_builder_2, got %+v", results)
+	}
+
+	var count int64
+	if err := DB.NamedRawBuilder("t", "SELECT * FROM users WHERE name LIKE @name AND deleted_at IS NULL", sql.Named("name", "%raw_builder%")).Model(&User{}).Count(&count).Error; err != nil || count != 3 {
+		t.Errorf("should count 3 users with named args, got %v, %v", count, err)
+	}
+
+	stmt := DB.Session(&gorm.Session{DryRun: true}).RawBuilder("u", "SELECT * FROM users WHERE email = 'a@b.c' AND age > ?", 18).Where("name = ?", "jinzhu").Limit(10).Find(&[]User{}).Statement
+	if !regexp.MustCompile("^SELECT \\* FROM \\(SELECT \\* FROM users WHERE email = 'a@b.c' AND age > .+\\) AS .u. WHERE name = .+ LIMIT").MatchString(stmt.SQL.String()) {
+		t.Errorf("should wrap the raw sql as a derived table, got %v", stmt.SQL.String())
+	}
+	if strings.Contains(stmt.SQL.String(), "deleted_at") {
+		t.Errorf("should not apply query clauses of the model to the derived table, got %v", stmt.SQL.String())
+	}
+	AssertEqual(t, stmt.Vars[:2], []interface{}{18, "jinzhu"})
+}
+
+func TestWithInsert(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	ins := postgresDB.Create(&Company{Name: "cte_company"})
+	stmt := postgresDB.WithInsert("ins", ins).Table("users").Create(map[string]interface{}{
+		"name": "cte_user", "company_id": gorm.Expr("(SELECT id FROM ins)"), "age": 18,
+	}).Statement
+
+	expected := `WITH "ins" AS (INSERT INTO "companies" ("name") VALUES ($1) RETURNING "id") INSERT INTO "users" ("age","company_id","name") VALUES ($2,(SELECT id FROM ins),$3)`
+	if stmt.SQL.String() != expected {
+		t.Errorf("should insert with the insert CTE, expects %v, got %v", expected, stmt.SQL.String())
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{"cte_company", 18, "cte_user"})
+
+	if err := postgresDB.WithInsert("ins", postgresDB.Model(&Company{})).Table("users").Create(map[string]interface{}{"name": "cte_user"}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData for an insert not built, got %v", err)
+	}
+}
+
+type tenantCtxKey struct{}
+
+func TestSchemaResolver(t *testing.T) {
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open mysql dialector, got error %v", err)
+	}
+	mysqlDB.Config.SchemaResolver = func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantCtxKey{}).(string)
+		return tenant
+	}
+
+	tx := mysqlDB.WithContext(context.WithValue(context.Background(), tenantCtxKey{}, "tenant_abc"))
+
+	stmt := tx.Where("age > ?", 18).Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT * FROM `tenant_abc`.`users` WHERE") {
+		t.Errorf("should prefix table with resolved schema, got %v", stmt.SQL.String())
+	}
+
+	stmt = tx.Model(&User{}).Where("id = ?", 1).Update("age", 20).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "UPDATE `tenant_abc`.`users` SET") {
+		t.Errorf("should prefix table with resolved schema for update, got %v", stmt.SQL.String())
+	}
+
+	stmt = tx.Create(&User{Name: "schema_resolver"}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "INSERT INTO `tenant_abc`.`users`") {
+		t.Errorf("should prefix table with resolved schema for create, got %v", stmt.SQL.String())
+	}
+
+	stmt = tx.Table("other.users").Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT * FROM `other`.`users`") {
+		t.Errorf("should keep qualified table, got %v", stmt.SQL.String())
+	}
+
+	stmt = mysqlDB.Find(&[]User{}).Statement
+	if !strings.HasPrefix(stmt.SQL.String(), "SELECT * FROM `users`") {
+		t.Errorf("should not prefix table when no schema resolved, got %v", stmt.SQL.String())
+	}
+}
+
+func TestValuesTable(t *testing.T) {
+	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
+
+	stmt := dryRunDB.ValuesTable("t", []string{"id", "name"}, [][]interface{}{{1, "a"}, {2, "b"}}).
+		Joins("JOIN users ON users.id = t.id").Where("t.name <> ?", "c").Select("users.*").Find(&[]User{}).Statement
+	if !regexp.MustCompile(`SELECT users.\* FROM \(VALUES \(.+,.+\),\(.+,.+\)\) AS .t. \(.id.,.name.\) JOIN users ON users.id = t.id WHERE t.name <> .+$`).MatchString(stmt.SQL.String()) {
+		t.Errorf("should build values table, got %v", stmt.SQL.String())
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{1, "a", 2, "b", "c"})
+
+	if err := dryRunDB.ValuesTable("t", []string{"id", "name"}, [][]interface{}{{1}}).Find(&[]User{}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData when row length mismatch, got %v", err)
+	}
+
+	if DB.Dialector.Name() == "sqlite" {
+		users := []User{{Name: "values_table_1"}, {Name: "values_table_2"}, {Name: "values_table_3"}}
+		DB.Create(&users)
+
+		DB.Delete(&users[2])
+
+		// sqlite doesn't support column aliases of derived table, columns are named column1, column2...
+		var results []User
+		if err := DB.ValuesTable("t", nil, [][]interface{}{{"values_table_1"}, {"values_table_3"}}).
+			Joins("JOIN users ON users.name = t.column1").Select("users.*").Order("users.id").Find(&results).Error; err != nil {
+			t.Fatalf("failed to join values table, got error %v", err)
+		}
+
+		// the soft delete condition of User isn't applied to the values table, nor to the joined users table
+		if len(results) != 2 || results[0].Name != "values_table_1" || results[1].Name != "values_table_3" {
+			t.Errorf("should find users joined with values table, got %+v", results)
+		}
+	}
+}
+
 func TestExplainSQL(t *testing.T) {
 	user := *GetUser("explain-sql", Config{})
 	dryRunDB := DB.Session(&gorm.Session{DryRun: true})
@@ -456,6 +756,122 @@ func TestToSQL(t *testing.T) {
 }
 
 // assertEqualSQL for assert that the sql is equal, this method will ignore quote, and dialect specials.
+func TestBuildSQL(t *testing.T) {
+	sql, vars, err := DB.BuildSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Where("name = ? AND age > ?", "build_sql", 18).Find(&[]User{})
+	})
+	if err != nil {
+		t.Fatalf("failed to build sql, got error %v", err)
+	}
+
+	if !regexp.MustCompile(`SELECT \* FROM .users. WHERE \(name = .+ AND age > .+\)`).MatchString(sql) || strings.Contains(sql, "build_sql") {
+		t.Errorf("should build parameterized sql, got %v", sql)
+	}
+	AssertEqual(t, vars, []interface{}{"build_sql", 18})
+
+	if DB.Statement.SQL.String() != "" || len(DB.Statement.Vars) != 0 {
+		t.Errorf("should not change the statement of DB")
+	}
+
+	if _, _, err := DB.BuildSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Delete(&User{})
+	}); !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("should return the error of the statement, got %v", err)
+	}
+
+	if _, _, err := DB.BuildSQL(func(tx *gorm.DB) *gorm.DB { return nil }); !errors.Is(err, gorm.ErrInvalidDB) {
+		t.Errorf("should return ErrInvalidDB when no db returned, got %v", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	result, err := DB.Explain(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&User{}).Where("name = ?", "explain").Find(&[]User{})
+	})
+	if err != nil {
+		t.Fatalf("failed to explain, got error %v", err)
+	}
+
+	if !regexp.MustCompile(`SELECT \* FROM .users. WHERE name = .+ AND .users.\..deleted_at. IS NULL`).MatchString(result.SQL) {
+		t.Errorf("should build the sql, got %v", result.SQL)
+	}
+	AssertEqual(t, result.Vars, []interface{}{"explain"})
+	AssertEqual(t, result.Table, "users")
+	AssertEqual(t, result.Operation, "SELECT")
+	if _, ok := result.Clauses["WHERE"]; !ok {
+		t.Errorf("should have the WHERE clause, got %v", result.Clauses)
+	}
+
+	// the clauses are a snapshot, modifying the statement afterward doesn't change them
+	var explained *gorm.DB
+	result, _ = DB.Explain(func(tx *gorm.DB) *gorm.DB {
+		explained = tx.Model(&User{}).Where("name = ?", "explain").Limit(10).Find(&[]User{})
+		return explained
+	})
+	where := explained.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	where.Exprs[0] = clause.Expr{SQL: "modified"}
+	*explained.Statement.Clauses["LIMIT"].Expression.(clause.Limit).Limit = 20
+	if expr, ok := result.Clauses["WHERE"].Expression.(clause.Where).Exprs[0].(clause.Expr); !ok || expr.SQL != "name = ?" {
+		t.Errorf("should keep the WHERE clause in the snapshot, got %+v", result.Clauses["WHERE"].Expression)
+	}
+	if limit := result.Clauses["LIMIT"].Expression.(clause.Limit).Limit; limit == nil || *limit != 10 {
+		t.Errorf("should keep the LIMIT clause in the snapshot, got %v", limit)
+	}
+
+	for op, queryFn := range map[string]func(tx *gorm.DB) *gorm.DB{
+		"INSERT": func(tx *gorm.DB) *gorm.DB { return tx.Create(GetUser("explain", Config{})) },
+		"UPDATE": func(tx *gorm.DB) *gorm.DB { return tx.Model(&User{}).Where("id = ?", 1).Update("age", 20) },
+		"DELETE": func(tx *gorm.DB) *gorm.DB { return tx.Unscoped().Where("id = ?", 1).Delete(&User{}) },
+		"":       func(tx *gorm.DB) *gorm.DB { return tx.Raw("SELECT 1") },
+	} {
+		if result, err := DB.Explain(queryFn); err != nil || result.Operation != op {
+			t.Errorf("should explain %q, got %+v, error %v", op, result, err)
+		}
+	}
+
+	result, _ = DB.Explain(func(tx *gorm.DB) *gorm.DB { return tx.Delete(&User{}, 1) })
+	if result.Operation != "UPDATE" || !strings.HasPrefix(result.SQL, "UPDATE") {
+		t.Errorf("soft delete should be an UPDATE, got %+v", result)
+	}
+
+	var count int64
+	DB.Model(&User{}).Where("name = ?", "explain").Count(&count)
+	if count != 0 {
+		t.Errorf("should not execute the statement, got %v records", count)
+	}
+
+	if _, err := DB.Explain(func(tx *gorm.DB) *gorm.DB { return tx.Model(&User{}).Delete(&User{}) }); !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("should return the error of the statement, got %v", err)
+	}
+}
+
+func TestQuoteTo(t *testing.T) {
+	quoteTo := func(writer clause.Writer, str string) bool {
+		if name, ok := strings.CutSuffix(str, "@remote"); ok {
+			gorm.QuoteIdentifierTo(writer, name, '"')
+			writer.WriteString("@remote")
+			return true
+		}
+		return false
+	}
+
+	tx := DB.Session(&gorm.Session{DryRun: true, QuoteTo: quoteTo})
+	stmt := tx.Table("users@remote").Select("name").Where(map[string]interface{}{"age": 18}).Find(&[]User{}).Statement
+	if !regexp.MustCompile(`^SELECT .name. FROM "users"@remote WHERE "users"@remote\..age. = `).MatchString(stmt.SQL.String()) {
+		t.Errorf("should quote with QuoteTo and fall back to the dialector, got %v", stmt.SQL.String())
+	}
+
+	stmt = tx.Clauses(clause.Eq{Column: clause.Column{Name: `age" = 1 OR 1=1; --@remote`}, Value: 18}).Find(&[]User{}).Statement
+	if !strings.Contains(stmt.SQL.String(), `WHERE "age"" = 1 OR 1=1; --"@remote = `) {
+		t.Errorf("should escape the identifier, got %v", stmt.SQL.String())
+	}
+
+	stmt = DB.Session(&gorm.Session{DryRun: true}).Table("users@remote").Find(&[]User{}).Statement
+	if strings.Contains(stmt.SQL.String(), `"users"@remote`) {
+		t.Errorf("QuoteTo should be scoped to the session, got %v", stmt.SQL.String())
+	}
+}
+
 func assertEqualSQL(t *testing.T, expected string, actually string) {
 	t.Helper()
 
@@ -497,3 +913,36 @@ func replaceQuoteInSQL(sql string) string {
 
 	return sql
 }
+
+func TestDistinctOn(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	stmt := postgresDB.Model(&Pet{}).DistinctOn("UserID").Order("user_id").Order("created_at DESC").Find(&[]Pet{}).Statement
+	if stmt.Error != nil {
+		t.Fatalf("no error should happen, got %v", stmt.Error)
+	}
+
+	expected := `SELECT DISTINCT ON ("user_id") * FROM "pets" WHERE "pets"."deleted_at" IS NULL ORDER BY user_id,created_at DESC`
+	if sql := stmt.SQL.String(); sql != expected {
+		t.Errorf("expected %v, got %v", expected, sql)
+	}
+
+	if err := postgresDB.Model(&Pet{}).DistinctOn("user_id", "name").Order("pets.name, user_id").Order("id").Find(&[]Pet{}).Error; err != nil {
+		t.Errorf("leading order columns in any order should be allowed, got %v", err)
+	}
+
+	if err := postgresDB.Model(&Pet{}).DistinctOn("user_id").Find(&[]Pet{}).Error; err != nil {
+		t.Errorf("distinct on without order should be allowed, got %v", err)
+	}
+
+	if err := postgresDB.Model(&Pet{}).DistinctOn("user_id").Order("created_at DESC").Order("user_id").Find(&[]Pet{}).Error; !errors.Is(err, gorm.ErrDistinctOnOrderMismatch) {
+		t.Errorf("should return ErrDistinctOnOrderMismatch, got %v", err)
+	}
+
+	if err := postgresDB.Model(&Pet{}).DistinctOn("user_id", "name").Order("user_id").Find(&[]Pet{}).Error; !errors.Is(err, gorm.ErrDistinctOnOrderMismatch) {
+		t.Errorf("should return ErrDistinctOnOrderMismatch for partial order, got %v", err)
+	}
+}
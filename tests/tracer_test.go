@@ -2,9 +2,14 @@ package tests_test
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"testing"
 	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	. "gorm.io/gorm/utils/tests"
 )
 
 type Tracer struct {
@@ -32,3 +37,63 @@ func (S Tracer) Trace(ctx context.Context, begin time.Time, fc func() (sql strin
 	S.Logger.Trace(ctx, begin, fc, err)
 	S.Test(ctx, begin, fc, err)
 }
+
+type StructuredTracer struct {
+	Tracer
+	Fields []map[string]interface{}
+}
+
+func (S *StructuredTracer) TraceStructured(ctx context.Context, fields map[string]interface{}) {
+	S.Fields = append(S.Fields, fields)
+}
+
+func TestStructuredTracer(t *testing.T) {
+	tracer := &StructuredTracer{Tracer: Tracer{
+		Logger: logger.Discard,
+		Test: func(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+			t.Errorf("Trace should not be called when the logger implements StructuredTracer")
+		},
+	}}
+	tx := DB.Session(&gorm.Session{Logger: tracer})
+
+	user := *GetUser("structured_tracer", Config{})
+	if err := tx.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	var users []User
+	tx.Where("name = ?", user.Name).Find(&users)
+
+	var age int
+	tx.Raw("SELECT age FROM users WHERE id = ?", user.ID).Scan(&age)
+
+	tx.Table("not_existing_table").Find(&users)
+
+	if len(tracer.Fields) != 4 {
+		t.Fatalf("should trace 4 statements, got %v", len(tracer.Fields))
+	}
+
+	for idx, fields := range tracer.Fields {
+		if sql, _ := fields["sql"].(string); sql == "" {
+			t.Errorf("#%v should trace the sql, got %v", idx, fields)
+		}
+		if _, ok := fields["duration_ms"].(float64); !ok {
+			t.Errorf("#%v should trace the duration, got %v", idx, fields)
+		}
+		if caller, _ := fields["caller"].(string); !strings.Contains(caller, "tracer_test.go:") {
+			t.Errorf("#%v should trace the caller, got %v", idx, fields["caller"])
+		}
+	}
+
+	if sql := tracer.Fields[1]["sql"].(string); !strings.Contains(sql, "structured_tracer") {
+		t.Errorf("should explain the vars in sql, got %v", sql)
+	}
+
+	if rows := tracer.Fields[2]["rows"].(int64); rows != 1 || tracer.Fields[2]["error"] != nil {
+		t.Errorf("should trace rows and error of scan, got %v", tracer.Fields[2])
+	}
+
+	if err, _ := tracer.Fields[3]["error"].(error); err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("should trace the error, got %v", tracer.Fields[3]["error"])
+	}
+}
@@ -2,10 +2,13 @@ package tests_test
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 	"time"
 
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	. "gorm.io/gorm/utils/tests"
 )
@@ -92,6 +95,37 @@ func TestCancelTransaction(t *testing.T) {
 	}
 }
 
+func TestCancelDefaultTransactionBeforeCommit(t *testing.T) {
+	db, err := OpenTestConnection(&gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open connection, got error %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db, got error %v", err)
+	}
+	defer sqlDB.Close()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	// cancel the context between the write and the commit of the default transaction
+	db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("cancel_context_before_commit", func(*gorm.DB) {
+		cancelFunc()
+	})
+
+	user := *GetUser("cancel_default_transaction", Config{})
+	if err := db.WithContext(ctx).Create(&user).Error; !errors.Is(err, context.Canceled) {
+		t.Fatalf("should get context.Canceled error, but got %v", err)
+	}
+
+	var count int64
+	if DB.Model(&User{}).Where("name = ?", user.Name).Count(&count); count != 0 {
+		t.Errorf("no partial write should be committed, but found %v records", count)
+	}
+}
+
 func TestTransactionWithBlock(t *testing.T) {
 	assertPanic := func(f func()) {
 		defer func() {
@@ -450,6 +484,133 @@ func TestTransactionOnClosedConn(t *testing.T) {
 	}
 }
 
+type AfterCommitOrder struct {
+	ID     uint
+	Name   string
+	events *[]string
+}
+
+func (o *AfterCommitOrder) AfterCreate(tx *gorm.DB) error {
+	tx.AfterCommit(func() { *o.events = append(*o.events, "created "+o.Name) })
+	if o.Name == "invalid" {
+		return errors.New("invalid order")
+	}
+	return nil
+}
+
+func TestAfterCommit(t *testing.T) {
+	var events []string
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.Create(GetUser("after_commit_1", Config{}))
+		tx.AfterCommit(func() { events = append(events, "first") })
+		tx.AfterCommit(func() { events = append(events, "second") })
+
+		tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.AfterCommit(func() { events = append(events, "nested rollback") })
+			return errors.New("rollback nested transaction")
+		})
+
+		tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.AfterCommit(func() { events = append(events, "nested") })
+			return nil
+		})
+
+		if len(events) != 0 {
+			t.Errorf("should not run functions before commit, got %v", events)
+		}
+		return nil
+	})
+	AssertEqual(t, events, []string{"first", "second", "nested"})
+
+	events = nil
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.AfterCommit(func() { events = append(events, "rollback") })
+		return errors.New("rollback")
+	})
+
+	tx := DB.Begin()
+	tx.AfterCommit(func() { events = append(events, "rollback") })
+	tx.Rollback()
+	AssertEqual(t, len(events), 0)
+
+	tx = DB.Begin()
+	tx.Session(&gorm.Session{NewDB: true}).Where("1 = 1").AfterCommit(func() { events = append(events, "session") })
+	tx.Commit()
+	AssertEqual(t, events, []string{"session"})
+
+	events = nil
+	DB.AfterCommit(func() { events = append(events, "no transaction") })
+	AssertEqual(t, events, []string{"no transaction"})
+
+	DB.Migrator().DropTable(&AfterCommitOrder{})
+	if err := DB.AutoMigrate(&AfterCommitOrder{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	events = nil
+	DB.Create(&AfterCommitOrder{Name: "valid", events: &events})
+	DB.Create(&AfterCommitOrder{Name: "invalid", events: &events})
+	AssertEqual(t, events, []string{"created valid"})
+}
+
+func TestAfterRollback(t *testing.T) {
+	var events []string
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.AfterCommit(func() { events = append(events, "commit") })
+		tx.AddAfterRollback(func() { events = append(events, "first") })
+		tx.AddAfterRollback(func() { events = append(events, "second") })
+
+		tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.AddAfterRollback(func() { events = append(events, "nested") })
+			return errors.New("rollback nested transaction")
+		})
+		AssertEqual(t, events, []string{"nested"})
+
+		return errors.New("rollback")
+	})
+	AssertEqual(t, events, []string{"nested", "first", "second"})
+
+	events = nil
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.AddAfterRollback(func() { events = append(events, "rollback") })
+		tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.AddAfterRollback(func() { events = append(events, "nested rollback") })
+			return nil
+		})
+		return nil
+	})
+	AssertEqual(t, len(events), 0)
+
+	tx := DB.Begin()
+	tx.AddAfterRollback(func() { events = append(events, "rollback") })
+	tx.Rollback()
+	AssertEqual(t, events, []string{"rollback"})
+
+	events = nil
+	DB.AddAfterRollback(func() { events = append(events, "no transaction") })
+	AssertEqual(t, len(events), 0)
+}
+
+func TestAddAfterCommit(t *testing.T) {
+	var events []string
+	DB.Transaction(func(tx *gorm.DB) error {
+		tx.AddAfterCommit(func() { events = append(events, "first") })
+		tx.Transaction(func(tx2 *gorm.DB) error {
+			tx2.AddAfterCommit(func() { events = append(events, "nested") })
+			return nil
+		})
+		AssertEqual(t, len(events), 0)
+		return nil
+	})
+	AssertEqual(t, events, []string{"first", "nested"})
+
+	events = nil
+	user := GetUser("add_after_commit", Config{})
+	DB.Create(user).AddAfterCommit(func() { events = append(events, "created") })
+	DB.Create(user).AddAfterCommit(func() { events = append(events, "duplicated") })
+	AssertEqual(t, events, []string{"created"})
+}
+
 func TestTransactionWithHooks(t *testing.T) {
 	user := GetUser("tTestTransactionWithHooks", Config{Account: true})
 	DB.Create(&user)
@@ -490,3 +651,98 @@ func TestTransactionWithDefaultTimeout(t *testing.T) {
 		t.Errorf("should return error when transaction timeout, got error %v", err)
 	}
 }
+
+func TestTransactionWithSnapshot(t *testing.T) {
+	if DB.Dialector.Name() != "postgres" {
+		err := DB.Session(&gorm.Session{SnapshotID: "00000003-0000001B-1"}).Transaction(func(tx *gorm.DB) error { return nil })
+		if !errors.Is(err, gorm.ErrUnsupportedDriver) {
+			t.Errorf("should return ErrUnsupportedDriver when importing snapshot, got %v", err)
+		}
+		return
+	}
+
+	exporter := DB.Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	defer exporter.Rollback()
+
+	var snapshotID string
+	if err := exporter.Raw("SELECT pg_export_snapshot()").Scan(&snapshotID).Error; err != nil {
+		t.Fatalf("failed to export snapshot, got error %v", err)
+	}
+
+	user := *GetUser("transaction_with_snapshot", Config{})
+	if err := DB.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user, got error %v", err)
+	}
+
+	var count int64
+	if err := DB.Session(&gorm.Session{SnapshotID: snapshotID}).Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&User{}).Where("name = ?", user.Name).Count(&count).Error
+	}); err != nil {
+		t.Fatalf("failed to run transaction with snapshot, got error %v", err)
+	}
+
+	if count != 0 {
+		t.Errorf("should not find the user created after the snapshot exported, got %v", count)
+	}
+
+	err := DB.Session(&gorm.Session{SnapshotID: snapshotID}).Transaction(func(tx *gorm.DB) error {
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if !errors.Is(err, gorm.ErrInvalidTransaction) {
+		t.Errorf("should return ErrInvalidTransaction with READ COMMITTED isolation, got %v", err)
+	}
+}
+
+type snapshotTx struct {
+	gorm.ConnPool
+	pool *snapshotConnPool
+}
+
+func (tx *snapshotTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	tx.pool.execs = append(tx.pool.execs, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (tx *snapshotTx) Commit() error   { return nil }
+func (tx *snapshotTx) Rollback() error { return nil }
+
+type snapshotConnPool struct {
+	gorm.ConnPool
+	opts  *sql.TxOptions
+	execs []string
+}
+
+func (p *snapshotConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	p.opts = opts
+	return &snapshotTx{pool: p}, nil
+}
+
+func TestTransactionWithSnapshotStatement(t *testing.T) {
+	pool := &snapshotConnPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open db, got error %v", err)
+	}
+
+	if err := db.Session(&gorm.Session{SnapshotID: "00000003-0000001B-1"}).Transaction(func(tx *gorm.DB) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to run transaction with snapshot, got error %v", err)
+	}
+	AssertEqual(t, pool.execs, []string{"SET TRANSACTION SNAPSHOT '00000003-0000001B-1'"})
+	AssertEqual(t, pool.opts.Isolation, sql.LevelRepeatableRead)
+
+	pool.execs = nil
+	if err := db.Session(&gorm.Session{SnapshotID: "x'y"}).Transaction(func(tx *gorm.DB) error {
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}); err != nil {
+		t.Fatalf("failed to run transaction with snapshot, got error %v", err)
+	}
+	AssertEqual(t, pool.execs, []string{"SET TRANSACTION SNAPSHOT 'x''y'"})
+	AssertEqual(t, pool.opts, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+
+	pool.execs = nil
+	if err := db.Transaction(func(tx *gorm.DB) error { return nil }); err != nil || len(pool.execs) != 0 {
+		t.Errorf("should not import snapshot without SnapshotID, got %v, %v", err, pool.execs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/utils"
@@ -251,6 +252,21 @@ func TestBlockGlobalUpdate(t *testing.T) {
 	}
 }
 
+func TestGlobalUpdate(t *testing.T) {
+	tx := DB.Session(&gorm.Session{DryRun: true})
+	if err := tx.Model(&User{}).GlobalUpdate().Update("name", "global_update").Error; err != nil {
+		t.Errorf("should returns no error while global update is allowed, but got err %v", err)
+	}
+
+	if err := tx.Model(&User{}).Update("name", "global_update").Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("should only allow global update for the statement, got err %v", err)
+	}
+
+	if err := tx.GlobalUpdate().Delete(&User{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("should not allow global delete, got err %v", err)
+	}
+}
+
 func TestSelectWithUpdate(t *testing.T) {
 	user := *GetUser("select_update", Config{Account: true, Pets: 3, Toys: 3, Company: true, Manager: true, Team: 3, Languages: 3, Friends: 4})
 	DB.Create(&user)
@@ -931,3 +947,95 @@ func TestUpdateFrom(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdatesReturningOld(t *testing.T) {
+	users := []User{
+		*GetUser("updates-returning-old-1", Config{}),
+		*GetUser("updates-returning-old-2", Config{}),
+	}
+	users[0].Age = 10
+	users[1].Age = 20
+
+	if err := DB.Create(&users).Error; err != nil {
+		t.Fatalf("errors happened when create: %v", err)
+	}
+
+	var old []User
+	result := DB.Model(&User{}).Where("name LIKE ?", "updates-returning-old-%").UpdatesReturningOld(map[string]interface{}{"age": 30}, &old)
+	if result.Error != nil {
+		t.Fatalf("errors happened when updates: %v", result.Error)
+	} else if result.RowsAffected != 2 {
+		t.Errorf("should update two records, but got %v", result.RowsAffected)
+	}
+
+	if len(old) != 2 || old[0].Age+old[1].Age != 30 {
+		t.Errorf("should returns the old values, but got %+v", old)
+	}
+
+	var results []User
+	DB.Where("name LIKE ?", "updates-returning-old-%").Find(&results)
+	for _, user := range results {
+		if user.Age != 30 {
+			t.Errorf("user's age should be updated, but got %v", user.Age)
+		}
+	}
+
+	var oldUser User
+	if err := DB.Model(&users[0]).UpdatesReturningOld(User{Name: "updates-returning-old-new"}, &oldUser).Error; err != nil {
+		t.Fatalf("errors happened when updates: %v", err)
+	} else if oldUser.ID != users[0].ID || oldUser.Name != "updates-returning-old-1" {
+		t.Errorf("should returns the old user, but got %+v", oldUser)
+	}
+
+	old = nil
+	if err := DB.Model(&User{}).UpdatesReturningOld(map[string]interface{}{"age": 40}, &old).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Errorf("should returns missing where clause error, but got %v", err)
+	} else if len(old) != 0 {
+		t.Errorf("should not query and lock rows without conditions, but got %v rows", len(old))
+	}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	users := []User{*GetUser("bulk_update_1", Config{}), *GetUser("bulk_update_2", Config{}), *GetUser("bulk_update_3", Config{})}
+	DB.Create(&users)
+
+	result := DB.BulkUpdate(&User{}, []map[string]interface{}{
+		{"id": users[0].ID, "name": "bulk_update_1_new", "Age": 31},
+		{"id": users[1].ID, "name": "bulk_update_2_new", "Age": 32},
+	}, []string{"ID"})
+	if result.Error != nil {
+		t.Fatalf("failed to bulk update, got error %v", result.Error)
+	}
+	AssertEqual(t, result.RowsAffected, int64(2))
+
+	var results []User
+	DB.Order("id").Find(&results, []uint{users[0].ID, users[1].ID, users[2].ID})
+	if results[0].Name != "bulk_update_1_new" || results[0].Age != 31 || results[1].Name != "bulk_update_2_new" || results[1].Age != 32 {
+		t.Errorf("should update rows with their own values, got %+v", results[:2])
+	}
+
+	if results[2].Name != users[2].Name || results[2].Age != users[2].Age {
+		t.Errorf("should not update other rows, got %+v", results[2])
+	}
+
+	if err := DB.BulkUpdate(&User{}, []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2}}, []string{"id"}).Error; !errors.Is(err, gorm.ErrInvalidData) {
+		t.Errorf("should return ErrInvalidData when rows have different columns, got %v", err)
+	}
+
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	stmt := postgresDB.BulkUpdate(&User{}, []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}}, []string{"id"}).Statement
+	if stmt.SQL.String() != `UPDATE "users" SET "name"="v"."name" FROM (VALUES (CAST($1 AS bigint),CAST($2 AS text)),($3,$4)) AS "v" ("id","name") WHERE "users"."id"="v"."id"` {
+		t.Errorf("should build values based bulk update, got %v", stmt.SQL.String())
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{1, "a", 2, "b"})
+
+	birthday := time.Now()
+	stmt = postgresDB.BulkUpdate(&User{}, []map[string]interface{}{{"id": 1, "Age": 10, "Birthday": birthday}, {"id": 2, "Age": 20, "Birthday": birthday}}, []string{"id"}).Statement
+	if stmt.SQL.String() != `UPDATE "users" SET "age"="v"."age","birthday"="v"."birthday" FROM (VALUES (CAST($1 AS bigint),CAST($2 AS bigint),CAST($3 AS timestamptz)),($4,$5,$6)) AS "v" ("id","age","birthday") WHERE "users"."id"="v"."id"` {
+		t.Errorf("should cast values to the column types, got %v", stmt.SQL.String())
+	}
+}
@@ -1,10 +1,15 @@
 package tests_test
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	. "gorm.io/gorm/utils/tests"
@@ -326,3 +331,358 @@ func TestUpdateWithMissWhere(t *testing.T) {
 		t.Fatalf("invalid updating SQL, got %v", tx.Statement.SQL.String())
 	}
 }
+
+func TestCreateOrUpdate(t *testing.T) {
+	user := User{Name: "create_or_update", Age: 18}
+	created, err := DB.CreateOrUpdate(&user, User{Name: "create_or_update"}, map[string]interface{}{"age": 20})
+	if err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	} else if !created || user.ID == 0 {
+		t.Fatalf("user should be created, got %+v", user)
+	}
+
+	var user2 User
+	created, err = DB.CreateOrUpdate(&user2, User{Name: "create_or_update"}, map[string]interface{}{"age": 20})
+	if err != nil {
+		t.Fatalf("no error should happen, but got %v", err)
+	} else if created || user2.ID != user.ID || user2.Age != 20 {
+		t.Fatalf("user should be found and updated, got %+v", user2)
+	}
+
+	var result User
+	DB.First(&result, user.ID)
+	AssertEqual(t, result.Age, uint(20))
+
+	var count int64
+	DB.Model(&User{}).Where("name = ?", "create_or_update").Count(&count)
+	AssertEqual(t, count, int64(1))
+}
+
+func TestUpsertWithDoUpdateWhere(t *testing.T) {
+	if DB.Dialector.Name() == "mysql" || DB.Dialector.Name() == "sqlserver" {
+		t.Skip()
+	}
+
+	now := time.Now().Round(time.Second)
+	user := *GetUser("upsert_do_update_where", Config{})
+	user.UpdatedAt = now
+	DB.Create(&user)
+
+	upsert := func(name string, updatedAt time.Time) {
+		u := User{Model: gorm.Model{ID: user.ID, CreatedAt: user.CreatedAt, UpdatedAt: updatedAt}, Name: name, Age: user.Age}
+		if err := DB.Session(&gorm.Session{SkipHooks: true}).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "excluded.updated_at > users.updated_at"}}},
+		}).Create(&u).Error; err != nil {
+			t.Fatalf("failed to upsert, got %v", err)
+		}
+	}
+
+	upsert("upsert_do_update_where_older", now.Add(-time.Hour))
+
+	var result User
+	if DB.First(&result, user.ID); result.Name != user.Name {
+		t.Errorf("should not update with an older updated_at, got name %v", result.Name)
+	}
+
+	upsert("upsert_do_update_where_newer", now.Add(time.Hour))
+
+	if DB.First(&result, user.ID); result.Name != "upsert_do_update_where_newer" {
+		t.Errorf("should update with a newer updated_at, got name %v", result.Name)
+	}
+}
+
+func TestUpsertGuardedDoUpdates(t *testing.T) {
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open mysql dialector, got error %v", err)
+	}
+
+	onConflict := clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "VALUES(updated_at) > updated_at"}}},
+	}
+
+	stmt := mysqlDB.Clauses(onConflict).Create(&Language{Code: "guarded", Name: "guarded"}).Statement
+	if !strings.HasSuffix(stmt.SQL.String(), "ON DUPLICATE KEY UPDATE `name`=IF(VALUES(updated_at) > updated_at,VALUES(`name`),`name`)") {
+		t.Errorf("should guard do updates, got %v", stmt.SQL.String())
+	}
+}
+
+func TestUpsertWithTargetAndExcludedExpr(t *testing.T) {
+	stmt := DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"age": gorm.Expr("target.age + excluded.age")}),
+	}).Create(&User{Name: "upsert_target_expr", Age: 1}).Statement
+
+	if DB.Dialector.Name() != "mysql" && DB.Dialector.Name() != "sqlserver" {
+		if !regexp.MustCompile(`DO UPDATE SET .age.=.users.\.age \+ excluded\.age`).MatchString(stmt.SQL.String()) {
+			t.Errorf("should replace target with current table, got %v", stmt.SQL.String())
+		}
+	}
+
+	if DB.Dialector.Name() == "mysql" || DB.Dialector.Name() == "sqlserver" {
+		t.Skip()
+	}
+
+	user := *GetUser("upsert_target_expr", Config{})
+	user.Age = 10
+	DB.Create(&user)
+
+	upsert := User{Model: gorm.Model{ID: user.ID, CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt}, Name: user.Name, Age: 5}
+	if err := DB.Session(&gorm.Session{SkipHooks: true}).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"age": gorm.Expr("target.age + excluded.age")}),
+	}).Create(&upsert).Error; err != nil {
+		t.Fatalf("failed to upsert, got %v", err)
+	}
+
+	var result User
+	if DB.First(&result, user.ID); result.Age != 15 {
+		t.Errorf("should accumulate age on conflict, got %v", result.Age)
+	}
+}
+
+type UpsertReturningItem struct {
+	ID    uint
+	Code  string `gorm:"size:64;unique"`
+	Score int    `gorm:"default:10"`
+}
+
+// only sqlite, postgres support returning with on conflict
+func TestUpsertDoNothingReturning(t *testing.T) {
+	if DB.Dialector.Name() != "sqlite" && DB.Dialector.Name() != "postgres" {
+		t.Skip()
+	}
+
+	DB.Migrator().DropTable(&UpsertReturningItem{})
+	if err := DB.AutoMigrate(&UpsertReturningItem{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+
+	var existing []UpsertReturningItem
+	for i := 0; i < 60; i += 3 {
+		existing = append(existing, UpsertReturningItem{Code: fmt.Sprintf("do-nothing-returning-%v", i), Score: 1})
+	}
+	DB.Create(&existing)
+
+	checkItems := func(t *testing.T, items []*UpsertReturningItem, inserted int64, rowsAffected int64) {
+		if rowsAffected != inserted {
+			t.Errorf("rows affected should be %v, got %v", inserted, rowsAffected)
+		}
+
+		for idx, item := range items {
+			var result UpsertReturningItem
+			DB.First(&result, "code = ?", item.Code)
+			if idx%3 == 0 {
+				if item.ID != 0 {
+					t.Errorf("skipped item %v should not be populated, got %+v", idx, item)
+				}
+			} else if item.ID != result.ID || item.Score != 10 {
+				t.Errorf("inserted item %v should be populated with %+v, got %+v", idx, result, item)
+			}
+		}
+	}
+
+	newItems := func(prefix string) (items []*UpsertReturningItem) {
+		for i := 0; i < 60; i++ {
+			code := fmt.Sprintf("%v-%v", prefix, i)
+			if i%3 == 0 {
+				code = fmt.Sprintf("do-nothing-returning-%v", i)
+			}
+			items = append(items, &UpsertReturningItem{Code: code})
+		}
+		return
+	}
+
+	t.Run("DefaultReturning", func(t *testing.T) {
+		items := newItems("default-returning")
+		result := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&items)
+		if result.Error != nil {
+			t.Fatalf("failed to create, got error %v", result.Error)
+		}
+		checkItems(t, items, 40, result.RowsAffected)
+	})
+
+	t.Run("ReturningAll", func(t *testing.T) {
+		items := newItems("returning-all")
+		result := DB.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}, clause.Returning{}).Create(&items)
+		if result.Error != nil {
+			t.Fatalf("failed to create, got error %v", result.Error)
+		}
+		if len(items) != 60 {
+			t.Fatalf("created values should not be replaced by the returned rows, got %v", len(items))
+		}
+		checkItems(t, items, 40, result.RowsAffected)
+	})
+
+	t.Run("ReturningColumns", func(t *testing.T) {
+		items := newItems("returning-columns")
+		returning := clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "score"}}}
+		stmt := DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{DoNothing: true}, returning).Create(&items).Statement
+		if !regexp.MustCompile(`RETURNING .id.,.score.,.code.$`).MatchString(stmt.SQL.String()) {
+			t.Errorf("should return the conflict keys, got %v", stmt.SQL.String())
+		}
+
+		result := DB.Clauses(clause.OnConflict{DoNothing: true}, returning).Create(&items)
+		if result.Error != nil {
+			t.Fatalf("failed to create, got error %v", result.Error)
+		}
+		if len(returning.Columns) != 2 {
+			t.Errorf("should not change the returning clause of users, got %v", returning.Columns)
+		}
+		checkItems(t, items, 40, result.RowsAffected)
+	})
+}
+
+func TestInsertIgnore(t *testing.T) {
+	mysqlDB, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open mysql dialector, got error %v", err)
+	}
+
+	stmt := mysqlDB.InsertIgnore().Create(&Language{Code: "ignore", Name: "ignore"}).Statement
+	if sql := stmt.SQL.String(); !strings.HasPrefix(sql, "INSERT IGNORE INTO `languages`") || strings.Contains(sql, "ON DUPLICATE KEY") {
+		t.Errorf("should use INSERT IGNORE on mysql, got %v", sql)
+	}
+
+	if sql := mysqlDB.Create(&Language{Code: "ignore", Name: "ignore"}).Statement.SQL.String(); strings.Contains(sql, "IGNORE") {
+		t.Errorf("should not keep INSERT IGNORE for the next statement, got %v", sql)
+	}
+
+	if DB.Dialector.Name() == "mysql" || DB.Dialector.Name() == "sqlserver" {
+		return
+	}
+
+	if sql := DB.Session(&gorm.Session{DryRun: true}).InsertIgnore().Create(&Language{Code: "ignore", Name: "ignore"}).Statement.SQL.String(); !strings.HasSuffix(sql, "ON CONFLICT DO NOTHING") {
+		t.Errorf("should use ON CONFLICT DO NOTHING, got %v", sql)
+	}
+
+	DB.Create(&Language{Code: "insert-ignore-1", Name: "original"})
+	langs := []Language{{Code: "insert-ignore-1", Name: "changed"}, {Code: "insert-ignore-2", Name: "new"}}
+	if result := DB.InsertIgnore().Create(&langs); result.Error != nil {
+		t.Fatalf("failed to insert ignore, got error %v", result.Error)
+	} else if result.RowsAffected != 1 {
+		t.Errorf("should insert 1 row, got %v", result.RowsAffected)
+	}
+
+	var lang Language
+	DB.First(&lang, "code = ?", "insert-ignore-1")
+	if lang.Name != "original" {
+		t.Errorf("should keep the existing row, got %v", lang.Name)
+	}
+
+	var newLang Language
+	if err := DB.First(&newLang, "code = ?", "insert-ignore-2").Error; err != nil || newLang.Name != "new" {
+		t.Errorf("should insert the new row, got %v, %v", newLang.Name, err)
+	}
+}
+
+func TestUpsertReturningOld(t *testing.T) {
+	postgresDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	var olds []Language
+	langs := []Language{{Code: "upsert_old_1", Name: "New 1"}, {Code: "upsert_old_2", Name: "New 2"}}
+	stmt := postgresDB.Clauses(clause.OnConflict{
+		Columns:      []clause.Column{{Name: "code"}},
+		DoUpdates:    clause.AssignmentColumns([]string{"name"}),
+		ReturningOld: &olds,
+	}).Create(&langs).Statement
+
+	if stmt.Error != nil {
+		t.Fatalf("no error should happen, got %v", stmt.Error)
+	}
+
+	expected := `WITH "gorm_old" AS (SELECT * FROM "languages" WHERE ("code") IN (($1),($2))),"gorm_new" AS (` +
+		`INSERT INTO "languages" ("code","name") VALUES ($3,$4),($5,$6) ON CONFLICT ("code") DO UPDATE SET "name"="excluded"."name" RETURNING *) ` +
+		`SELECT "gorm_new".*,"gorm_old"."code" AS "gorm_old__code","gorm_old"."name" AS "gorm_old__name" ` +
+		`FROM "gorm_new" LEFT JOIN "gorm_old" ON "gorm_new"."code" = "gorm_old"."code"`
+	if sql := stmt.SQL.String(); sql != expected {
+		t.Errorf("expected %v, got %v", expected, sql)
+	}
+	AssertEqual(t, stmt.Vars, []interface{}{"upsert_old_1", "upsert_old_2", "upsert_old_1", "New 1", "upsert_old_2", "New 2"})
+
+	var old Language
+	if err := DB.Session(&gorm.Session{DryRun: true}).Clauses(clause.OnConflict{UpdateAll: true, ReturningOld: &old}).Create(&Language{Code: "upsert_old_3"}).Error; DB.Dialector.Name() != "postgres" && !errors.Is(err, gorm.ErrUnsupportedDriver) {
+		t.Errorf("should return ErrUnsupportedDriver for %v, got %v", DB.Dialector.Name(), err)
+	}
+}
+
+func TestUpsertWithTargetExprs(t *testing.T) {
+	pgDB, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open postgres dialector, got error %v", err)
+	}
+
+	onConflict := clause.OnConflict{
+		TargetExprs: []clause.Expression{clause.Expr{SQL: "lower(?)", Vars: []interface{}{clause.Column{Name: "code"}}}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"name"}),
+		Where:       clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "excluded.name > target.name"}}},
+	}
+	stmt := pgDB.Clauses(onConflict).Create(&Language{Code: "target_exprs", Name: "target_exprs"}).Statement
+	if !strings.HasSuffix(strings.TrimSpace(stmt.SQL.String()), `ON CONFLICT (lower("code"))  WHERE deleted_at IS NULL DO UPDATE SET "name"="excluded"."name" WHERE excluded.name > "languages".name`) {
+		t.Errorf("should build the expression conflict target, got %v", stmt.SQL.String())
+	}
+
+	onConflict.Columns = []clause.Column{{Name: "name"}}
+	onConflict.UpdateAll, onConflict.DoUpdates = true, nil
+	stmt = pgDB.Clauses(onConflict).Create(&Language{Code: "target_exprs", Name: "target_exprs"}).Statement
+	if !strings.Contains(stmt.SQL.String(), `ON CONFLICT ("name",lower("code"))  WHERE deleted_at IS NULL DO UPDATE SET`) {
+		t.Errorf("should build the conflict target with columns and expressions, got %v", stmt.SQL.String())
+	}
+}
+
+type UpsertAccount struct {
+	ID        uint
+	Email     string
+	Version   int
+	DeletedAt gorm.DeletedAt
+}
+
+func TestUpsertWithTargetExprsRowsAffected(t *testing.T) {
+	if DB.Dialector.Name() == "mysql" || DB.Dialector.Name() == "sqlserver" {
+		t.Skip()
+	}
+
+	DB.Migrator().DropTable(&UpsertAccount{})
+	if err := DB.AutoMigrate(&UpsertAccount{}); err != nil {
+		t.Fatalf("failed to migrate, got error %v", err)
+	}
+	if err := DB.Exec("CREATE UNIQUE INDEX idx_upsert_accounts_lower_email ON upsert_accounts (lower(email)) WHERE deleted_at IS NULL").Error; err != nil {
+		t.Fatalf("failed to create expression index, got error %v", err)
+	}
+
+	upsert := func(email string, version int) int64 {
+		result := DB.Clauses(clause.OnConflict{
+			TargetExprs: []clause.Expression{clause.Expr{SQL: "lower(?)", Vars: []interface{}{clause.Column{Name: "email"}}}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates:   clause.AssignmentColumns([]string{"email", "version"}),
+			Where:       clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "excluded.version > target.version"}}},
+		}).Create(&UpsertAccount{Email: email, Version: version})
+		if result.Error != nil {
+			t.Fatalf("failed to upsert, got error %v", result.Error)
+		}
+		return result.RowsAffected
+	}
+
+	if affected := upsert("Upsert@Example.com", 1); affected != 1 {
+		t.Errorf("should insert the row, got rows affected %v", affected)
+	}
+	if affected := upsert("upsert@example.com", 2); affected != 1 {
+		t.Errorf("should update the row with a newer version, got rows affected %v", affected)
+	}
+	if affected := upsert("UPSERT@example.com", 1); affected != 0 {
+		t.Errorf("should not update the row with an older version, got rows affected %v", affected)
+	}
+
+	var accounts []UpsertAccount
+	DB.Find(&accounts)
+	if len(accounts) != 1 || accounts[0].Email != "upsert@example.com" || accounts[0].Version != 2 {
+		t.Errorf("should upsert into one row, got %+v", accounts)
+	}
+}